@@ -0,0 +1,139 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// longitudeFrame reports whether the main image is in a geographic CRS with
+// a north-up geotransform (ok), the longitude of its western edge, and
+// whether it spans the full 360°, so columns wrap around at the antimeridian
+func (c *COG) longitudeFrame() (west float64, global, ok bool) {
+	if len(c.metadata) == 0 {
+		return 0, false, false
+	}
+	projection, err := c.Projection()
+	if err != nil {
+		return 0, false, false
+	}
+	if _, geographic := projection.(proj.Geographic); !geographic {
+		return 0, false, false
+	}
+	gt, ok := c.GeoTransform(0)
+	if !ok || !gt.IsNorthUp() || gt[1] <= 0 {
+		return 0, false, false
+	}
+	span := float64(c.metadata[0].Width) * gt[1]
+	return gt[0], math.Abs(span-360) < gt[1]/2, true
+}
+
+// wrapLongitude returns lon shifted by a multiple of 360° into [west, west+360)
+func wrapLongitude(lon, west float64) float64 {
+	return west + math.Mod(math.Mod(lon-west, 360)+360, 360)
+}
+
+// readRegionWrapped handles ReadRegion requests on geographic images that
+// cross the antimeridian (Min[0] > Max[0]) or lie outside the image's
+// longitude range by a multiple of 360°, such as -180–180 requests on a
+// 0–360 image. handled is false if the request can be read as it is.
+func (c *COG) readRegionWrapped(bound orb.Bound, overview int) (data *RasterData, handled bool, err error) {
+	frameWest, global, ok := c.longitudeFrame()
+	if !ok || overview < 0 || overview >= len(c.geoTIFFs) {
+		return nil, false, nil
+	}
+	imageBounds := c.Bounds()
+	span := bound.Max[0] - bound.Min[0]
+	if span < 0 {
+		span += 360
+	}
+	span = math.Min(span, 360)
+	if bound.Max[0] >= bound.Min[0] && bound.Min[0] >= imageBounds.Min[0] && bound.Max[0] <= imageBounds.Max[0] {
+		return nil, false, nil
+	}
+	gtr := c.geoTIFFs[overview]
+	result := func(data *RasterData, window Rectangle, shift float64) *RasterData {
+		// Bounds are those of the pixels read, which may be clamped to the
+		// image, in the longitudes of the request
+		b := gtr.pixelBounds(float64(window.X), float64(window.Y), float64(window.X+window.Width), float64(window.Y+window.Height))
+		data.Bounds = orb.Bound{Min: orb.Point{b.Min[0] + shift, b.Min[1]}, Max: orb.Point{b.Max[0] + shift, b.Max[1]}}
+		return data
+	}
+
+	if !global {
+		// Shift the request by the multiple of 360° that overlaps the image most
+		best, bestOverlap := 0.0, -1.0
+		for _, shift := range []float64{0, -360, 360} {
+			west := bound.Min[0] + shift
+			overlap := math.Min(west+span, imageBounds.Max[0]) - math.Max(west, imageBounds.Min[0])
+			if overlap > bestOverlap {
+				best, bestOverlap = shift, overlap
+			}
+		}
+		if best == 0 && bound.Max[0] >= bound.Min[0] {
+			return nil, false, nil
+		}
+		shifted := orb.Bound{
+			Min: orb.Point{bound.Min[0] + best, bound.Min[1]},
+			Max: orb.Point{bound.Min[0] + best + span, bound.Max[1]},
+		}
+		window, err := c.regionWindow(shifted, overview)
+		if err != nil {
+			return nil, true, err
+		}
+		data, err := c.readRegion(shifted, overview)
+		if err != nil {
+			return nil, true, err
+		}
+		return result(data, window, -best), true, nil
+	}
+
+	// The image wraps around: read the columns modulo its width
+	meta := c.metadata[overview]
+	west := wrapLongitude(bound.Min[0], frameWest)
+	minX, minY := gtr.geoToPixel(west, bound.Max[1])
+	maxX, maxY := gtr.geoToPixel(west+span, bound.Min[1])
+	minY = math.Max(0, math.Min(float64(meta.Height-1), minY))
+	maxY = math.Max(0, math.Min(float64(meta.Height-1), maxY))
+
+	width := min(meta.Width, int(math.Ceil(maxX-minX)))
+	height := int(math.Ceil(maxY - minY))
+	if width <= 0 || height <= 0 {
+		return nil, true, fmt.Errorf("invalid region dimensions")
+	}
+	window := Rectangle{X: int(math.Floor(minX)), Y: int(minY), Width: width, Height: height}
+	data, err = c.readRasterWrapped(overview, window.X, window.Y, width, height)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read pixel region: %w", err)
+	}
+	return result(data, window, bound.Min[0]-west), true, nil
+}
+
+// readRasterWrapped reads a window of an IFD of an image spanning 360° of
+// longitude. Columns are taken modulo the image width, so the window may
+// start left of column 0 or extend beyond the last column.
+func (c *COG) readRasterWrapped(ifdIndex int, x, y, width, height int) (*RasterData, error) {
+	meta := c.metadata[ifdIndex]
+	if x >= 0 && x+width <= meta.Width {
+		return c.readRaster(ifdIndex, x, y, width, height)
+	}
+
+	bounds := c.geoTIFFs[ifdIndex].pixelBounds(float64(x), float64(y), float64(x+width), float64(y+height))
+	result := c.newRasterData(make([]uint64, width*height*meta.BandCount), width, height, meta.BandCount, bounds)
+	result.Overview = ifdIndex
+	for column := x; column < x+width; {
+		start := ((column % meta.Width) + meta.Width) % meta.Width
+		n := min(x+width-column, meta.Width-start)
+		piece, err := c.readRaster(ifdIndex, start, y, n, height)
+		if err != nil {
+			return nil, err
+		}
+		for row := 0; row < height; row++ {
+			copy(result.Data[result.Index(0, column-x, row):], piece.Data[piece.Index(0, 0, row):piece.Index(0, 0, row)+n*piece.Bands])
+		}
+		column += n
+	}
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+)
+
+// globalRaster covers the globe with 1° pixels from the given western edge;
+// values are the column
+func globalRaster(west float64) testRaster {
+	return testRaster{
+		Width: 360, Height: 180, DataType: DTSShort, TileSize: 32, EPSG: 4326,
+		Origin: [2]float64{west, 90}, PixelSize: [2]float64{1, 1},
+		Value: func(band, x, y int) float64 { return float64(x) },
+	}
+}
+
+func checkColumns(t *testing.T, data *RasterData, want []int) {
+	t.Helper()
+	if data.Width != len(want) {
+		t.Fatalf("expected %d columns, got %d", len(want), data.Width)
+	}
+	for x, w := range want {
+		if got := data.At(0, x, 0); got != uint64(w) {
+			t.Fatalf("column %d: expected %d, got %d", x, w, got)
+		}
+	}
+}
+
+func columnRange(from, to int) []int {
+	var columns []int
+	for c := from; c != to; c = (c + 1) % 360 {
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+func TestReadRegionAcrossAntimeridian(t *testing.T) {
+	c := globalRaster(-180).cog(t)
+
+	// 170°E to 170°W is columns 350-359 followed by 0-9
+	data, err := c.ReadRegion(orb.Bound{Min: orb.Point{170, 10}, Max: orb.Point{-170, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(350, 10))
+	if data.Height != 10 {
+		t.Errorf("expected 10 rows, got %d", data.Height)
+	}
+	want := orb.Bound{Min: orb.Point{170, 10}, Max: orb.Point{190, 20}}
+	if data.Bounds != want {
+		t.Errorf("expected bounds %v, got %v", want, data.Bounds)
+	}
+
+	// Longitudes beyond 180° are matched modulo 360°
+	data, err = c.ReadRegion(orb.Bound{Min: orb.Point{175, 10}, Max: orb.Point{185, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(355, 5))
+
+	// Regions inside the image are read as before
+	data, err = c.ReadRegion(orb.Bound{Min: orb.Point{10, 10}, Max: orb.Point{20, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(190, 200))
+}
+
+func TestReadRegionZeroTo360(t *testing.T) {
+	c := globalRaster(0).cog(t)
+
+	data, err := c.ReadRegion(orb.Bound{Min: orb.Point{-10, 10}, Max: orb.Point{10, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(350, 10))
+
+	data, err = c.ReadRegion(orb.Bound{Min: orb.Point{-100, 10}, Max: orb.Point{-90, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(260, 270))
+	if data.Bounds.Min[0] != -100 {
+		t.Errorf("expected bounds in the request's longitudes, got %v", data.Bounds)
+	}
+
+	// Latitudes beyond the poles are clamped
+	data, err = c.ReadRegion(orb.Bound{Min: orb.Point{-10, 80}, Max: orb.Point{10, 100}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	if want := (orb.Bound{Min: orb.Point{-10, 80}, Max: orb.Point{10, 90}}); data.Height != 10 || data.Bounds != want {
+		t.Errorf("expected 10 rows in %v, got %d in %v", want, data.Height, data.Bounds)
+	}
+
+	s, err := c.Sample(orb.Point{-89.5, 0})
+	if err != nil {
+		t.Fatalf("Sample failed: %v", err)
+	}
+	if s.Values[0] != 270 {
+		t.Errorf("expected 270 at 89.5°W, got %v", s.Values[0])
+	}
+}
+
+func TestReadRegionBoundsAgree(t *testing.T) {
+	// The same request reports the same bounds whatever the image's
+	// longitude range
+	for _, bound := range []orb.Bound{
+		{Min: orb.Point{-10.5, 10}, Max: orb.Point{10, 20}},
+		{Min: orb.Point{170, 10}, Max: orb.Point{-170, 20.5}},
+		{Min: orb.Point{-100, 80}, Max: orb.Point{-90, 100}},
+	} {
+		a, err := globalRaster(-180).cog(t).ReadRegion(bound, 0)
+		if err != nil {
+			t.Fatalf("ReadRegion failed: %v", err)
+		}
+		b, err := globalRaster(0).cog(t).ReadRegion(bound, 0)
+		if err != nil {
+			t.Fatalf("ReadRegion failed: %v", err)
+		}
+		if a.Bounds != b.Bounds || a.Width != b.Width || a.Height != b.Height {
+			t.Errorf("%v: -180–180 image read %dx%d in %v, 0–360 image %dx%d in %v",
+				bound, a.Width, a.Height, a.Bounds, b.Width, b.Height, b.Bounds)
+		}
+	}
+}
+
+func TestReadRegionRegionalAcrossAntimeridian(t *testing.T) {
+	// 20° wide image from 170°E to 190°E (170°W)
+	tr := globalRaster(170)
+	tr.Width = 20
+	c := tr.cog(t)
+
+	data, err := c.ReadRegion(orb.Bound{Min: orb.Point{-178, 10}, Max: orb.Point{-172, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(12, 18))
+
+	data, err = c.ReadRegion(orb.Bound{Min: orb.Point{175, 10}, Max: orb.Point{-175, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(5, 15))
+
+	// Requests beyond the image are clamped, and so are their bounds
+	data, err = c.ReadRegion(orb.Bound{Min: orb.Point{-178, 10}, Max: orb.Point{-165, 20}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	checkColumns(t, data, columnRange(12, 19))
+	if want := (orb.Bound{Min: orb.Point{-178, 10}, Max: orb.Point{-171, 20}}); data.Bounds != want {
+		t.Errorf("expected bounds %v, got %v", want, data.Bounds)
+	}
+}
+
+func TestReadTileZeroTo360(t *testing.T) {
+	c := globalRaster(0).cog(t)
+
+	// The western hemisphere comes from columns 180-359
+	tile := maptile.New(0, 1, 2)
+	data, err := c.ReadTile(tile)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if data.Mask != nil {
+		t.Error("expected every pixel of the tile to be inside the image")
+	}
+	lon := -180 + (128.5/256)*90
+	if got, want := data.At(0, 128, 128), uint64(math.Floor(lon+360)); got != want {
+		t.Errorf("expected column %d at %v°, got %d", want, lon, got)
+	}
+
+	// The whole world with an interpolating kernel wraps across the seam at 0°
+	c.SetResampling(ResampleBilinear)
+	data, err = c.ReadTile(maptile.New(0, 0, 0))
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if data.Mask != nil {
+		t.Error("expected every pixel of the world tile to be inside the image")
+	}
+	// 90°W is column 270 of the 0-360 image
+	if got := data.At(0, 64, 128); got < 269 || got > 271 {
+		t.Errorf("expected about 270 at 90°W, got %d", got)
+	}
+}
@@ -433,55 +433,6 @@ func BenchmarkBytesBufferPooled(b *testing.B) {
 	}
 }
 
-// =============================================================================
-// Benchmarks for resampleImage
-// =============================================================================
-
-func BenchmarkResampleImage_256to128(b *testing.B) {
-	srcWidth, srcHeight := 256, 256
-	dstWidth, dstHeight := 128, 128
-	bands := 3
-	data := generateTestTileData(srcWidth, srcHeight, bands, DTByte)
-	cog := &COG{}
-	
-	b.ResetTimer()
-	b.ReportAllocs()
-	
-	for i := 0; i < b.N; i++ {
-		_, _ = cog.resampleImage(data, srcWidth, srcHeight, dstWidth, dstHeight, bands, DTByte)
-	}
-}
-
-func BenchmarkResampleImage_512to256(b *testing.B) {
-	srcWidth, srcHeight := 512, 512
-	dstWidth, dstHeight := 256, 256
-	bands := 3
-	data := generateTestTileData(srcWidth, srcHeight, bands, DTByte)
-	cog := &COG{}
-	
-	b.ResetTimer()
-	b.ReportAllocs()
-	
-	for i := 0; i < b.N; i++ {
-		_, _ = cog.resampleImage(data, srcWidth, srcHeight, dstWidth, dstHeight, bands, DTByte)
-	}
-}
-
-func BenchmarkResampleImage_1024to256(b *testing.B) {
-	srcWidth, srcHeight := 1024, 1024
-	dstWidth, dstHeight := 256, 256
-	bands := 3
-	data := generateTestTileData(srcWidth, srcHeight, bands, DTByte)
-	cog := &COG{}
-	
-	b.ResetTimer()
-	b.ReportAllocs()
-	
-	for i := 0; i < b.N; i++ {
-		_, _ = cog.resampleImage(data, srcWidth, srcHeight, dstWidth, dstHeight, bands, DTByte)
-	}
-}
-
 // =============================================================================
 // Benchmark for TIFF parsing
 // =============================================================================
@@ -17,6 +17,7 @@ import (
 
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog/proj"
 	"github.com/valyala/fasthttp"
 	"golang.org/x/image/tiff/lzw"
 )
@@ -27,18 +28,31 @@ type COG struct {
 	tiffReader *TIFFReader
 	geoTIFFs   []*GeoTIFFReader
 	metadata   []*GeoTIFFMetadata
+
+	oversamplingThreshold float64
+	resampling            Resampling
+	elevationInMetres     bool
 }
 
 // RasterData represents raster data read from a COG.
 // Data is stored as a flat array in band-interleaved-by-pixel (BIP) format:
 // index = y * Width * Bands + x * Bands + band
 // This provides better cache locality and fewer allocations than nested slices.
+//
+// Samples keep the raw bit pattern produced by decoding: signed integers are
+// sign-extended and floating point values hold their IEEE-754 bits. Use
+// Float to obtain the numeric value of a sample.
 type RasterData struct {
-	Data   []uint64 // Flat array: [y * Width * Bands + x * Bands + band]
-	Width  int
-	Height int
-	Bands  int
-	Bounds orb.Bound
+	Data      []uint64 // Flat array: [y * Width * Bands + x * Bands + band]
+	Width     int
+	Height    int
+	Bands     int
+	Bounds    orb.Bound
+	DataType  DataType // Sample type the values in Data are encoded as
+	NoData    float64  // NoData value, only meaningful if HasNoData is set
+	HasNoData bool
+	Mask      []uint8 // Per-pixel validity, 0 marks pixels outside the source data; nil if all pixels are valid
+	Overview  int     // IFD the data was read from (0 = main image, 1+ = overviews)
 }
 
 // At returns the value at the specified band, x, y coordinates.
@@ -100,6 +114,98 @@ func (r *RasterData) GetPixel(x, y int) []uint64 {
 	return result
 }
 
+// Float returns the numeric value at the specified band, x, y coordinates,
+// interpreting the raw sample according to the raster's DataType.
+func (r *RasterData) Float(band, x, y int) float64 {
+	return SampleToFloat64(r.At(band, x, y), r.DataType)
+}
+
+// IsNoData reports whether the sample at the specified band, x, y coordinates
+// is missing: masked, equal to the NoData value or NaN.
+func (r *RasterData) IsNoData(band, x, y int) bool {
+	if r.Mask != nil && x >= 0 && x < r.Width && y >= 0 && y < r.Height && r.Mask[y*r.Width+x] == 0 {
+		return true
+	}
+	return r.isNoDataValue(r.Float(band, x, y))
+}
+
+// masked reports whether the pixel at the flat pixel index (y * Width + x)
+// is excluded by the mask
+func (r *RasterData) masked(pixel int) bool {
+	return r.Mask != nil && r.Mask[pixel] == 0
+}
+
+// isNoDataValue reports whether a numeric sample value should be treated as missing
+func (r *RasterData) isNoDataValue(v float64) bool {
+	if math.IsNaN(v) {
+		return true
+	}
+	return r.HasNoData && v == r.NoData
+}
+
+// SampleToFloat64 converts a raw sample, as stored in RasterData.Data,
+// to its numeric value for the given data type.
+func SampleToFloat64(value uint64, dataType DataType) float64 {
+	switch dataType {
+	case DTSByte, DTSShortS, DTSLongS, DTSRational:
+		return float64(int64(value))
+	case DTFloat:
+		return float64(math.Float32frombits(uint32(value)))
+	case DTDouble:
+		return math.Float64frombits(value)
+	default:
+		return float64(value)
+	}
+}
+
+// Float64ToSample converts a numeric value to the raw sample representation
+// used by RasterData.Data for the given data type. Integer types are rounded
+// and clamped to their valid range.
+func Float64ToSample(value float64, dataType DataType) uint64 {
+	switch dataType {
+	case DTFloat:
+		return uint64(math.Float32bits(float32(value)))
+	case DTDouble:
+		return math.Float64bits(value)
+	}
+
+	if math.IsNaN(value) {
+		return 0
+	}
+
+	minValue, maxValue := dataTypeRange(dataType)
+	value = math.Max(minValue, math.Min(maxValue, math.Round(value)))
+
+	switch dataType {
+	case DTSByte, DTSShortS, DTSLongS, DTSRational:
+		return uint64(int64(value))
+	default:
+		return uint64(value)
+	}
+}
+
+// dataTypeRange returns the smallest and largest value representable by an integer data type
+func dataTypeRange(dataType DataType) (float64, float64) {
+	switch dataType {
+	case DTSByte:
+		return math.MinInt8, math.MaxInt8
+	case DTSShort:
+		return 0, math.MaxUint16
+	case DTSShortS:
+		return math.MinInt16, math.MaxInt16
+	case DTSLong, DTRational:
+		return 0, math.MaxUint32
+	case DTSLongS, DTSRational:
+		return math.MinInt32, math.MaxInt32
+	case DTFloat:
+		return -math.MaxFloat32, math.MaxFloat32
+	case DTDouble:
+		return -math.MaxFloat64, math.MaxFloat64
+	default:
+		return 0, math.MaxUint8
+	}
+}
+
 // TileInfo represents information about a tile
 type TileInfo struct {
 	X      int
@@ -122,6 +228,8 @@ func Read(r io.ReadSeeker) (*COG, error) {
 		tiffReader: tr,
 		geoTIFFs:   make([]*GeoTIFFReader, 0),
 		metadata:   make([]*GeoTIFFMetadata, 0),
+
+		oversamplingThreshold: DefaultOversamplingThreshold,
 	}
 
 	// Read metadata for all IFDs (main image + overviews)
@@ -141,6 +249,7 @@ func Read(r io.ReadSeeker) (*COG, error) {
 		cog.geoTIFFs = append(cog.geoTIFFs, gtr)
 		cog.metadata = append(cog.metadata, gtr.GetMetadata())
 	}
+	cog.deriveOverviewGeoTransforms()
 
 	return cog, nil
 }
@@ -201,6 +310,8 @@ func Open(pathOrURL string, client *fasthttp.Client) (*COG, error) {
 		tiffReader: tr,
 		geoTIFFs:   make([]*GeoTIFFReader, 0),
 		metadata:   make([]*GeoTIFFMetadata, 0),
+
+		oversamplingThreshold: DefaultOversamplingThreshold,
 	}
 
 	// Read metadata for all IFDs (main image + overviews)
@@ -223,6 +334,7 @@ func Open(pathOrURL string, client *fasthttp.Client) (*COG, error) {
 		cog.geoTIFFs = append(cog.geoTIFFs, gtr)
 		cog.metadata = append(cog.metadata, gtr.GetMetadata())
 	}
+	cog.deriveOverviewGeoTransforms()
 
 	return cog, nil
 }
@@ -275,6 +387,31 @@ func (c *COG) DataType() DataType {
 	return c.metadata[0].DataType
 }
 
+// NoData returns the NoData value of the image and whether one is defined
+func (c *COG) NoData() (float64, bool) {
+	if len(c.metadata) == 0 {
+		return 0, false
+	}
+	return c.metadata[0].NoData, c.metadata[0].HasNoData
+}
+
+// ScaleOffset returns the scale and offset that convert raw values of a band
+// (0-based) to physical units (physical = raw*scale + offset), as stored in
+// the GDAL_METADATA tag. It returns 1 and 0 if none are defined. With
+// SetElevationInMetres, both include the conversion of heights to metres.
+func (c *COG) ScaleOffset(band int) (float64, float64) {
+	if len(c.metadata) == 0 {
+		return 1, 0
+	}
+	scale, offset := 1.0, 0.0
+	meta := c.metadata[0]
+	if band >= 0 && band < len(meta.Scales) {
+		scale, offset = meta.Scales[band], meta.Offsets[band]
+	}
+	factor := c.elevationFactor()
+	return scale * factor, offset * factor
+}
+
 // OverviewCount returns the number of overview levels
 func (c *COG) OverviewCount() int {
 	if len(c.metadata) <= 1 {
@@ -292,8 +429,28 @@ func (c *COG) GetOverview(level int) *GeoTIFFMetadata {
 	return c.metadata[overviewIndex]
 }
 
-// ReadRegion reads a geographic region from the COG
+// ReadRegion reads a geographic region from the COG at the given overview
+// level (0 = main image). ReadRegionAtResolution selects the overview from a
+// target resolution instead.
+//
+// For images in a geographic CRS, a region crossing the antimeridian is
+// given with Min[0] > Max[0] (e.g. 170 to -170) and is read in two parts
+// stitched into one raster, and longitudes are matched modulo 360°, so
+// -180–180 regions can be read from 0–360 images and vice versa.
+//
+// The returned bounds are those of the pixels read: the region snapped to
+// whole pixels and clamped to the image. For geographic images they are in
+// the longitudes of the request, running from Min[0] eastwards (e.g. 170 to
+// 190), whatever the image's longitude range.
 func (c *COG) ReadRegion(bound orb.Bound, overview int) (*RasterData, error) {
+	if data, handled, err := c.readRegionWrapped(bound, overview); handled {
+		return data, err
+	}
+	return c.readRegion(bound, overview)
+}
+
+// readRegion reads a geographic region without longitude wrapping
+func (c *COG) readRegion(bound orb.Bound, overview int) (*RasterData, error) {
 	if len(c.geoTIFFs) == 0 {
 		return nil, fmt.Errorf("no image data available")
 	}
@@ -303,27 +460,15 @@ func (c *COG) ReadRegion(bound orb.Bound, overview int) (*RasterData, error) {
 		return nil, fmt.Errorf("invalid overview level: %d", overview)
 	}
 
-	gtr := c.geoTIFFs[overviewIndex]
 	meta := c.metadata[overviewIndex]
-
-	// Convert geographic bounds to pixel coordinates
-	pixelBounds := c.geoToPixelBounds(bound, meta, gtr)
-
-	// Clamp to image bounds
-	pixelBounds.MinX = math.Max(0, math.Min(float64(meta.Width-1), pixelBounds.MinX))
-	pixelBounds.MaxX = math.Max(0, math.Min(float64(meta.Width-1), pixelBounds.MaxX))
-	pixelBounds.MinY = math.Max(0, math.Min(float64(meta.Height-1), pixelBounds.MinY))
-	pixelBounds.MaxY = math.Max(0, math.Min(float64(meta.Height-1), pixelBounds.MaxY))
-
-	width := int(math.Ceil(pixelBounds.MaxX - pixelBounds.MinX))
-	height := int(math.Ceil(pixelBounds.MaxY - pixelBounds.MinY))
-
-	if width <= 0 || height <= 0 {
-		return nil, fmt.Errorf("invalid region dimensions")
+	window, err := c.regionWindow(bound, overviewIndex)
+	if err != nil {
+		return nil, err
 	}
+	width, height := window.Width, window.Height
 
 	// Read the data
-	data, err := c.readPixelRegion(overviewIndex, int(pixelBounds.MinX), int(pixelBounds.MinY), width, height)
+	data, err := c.readPixelRegion(overviewIndex, window.X, window.Y, width, height)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read pixel region: %w", err)
 	}
@@ -337,13 +482,50 @@ func (c *COG) ReadRegion(bound orb.Bound, overview int) (*RasterData, error) {
 	// Decode bytes to flat uint64 slice
 	decodedData := c.decodeBytesToFlat(data, width, height, meta.BandCount, meta.DataType, ifd.ByteOrder, meta.PhotometricInterpretation)
 
+	bounds := c.geoTIFFs[overviewIndex].pixelBounds(float64(window.X), float64(window.Y), float64(window.X+width), float64(window.Y+height))
+	result := c.newRasterData(decodedData, width, height, meta.BandCount, bounds)
+	result.Overview = overviewIndex
+	return result, nil
+}
+
+// regionWindow returns the pixel window readRegion reads for a geographic
+// region: the pixels it covers, clamped to the image
+func (c *COG) regionWindow(bound orb.Bound, overview int) (Rectangle, error) {
+	gtr := c.geoTIFFs[overview]
+	meta := c.metadata[overview]
+
+	// Convert geographic bounds to pixel coordinates
+	pixelBounds := c.geoToPixelBounds(bound, meta, gtr)
+
+	// Clamp to image bounds
+	pixelBounds.MinX = math.Max(0, math.Min(float64(meta.Width-1), pixelBounds.MinX))
+	pixelBounds.MaxX = math.Max(0, math.Min(float64(meta.Width-1), pixelBounds.MaxX))
+	pixelBounds.MinY = math.Max(0, math.Min(float64(meta.Height-1), pixelBounds.MinY))
+	pixelBounds.MaxY = math.Max(0, math.Min(float64(meta.Height-1), pixelBounds.MaxY))
+
+	width := int(math.Ceil(pixelBounds.MaxX - pixelBounds.MinX))
+	height := int(math.Ceil(pixelBounds.MaxY - pixelBounds.MinY))
+
+	if width <= 0 || height <= 0 {
+		return Rectangle{}, fmt.Errorf("invalid region dimensions")
+	}
+	return Rectangle{X: int(pixelBounds.MinX), Y: int(pixelBounds.MinY), Width: width, Height: height}, nil
+}
+
+// newRasterData wraps decoded samples in a RasterData carrying the image's
+// data type and NoData value
+func (c *COG) newRasterData(data []uint64, width, height, bands int, bounds orb.Bound) *RasterData {
+	noData, hasNoData := c.NoData()
 	return &RasterData{
-		Data:   decodedData,
-		Width:  width,
-		Height: height,
-		Bands:  meta.BandCount,
-		Bounds: bound,
-	}, nil
+		Data:      data,
+		Width:     width,
+		Height:    height,
+		Bands:     bands,
+		Bounds:    bounds,
+		DataType:  c.DataType(),
+		NoData:    noData,
+		HasNoData: hasNoData,
+	}
 }
 
 // pixelBounds represents pixel coordinate bounds
@@ -359,31 +541,24 @@ type Rectangle struct {
 	Height int // Height in pixels
 }
 
-// geoToPixelBounds converts geographic bounds to pixel bounds
+// geoToPixelBounds converts geographic bounds to pixel bounds. All four
+// corners are transformed, so the result encloses the bounds even for
+// rotated images.
 func (c *COG) geoToPixelBounds(bound orb.Bound, meta *GeoTIFFMetadata, gtr *GeoTIFFReader) pixelBounds {
-	// Get image bounds
-	imgBounds := gtr.Bounds()
-
-	// Calculate pixel coordinates
-	geoWidth := imgBounds.Max[0] - imgBounds.Min[0]
-	geoHeight := imgBounds.Max[1] - imgBounds.Min[1]
-
-	if geoWidth == 0 || geoHeight == 0 {
+	if !gtr.georeferenced() {
 		return pixelBounds{}
 	}
 
-	// Normalize coordinates
-	minX := (bound.Min[0] - imgBounds.Min[0]) / geoWidth * float64(meta.Width)
-	maxX := (bound.Max[0] - imgBounds.Min[0]) / geoWidth * float64(meta.Width)
-	minY := (imgBounds.Max[1] - bound.Max[1]) / geoHeight * float64(meta.Height) // Y is inverted
-	maxY := (imgBounds.Max[1] - bound.Min[1]) / geoHeight * float64(meta.Height)
-
-	return pixelBounds{
-		MinX: minX,
-		MinY: minY,
-		MaxX: maxX,
-		MaxY: maxY,
+	result := pixelBounds{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, corner := range []orb.Point{bound.Min, {bound.Max[0], bound.Min[1]}, bound.Max, {bound.Min[0], bound.Max[1]}} {
+		x, y := gtr.geoToPixel(corner[0], corner[1])
+		result.MinX = math.Min(result.MinX, x)
+		result.MinY = math.Min(result.MinY, y)
+		result.MaxX = math.Max(result.MaxX, x)
+		result.MaxY = math.Max(result.MaxY, y)
 	}
+
+	return result
 }
 
 // readPixelRegion reads a region of pixels from the specified IFD
@@ -414,6 +589,102 @@ func (c *COG) readPixelRegion(ifdIndex int, x, y, width, height int) ([]byte, er
 	return nil, fmt.Errorf("image is neither tiled nor stripped")
 }
 
+// readRaster reads and decodes a window of pixels from the specified IFD.
+// The bounds of the result are derived from the main image georeferencing.
+func (c *COG) readRaster(ifdIndex int, x, y, width, height int) (*RasterData, error) {
+	ifd := c.tiffReader.GetIFD(ifdIndex)
+	if ifd == nil {
+		return nil, fmt.Errorf("IFD %d not found", ifdIndex)
+	}
+	meta := c.metadata[ifdIndex]
+
+	data, err := c.readPixelRegion(ifdIndex, x, y, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pixel region: %w", err)
+	}
+
+	decodedData := c.decodeBytesToFlat(data, width, height, meta.BandCount, meta.DataType, ifd.ByteOrder, meta.PhotometricInterpretation)
+
+	// Compute geographic bounds from the IFD's georeferencing
+	bounds := c.geoTIFFs[ifdIndex].pixelBounds(float64(x), float64(y), float64(x+width), float64(y+height))
+
+	result := c.newRasterData(decodedData, width, height, meta.BandCount, bounds)
+	result.Overview = ifdIndex
+	return result, nil
+}
+
+// blockSize returns the dimensions of the tiles or strips of the specified IFD
+func (c *COG) blockSize(ifdIndex int) (int, int) {
+	ifd := c.tiffReader.GetIFD(ifdIndex)
+	if ifd == nil {
+		return 0, 0
+	}
+	meta := c.metadata[ifdIndex]
+
+	if ifd.Tags[324] != nil { // TileOffsets
+		tileWidth := 256  // Default
+		tileHeight := 256 // Default
+
+		if tag := ifd.Tags[322]; tag != nil { // TileWidth
+			if val, ok := tag.Value.(uint16); ok {
+				tileWidth = int(val)
+			} else if val, ok := tag.Value.(uint32); ok {
+				tileWidth = int(val)
+			}
+		}
+		if tag := ifd.Tags[323]; tag != nil { // TileLength
+			if val, ok := tag.Value.(uint16); ok {
+				tileHeight = int(val)
+			} else if val, ok := tag.Value.(uint32); ok {
+				tileHeight = int(val)
+			}
+		}
+		return tileWidth, tileHeight
+	}
+
+	rowsPerStrip := meta.Height // Default
+
+	if tag := ifd.Tags[278]; tag != nil { // RowsPerStrip
+		if val, ok := tag.Value.(uint16); ok {
+			rowsPerStrip = int(val)
+		} else if val, ok := tag.Value.(uint32); ok {
+			rowsPerStrip = int(val)
+		}
+	}
+	if rowsPerStrip <= 0 || rowsPerStrip > meta.Height {
+		rowsPerStrip = meta.Height
+	}
+	return meta.Width, rowsPerStrip
+}
+
+// forEachBlockRow reads the specified IFD one row of tiles (or one strip) at
+// a time, so that memory use is bounded by the block height.
+func (c *COG) forEachBlockRow(ifdIndex int, fn func(data *RasterData, y int) error) error {
+	if ifdIndex < 0 || ifdIndex >= len(c.metadata) {
+		return fmt.Errorf("invalid overview level: %d", ifdIndex)
+	}
+	meta := c.metadata[ifdIndex]
+	_, blockHeight := c.blockSize(ifdIndex)
+	if blockHeight <= 0 {
+		blockHeight = meta.Height
+	}
+
+	for y := 0; y < meta.Height; y += blockHeight {
+		height := blockHeight
+		if y+height > meta.Height {
+			height = meta.Height - y
+		}
+		data, err := c.readRaster(ifdIndex, 0, y, meta.Width, height)
+		if err != nil {
+			return err
+		}
+		if err := fn(data, y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // decompressTile decompresses tile data based on compression type
 func (c *COG) decompressTile(data []byte, compression uint16, ifd *IFD, tileWidth, tileHeight, bands int, dataType DataType) ([]byte, error) {
 	switch compression {
@@ -1146,6 +1417,7 @@ func (c *COG) decodeBytesToFlat(data []byte, width, height, bands int, dataType
 // The rectangle is specified in the main image's pixel coordinates.
 // The function automatically selects the appropriate overview level to minimize data transfer
 // while maintaining sufficient resolution.
+// The output size depends on the overview chosen; use ReadWindowSized for an exact size.
 func (c *COG) ReadWindow(rect Rectangle) (*RasterData, error) {
 	if len(c.metadata) == 0 {
 		return nil, fmt.Errorf("no image data available")
@@ -1192,37 +1464,55 @@ func (c *COG) ReadWindow(rect Rectangle) (*RasterData, error) {
 	}
 
 	// Read pixel data from the selected overview
-	data, err := c.readPixelRegion(overviewIndex, overviewX, overviewY, overviewWidth, overviewHeight)
+	data, err := c.readRaster(overviewIndex, overviewX, overviewY, overviewWidth, overviewHeight)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read pixel region: %w", err)
+		return nil, err
 	}
 
-	// Get byte order from IFD
-	ifd := c.tiffReader.GetIFD(overviewIndex)
-	if ifd == nil {
-		return nil, fmt.Errorf("IFD %d not found", overviewIndex)
-	}
+	// Calculate geographic bounds using main image georeferencing
+	data.Bounds = c.geoTIFFs[0].pixelBounds(float64(rect.X), float64(rect.Y), float64(rect.X+rect.Width), float64(rect.Y+rect.Height))
 
-	// Decode bytes to flat uint64 slice
-	decodedData := c.decodeBytesToFlat(data, overviewWidth, overviewHeight, meta.BandCount, meta.DataType, ifd.ByteOrder, meta.PhotometricInterpretation)
+	return data, nil
+}
 
-	// Calculate geographic bounds using main image georeferencing
-	mainGTR := c.geoTIFFs[0]
-	topLeftX, topLeftY := mainGTR.pixelToGeo(float64(rect.X), float64(rect.Y))
-	bottomRightX, bottomRightY := mainGTR.pixelToGeo(float64(rect.X+rect.Width), float64(rect.Y+rect.Height))
+// ReadWindowSized reads a window given in the main image's pixel coordinates
+// and resamples it to exactly width x height pixels, like GDAL's RasterIO
+// with a buffer size. The coarsest overview meeting the output resolution is
+// read (see SetOversamplingThreshold) and resampled with the method set by
+// SetResampling. The returned bounds are the window's geographic bounds.
+func (c *COG) ReadWindowSized(rect Rectangle, width, height int) (*RasterData, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image data available")
+	}
 
-	bounds := orb.Bound{
-		Min: orb.Point{topLeftX, bottomRightY},
-		Max: orb.Point{bottomRightX, topLeftY},
+	mainMeta := c.metadata[0]
+	if rect.X < 0 || rect.Y < 0 {
+		return nil, fmt.Errorf("rectangle coordinates must be non-negative")
+	}
+	if rect.Width <= 0 || rect.Height <= 0 {
+		return nil, fmt.Errorf("rectangle dimensions must be positive")
+	}
+	if rect.X+rect.Width > mainMeta.Width || rect.Y+rect.Height > mainMeta.Height {
+		return nil, fmt.Errorf("rectangle extends beyond image (%dx%d)", mainMeta.Width, mainMeta.Height)
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("output dimensions must be positive")
 	}
 
-	return &RasterData{
-		Data:   decodedData,
-		Width:  overviewWidth,
-		Height: overviewHeight,
-		Bands:  meta.BandCount,
-		Bounds: bounds,
-	}, nil
+	scaleX := float64(rect.Width) / float64(width)
+	scaleY := float64(rect.Height) / float64(height)
+	mapping := func(x, y float64) (float64, float64, bool) {
+		return float64(rect.X) + x*scaleX, float64(rect.Y) + y*scaleY, true
+	}
+
+	bounds := c.geoTIFFs[0].pixelBounds(float64(rect.X), float64(rect.Y), float64(rect.X+rect.Width), float64(rect.Y+rect.Height))
+	overview := c.bestOverview(math.Min(scaleX, scaleY))
+
+	data, err := c.warp(overview, width, height, mapping, bounds, c.resampling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resample window: %w", err)
+	}
+	return data, nil
 }
 
 // selectOverview determines which overview level to use for reading a window.
@@ -1279,7 +1569,18 @@ func (c *COG) selectOverview(rect Rectangle) int {
 
 // ReadTile reads a map tile from the COG and returns it as a RasterData image.
 // The tile is specified using a maptile.Tile object and will be resampled to the specified tile size.
-// The GeoTiff must be in CRS EPSG:4326 or EPSG:3857, otherwise an error is returned.
+// The GeoTiff CRS must be supported by Projection (e.g. EPSG:4326, EPSG:3857 or UTM),
+// otherwise an error is returned.
+//
+// The image is warped: the centre of every output pixel is transformed to the
+// GeoTiff CRS and resampled from the source pixels around it (nearest
+// neighbour unless changed with SetResampling), so tiles are correct in any
+// supported projection and at image edges. Pixels outside the
+// image are set to the NoData value (or zero) and masked (see RasterData.Mask).
+// The coarsest overview meeting the tile's resolution is read, subject to
+// SetOversamplingThreshold; RasterData.Overview reports which.
+// The returned bounds are the tile's bounds in the GeoTiff CRS: tile.Bound()
+// for geographic images and the envelope of the projected tile otherwise.
 // If tileSize is not provided or is <= 0, it defaults to 256.
 func (c *COG) ReadTile(tile maptile.Tile, tileSize ...int) (*RasterData, error) {
 	if len(c.geoTIFFs) == 0 {
@@ -1292,143 +1593,33 @@ func (c *COG) ReadTile(tile maptile.Tile, tileSize ...int) (*RasterData, error)
 		size = tileSize[0]
 	}
 
-	// Validate CRS
-	crs := c.CRS()
-	if crs != "EPSG:4326" && crs != "EPSG:3857" {
-		return nil, fmt.Errorf("unsupported CRS: %s (only EPSG:4326 and EPSG:3857 are supported)", crs)
-	}
+	// Tile bounds in Web Mercator (tile.Bound() returns WGS84/EPSG:4326 bounds)
+	bounds := projectBound(tile.Bound(), proj.WebMercator)
 
-	// Get tile bounds (tile.Bound() returns WGS84/EPSG:4326 bounds)
-	tileBounds := tile.Bound()
-
-	// Convert tile bounds to match GeoTiff CRS if needed
-	var geoBounds orb.Bound
-	if crs == "EPSG:4326" {
-		// Already in WGS84
-		geoBounds = tileBounds
-	} else {
-		// Convert WGS84 bounds to Web Mercator
-		geoBounds = wgs84ToMercator(tileBounds)
-	}
-
-	// Convert geographic bounds to pixel coordinates
-	meta := c.metadata[0]
-	gtr := c.geoTIFFs[0]
-	pixelBounds := c.geoToPixelBounds(geoBounds, meta, gtr)
-
-	// Clamp to image bounds
-	pixelBounds.MinX = math.Max(0, math.Min(float64(meta.Width-1), pixelBounds.MinX))
-	pixelBounds.MaxX = math.Max(0, math.Min(float64(meta.Width-1), pixelBounds.MaxX))
-	pixelBounds.MinY = math.Max(0, math.Min(float64(meta.Height-1), pixelBounds.MinY))
-	pixelBounds.MaxY = math.Max(0, math.Min(float64(meta.Height-1), pixelBounds.MaxY))
-
-	width := int(math.Ceil(pixelBounds.MaxX - pixelBounds.MinX))
-	height := int(math.Ceil(pixelBounds.MaxY - pixelBounds.MinY))
-
-	if width <= 0 || height <= 0 {
-		return nil, fmt.Errorf("invalid tile dimensions after projection")
-	}
-
-	// Read the pixel data
-	data, err := c.readPixelRegion(0, int(pixelBounds.MinX), int(pixelBounds.MinY), width, height)
+	mapping, err := c.gridMapping(proj.WebMercator, bounds, size, size)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read pixel region: %w", err)
-	}
-
-	// Resample to tile size if needed
-	if width != size || height != size {
-		data, err = c.resampleImage(data, width, height, size, size, meta.BandCount, meta.DataType)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resample image: %w", err)
-		}
-		width = size
-		height = size
-	}
-
-	// Get byte order from IFD
-	ifd := c.tiffReader.GetIFD(0)
-	if ifd == nil {
-		return nil, fmt.Errorf("IFD 0 not found")
-	}
-
-	// Decode bytes to flat uint64 slice
-	decodedData := c.decodeBytesToFlat(data, width, height, meta.BandCount, meta.DataType, ifd.ByteOrder, meta.PhotometricInterpretation)
-
-	return &RasterData{
-		Data:   decodedData,
-		Width:  width,
-		Height: height,
-		Bands:  meta.BandCount,
-		Bounds: geoBounds,
-	}, nil
-}
-
-// mercatorToWGS84 converts Web Mercator (EPSG:3857) bounds to WGS84 (EPSG:4326) bounds
-func mercatorToWGS84(bound orb.Bound) orb.Bound {
-	const maxMercator = 20037508.342789244
-
-	minLon := bound.Min[0] / maxMercator * 180.0
-	maxLon := bound.Max[0] / maxMercator * 180.0
-
-	minLat := math.Atan(math.Exp(bound.Min[1]*math.Pi/maxMercator))*360.0/math.Pi - 90.0
-	maxLat := math.Atan(math.Exp(bound.Max[1]*math.Pi/maxMercator))*360.0/math.Pi - 90.0
-
-	return orb.Bound{
-		Min: orb.Point{minLon, minLat},
-		Max: orb.Point{maxLon, maxLat},
+		return nil, err
 	}
-}
-
-// wgs84ToMercator converts WGS84 (EPSG:4326) bounds to Web Mercator (EPSG:3857) bounds
-func wgs84ToMercator(bound orb.Bound) orb.Bound {
-	const maxMercator = 20037508.342789244
 
-	minX := bound.Min[0] / 180.0 * maxMercator
-	maxX := bound.Max[0] / 180.0 * maxMercator
+	// Read from the coarsest overview that still meets the tile's resolution
+	overview := c.bestOverview(mappingFactor(mapping, size, size))
 
-	minY := math.Log(math.Tan((90.0+bound.Min[1])*math.Pi/360.0)) / math.Pi * maxMercator
-	maxY := math.Log(math.Tan((90.0+bound.Max[1])*math.Pi/360.0)) / math.Pi * maxMercator
-
-	return orb.Bound{
-		Min: orb.Point{minX, minY},
-		Max: orb.Point{maxX, maxY},
+	data, err := c.warp(overview, size, size, mapping, bounds, c.resampling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to warp tile: %w", err)
 	}
+	data.Bounds = c.tileBounds(tile)
+	return data, nil
 }
 
-// resampleImage resamples image data from source dimensions to target dimensions
-func (c *COG) resampleImage(data []byte, srcWidth, srcHeight, dstWidth, dstHeight, bands int, dataType DataType) ([]byte, error) {
-	bytesPerPixel := bands * c.getBytesPerSample(dataType)
-	srcSize := srcWidth * srcHeight * bytesPerPixel
-	dstSize := dstWidth * dstHeight * bytesPerPixel
-
-	if len(data) < srcSize {
-		return nil, fmt.Errorf("insufficient data: expected %d bytes, got %d", srcSize, len(data))
+// tileBounds returns the bounds of a map tile in the image's CRS
+func (c *COG) tileBounds(tile maptile.Tile) orb.Bound {
+	projection, err := c.Projection()
+	if err != nil {
+		return tile.Bound()
 	}
-
-	dstData := make([]byte, dstSize)
-
-	// Simple nearest-neighbor resampling
-	for y := 0; y < dstHeight; y++ {
-		for x := 0; x < dstWidth; x++ {
-			// Map destination coordinates to source coordinates
-			srcX := int(float64(x) * float64(srcWidth) / float64(dstWidth))
-			srcY := int(float64(y) * float64(srcHeight) / float64(dstHeight))
-
-			// Clamp to source bounds
-			if srcX >= srcWidth {
-				srcX = srcWidth - 1
-			}
-			if srcY >= srcHeight {
-				srcY = srcHeight - 1
-			}
-
-			// Copy pixel data
-			srcOffset := (srcY*srcWidth + srcX) * bytesPerPixel
-			dstOffset := (y*dstWidth + x) * bytesPerPixel
-
-			copy(dstData[dstOffset:dstOffset+bytesPerPixel], data[srcOffset:srcOffset+bytesPerPixel])
-		}
+	if _, geographic := projection.(proj.Geographic); geographic {
+		return tile.Bound()
 	}
-
-	return dstData, nil
+	return projectBound(tile.Bound(), projection)
 }
@@ -0,0 +1,301 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// ContourOptions configures Contours. Either Interval or Levels must be set.
+type ContourOptions struct {
+	// Interval is the spacing of the contour levels, e.g. 10 for a line
+	// every 10 m; levels are Base + k*Interval
+	Interval float64
+	Base     float64
+
+	// Levels are explicit contour levels, used instead of Interval
+	Levels []float64
+
+	Band     int // 0-based band to contour
+	Overview int // Level to contour (0 = main image)
+
+	// Window limits contouring to a rectangle in pixels of the level; the
+	// zero Rectangle contours the whole image
+	Window Rectangle
+}
+
+// ContourElevationProperty is the feature property holding a contour's level
+const ContourElevationProperty = "elevation"
+
+// Contours traces contour lines with marching squares between pixel
+// centres, in physical units (see ScaleOffset). Each level with lines is
+// returned as a feature with an orb.MultiLineString geometry in the image's
+// CRS and its level in the "elevation" property, ordered by level. The
+// image is read a row of blocks at a time and segments are joined across
+// block boundaries, so lines only end at the window edges and around
+// NoData, where cells with a NoData corner are left out. Closed contours
+// end where they start.
+func (c *COG) Contours(opts ContourOptions) (*geojson.FeatureCollection, error) {
+	if opts.Overview < 0 || opts.Overview >= len(c.geoTIFFs) {
+		return nil, fmt.Errorf("overview %d not found", opts.Overview)
+	}
+	meta := c.metadata[opts.Overview]
+	if opts.Band < 0 || opts.Band >= meta.BandCount {
+		return nil, fmt.Errorf("band %d out of range (image has %d bands)", opts.Band, meta.BandCount)
+	}
+	if len(opts.Levels) == 0 && !(opts.Interval > 0) {
+		return nil, fmt.Errorf("contours need a positive interval or explicit levels")
+	}
+	gtr := c.geoTIFFs[opts.Overview]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+
+	window := opts.Window
+	if window == (Rectangle{}) {
+		window = Rectangle{Width: meta.Width, Height: meta.Height}
+	}
+	x0, y0 := max(window.X, 0), max(window.Y, 0)
+	x1, y1 := min(window.X+window.Width, meta.Width), min(window.Y+window.Height, meta.Height)
+	if x1-x0 < 2 || y1-y0 < 2 {
+		return nil, fmt.Errorf("window %+v leaves fewer than 2 x 2 pixels to contour", opts.Window)
+	}
+
+	// Sorted distinct levels, as a repeated level would trace every line twice
+	levels := append([]float64(nil), opts.Levels...)
+	sort.Float64s(levels)
+	distinct := levels[:0]
+	for i, level := range levels {
+		if math.IsNaN(level) || math.IsInf(level, 0) {
+			return nil, fmt.Errorf("invalid contour level %v", level)
+		}
+		if i == 0 || level != levels[i-1] {
+			distinct = append(distinct, level)
+		}
+	}
+	levels = distinct
+	ct := &contourTracer{
+		width:    x1 - x0,
+		levels:   levels,
+		interval: opts.Interval,
+		base:     opts.Base,
+		segments: make(map[float64][]contourSegment),
+	}
+
+	// Read rows of blocks overlapping by one row, so every cell has both
+	// of its rows
+	scale, offset := c.ScaleOffset(opts.Band)
+	_, rows := c.blockSize(opts.Overview)
+	if rows <= 0 {
+		rows = meta.Height
+	}
+	var previous []float64
+	for top := y0; top < y1; top += rows {
+		h := min(rows, y1-top)
+		data, err := c.readRaster(opts.Overview, x0, top, ct.width, h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rows %d to %d: %w", top, top+h, err)
+		}
+		for y := 0; y < h; y++ {
+			row := make([]float64, ct.width)
+			for x := range row {
+				row[x] = math.NaN()
+				if !data.IsNoData(opts.Band, x, y) {
+					row[x] = data.Float(opts.Band, x, y)*scale + offset
+				}
+			}
+			if previous != nil {
+				ct.addRow(top+y-y0-1, previous, row)
+			}
+			previous = row
+		}
+	}
+
+	// Join the segments of each level and locate them in the CRS
+	fc := geojson.NewFeatureCollection()
+	found := make([]float64, 0, len(ct.segments))
+	for level := range ct.segments {
+		found = append(found, level)
+	}
+	sort.Float64s(found)
+	for _, level := range found {
+		lines := joinContourSegments(ct.segments[level])
+		for _, line := range lines {
+			for i, p := range line {
+				x, y := gtr.pixelToGeo(float64(x0)+p[0], float64(y0)+p[1])
+				line[i] = orb.Point{x, y}
+			}
+		}
+		feature := geojson.NewFeature(lines)
+		feature.Properties[ContourElevationProperty] = level
+		fc.Append(feature)
+	}
+	return fc, nil
+}
+
+// contourPoint is a crossing of a contour with a cell edge. key identifies
+// the edge, so the segments of neighbouring cells can be joined exactly.
+type contourPoint struct {
+	key   int64
+	point orb.Point // In pixels of the window
+}
+
+// contourSegment is the part of a contour line inside one cell
+type contourSegment [2]contourPoint
+
+// contourTracer collects the contour segments of every level
+type contourTracer struct {
+	width    int
+	levels   []float64 // Sorted explicit levels, if any
+	interval float64
+	base     float64
+	segments map[float64][]contourSegment
+}
+
+// levelsIn returns the levels L with low < L <= high, the levels crossing a
+// cell whose corners range from low to high
+func (ct *contourTracer) levelsIn(low, high float64) []float64 {
+	if len(ct.levels) > 0 {
+		from := sort.Search(len(ct.levels), func(i int) bool { return ct.levels[i] > low })
+		to := sort.Search(len(ct.levels), func(i int) bool { return ct.levels[i] > high })
+		return ct.levels[from:to]
+	}
+	var levels []float64
+	for k := math.Floor((low-ct.base)/ct.interval) + 1; ct.base+k*ct.interval <= high; k++ {
+		if level := ct.base + k*ct.interval; level > low {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// addRow adds the segments of the cells between pixel rows j (upper) and
+// j+1 (lower) of the window. Cells with a NoData corner are skipped.
+func (ct *contourTracer) addRow(j int, upper, lower []float64) {
+	for i := 0; i+1 < ct.width; i++ {
+		// Corners clockwise from the top-left
+		v := [4]float64{upper[i], upper[i+1], lower[i+1], lower[i]}
+		low, high := v[0], v[0]
+		for _, value := range v {
+			if math.IsNaN(value) {
+				low = math.NaN()
+				break
+			}
+			low, high = math.Min(low, value), math.Max(high, value)
+		}
+		if math.IsNaN(low) || low == high {
+			continue
+		}
+		for _, level := range ct.levelsIn(low, high) {
+			ct.addCell(i, j, v, level)
+		}
+	}
+}
+
+// contourEdges are the corners of each cell edge, clockwise from the top,
+// in the order the crossing is interpolated: left to right and top to
+// bottom, so neighbouring cells compute identical points
+var contourEdges = [4][2]int{{0, 1}, {1, 2}, {3, 2}, {0, 3}}
+
+// addCell adds the segments of one level through the cell whose top-left
+// corner is pixel (i, j), with corner values v clockwise from the top-left
+func (ct *contourTracer) addCell(i, j int, v [4]float64, level float64) {
+	var crossings []contourPoint
+	for e, corners := range contourEdges {
+		a, b := v[corners[0]], v[corners[1]]
+		if (a >= level) != (b >= level) {
+			crossings = append(crossings, ct.crossing(i, j, e, (level-a)/(b-a)))
+		}
+	}
+
+	if len(crossings) == 2 {
+		ct.segments[level] = append(ct.segments[level], contourSegment{crossings[0], crossings[1]})
+		return
+	}
+
+	// Saddle: the top-left and bottom-right corners are on one side. If
+	// the centre is on their side too, they are connected and the lines
+	// cut off the other two corners.
+	top, right, bottom, left := crossings[0], crossings[1], crossings[2], crossings[3]
+	centre := (v[0] + v[1] + v[2] + v[3]) / 4
+	if (centre >= level) == (v[0] >= level) {
+		ct.segments[level] = append(ct.segments[level], contourSegment{top, right}, contourSegment{bottom, left})
+	} else {
+		ct.segments[level] = append(ct.segments[level], contourSegment{top, left}, contourSegment{right, bottom})
+	}
+}
+
+// crossing returns the point a fraction t along edge e of cell (i, j).
+// Pixel centres are at half-integers.
+func (ct *contourTracer) crossing(i, j, e int, t float64) contourPoint {
+	x, y := float64(i)+0.5, float64(j)+0.5
+	// Horizontal edges have even keys and vertical edges odd keys, both
+	// indexed by their top or left corner
+	switch e {
+	case 0: // Top
+		return contourPoint{key: 2 * (int64(j)*int64(ct.width) + int64(i)), point: orb.Point{x + t, y}}
+	case 1: // Right
+		return contourPoint{key: 2*(int64(j)*int64(ct.width)+int64(i+1)) + 1, point: orb.Point{x + 1, y + t}}
+	case 2: // Bottom
+		return contourPoint{key: 2 * (int64(j+1)*int64(ct.width) + int64(i)), point: orb.Point{x + t, y + 1}}
+	default: // Left
+		return contourPoint{key: 2*(int64(j)*int64(ct.width)+int64(i)) + 1, point: orb.Point{x, y + t}}
+	}
+}
+
+// joinContourSegments joins segments sharing edge crossings into lines.
+// Open lines are followed from their ends first; the remaining segments
+// form closed lines.
+func joinContourSegments(segments []contourSegment) orb.MultiLineString {
+	ends := make(map[int64][]int, 2*len(segments))
+	for s, seg := range segments {
+		ends[seg[0].key] = append(ends[seg[0].key], s)
+		ends[seg[1].key] = append(ends[seg[1].key], s)
+	}
+	used := make([]bool, len(segments))
+
+	follow := func(s int, from int64) orb.LineString {
+		seg := segments[s]
+		if seg[0].key != from {
+			seg[0], seg[1] = seg[1], seg[0]
+		}
+		line := orb.LineString{seg[0].point}
+		for {
+			used[s] = true
+			line = append(line, seg[1].point)
+			next := -1
+			for _, t := range ends[seg[1].key] {
+				if !used[t] {
+					next = t
+					break
+				}
+			}
+			if next < 0 {
+				return line
+			}
+			key := seg[1].key
+			s, seg = next, segments[next]
+			if seg[0].key != key {
+				seg[0], seg[1] = seg[1], seg[0]
+			}
+		}
+	}
+
+	var lines orb.MultiLineString
+	for s, seg := range segments {
+		for _, end := range seg {
+			if !used[s] && len(ends[end.key]) == 1 {
+				lines = append(lines, follow(s, end.key))
+			}
+		}
+	}
+	for s, seg := range segments {
+		if !used[s] {
+			lines = append(lines, follow(s, seg[0].key))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,119 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+// slopeRaster is a 10 m UTM DEM rising by 1 m per column to the east, in
+// 16 x 16 tiles
+var slopeRaster = testRaster{
+	Width: 64, Height: 64, DataType: DTFloat, TileSize: 16, Overviews: 1, EPSG: 32633, NoData: "-9999",
+	Origin: [2]float64{500000, 4000000}, PixelSize: [2]float64{10, 10},
+	Value: func(band, x, y int) float64 { return float64(x) },
+}
+
+func TestContours(t *testing.T) {
+	c := slopeRaster.cog(t)
+	fc, err := c.Contours(ContourOptions{Interval: 10})
+	if err != nil {
+		t.Fatalf("Contours failed: %v", err)
+	}
+	if len(fc.Features) != 6 {
+		t.Fatalf("expected levels 10 to 60, got %d features", len(fc.Features))
+	}
+	for i, f := range fc.Features {
+		level := float64(10 * (i + 1))
+		if f.Properties[ContourElevationProperty] != level {
+			t.Errorf("feature %d: expected level %v, got %v", i, level, f.Properties[ContourElevationProperty])
+		}
+		lines := f.Geometry.(orb.MultiLineString)
+		// One line through all four rows of tiles, at the centre of the
+		// column whose value is the level
+		if len(lines) != 1 || len(lines[0]) != 64 {
+			t.Fatalf("level %v: expected one line of 64 points, got %v", level, lines)
+		}
+		x := 500000 + (level+0.5)*10
+		if b := lines.Bound(); b.Min[0] != x || b.Max[0] != x || b.Min[1] != 4000000-635 || b.Max[1] != 4000000-5 {
+			t.Errorf("level %v: unexpected bounds %v", level, b)
+		}
+	}
+
+	fc, err = c.Contours(ContourOptions{Levels: []float64{15.5}})
+	if err != nil {
+		t.Fatalf("Contours failed: %v", err)
+	}
+	if b := fc.Features[0].Geometry.Bound(); len(fc.Features) != 1 || b.Min[0] != 500160 {
+		t.Errorf("expected level 15.5 at 500160, got %v", b)
+	}
+
+	// Repeated levels are traced once
+	fc, err = c.Contours(ContourOptions{Levels: []float64{20, 10, 20, 10}})
+	if err != nil {
+		t.Fatalf("Contours failed: %v", err)
+	}
+	for _, f := range fc.Features {
+		if lines := f.Geometry.(orb.MultiLineString); len(lines) != 1 || len(lines[0]) != 64 {
+			t.Errorf("expected one line of 64 points for repeated levels, got %v", lines)
+		}
+	}
+	if len(fc.Features) != 2 {
+		t.Errorf("expected 2 features for repeated levels, got %d", len(fc.Features))
+	}
+
+	// A window only sees its own levels
+	fc, err = c.Contours(ContourOptions{Interval: 10, Window: Rectangle{X: 8, Width: 16, Height: 64}})
+	if err != nil {
+		t.Fatalf("Contours failed: %v", err)
+	}
+	if len(fc.Features) != 2 || fc.Features[1].Geometry.Bound().Min[0] != 500205 {
+		t.Errorf("expected levels 10 and 20 in the window, got %d features", len(fc.Features))
+	}
+
+	if _, err := c.Contours(ContourOptions{}); err == nil {
+		t.Error("expected error without an interval or levels")
+	}
+	if _, err := c.Contours(ContourOptions{Levels: []float64{10, math.NaN()}}); err == nil {
+		t.Error("expected error for a NaN level")
+	}
+}
+
+func TestContoursNoDataAndRings(t *testing.T) {
+	// A NoData band across the slope splits every line in two
+	tr := slopeRaster
+	tr.Value = func(band, x, y int) float64 {
+		if y >= 30 && y < 34 {
+			return -9999
+		}
+		return float64(x)
+	}
+	fc, err := tr.cog(t).Contours(ContourOptions{Interval: 10})
+	if err != nil {
+		t.Fatalf("Contours failed: %v", err)
+	}
+	for _, f := range fc.Features {
+		if lines := f.Geometry.(orb.MultiLineString); len(lines) != 2 {
+			t.Errorf("expected the NoData band to split the line, got %d lines", len(lines))
+		}
+	}
+
+	// A cone gives closed rings centred on its peak
+	tr.Value = func(band, x, y int) float64 {
+		return 100 - 2*math.Hypot(float64(x)-31.5, float64(y)-31.5)
+	}
+	fc, err = tr.cog(t).Contours(ContourOptions{Levels: []float64{80}})
+	if err != nil {
+		t.Fatalf("Contours failed: %v", err)
+	}
+	lines := fc.Features[0].Geometry.(orb.MultiLineString)
+	if len(lines) != 1 || len(lines[0]) < 16 || lines[0][0] != lines[0][len(lines[0])-1] {
+		t.Fatalf("expected one closed ring, got %v", lines)
+	}
+	for _, p := range lines[0] {
+		if r := math.Hypot(p[0]-500320, p[1]-(4000000-320)); math.Abs(r-100) > 5 {
+			t.Errorf("expected the ring 100 m from the peak, got %v at %v", r, p)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/clip"
+	"github.com/paulmach/orb/maptile"
+	"github.com/paulmach/orb/maptile/tilecover"
+	"github.com/paulmach/orb/planar"
+	"github.com/tingold/gocog/proj"
+)
+
+// footprintEdgeSamples is the number of points per image edge in
+// LonLatFootprint
+const footprintEdgeSamples = 32
+
+// maxMercatorLatitude is the latitude limit of Web Mercator tiles
+const maxMercatorLatitude = 85.05112877980659
+
+// LonLatFootprint returns the outline of the main image in longitude and
+// latitude. Edges are densified, so curved edges of projected images and of
+// images georeferenced by GCPs or RPCs are followed, and rotated images are
+// not widened to their bounding box. The outline is clipped to the Web
+// Mercator latitude range and split at the antimeridian into a polygon on
+// each side.
+func (c *COG) LonLatFootprint() (orb.MultiPolygon, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image metadata")
+	}
+	gtr := c.geoTIFFs[0]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+	projection, err := c.Projection()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported CRS: %w", err)
+	}
+
+	// Walk the image edges clockwise from the top-left corner
+	width, height := float64(c.metadata[0].Width), float64(c.metadata[0].Height)
+	corners := [][2]float64{{0, 0}, {width, 0}, {width, height}, {0, height}}
+	var ring orb.Ring
+	for i, from := range corners {
+		to := corners[(i+1)%len(corners)]
+		for s := 0; s < footprintEdgeSamples; s++ {
+			f := float64(s) / footprintEdgeSamples
+			x, y := gtr.pixelToGeo(from[0]+f*(to[0]-from[0]), from[1]+f*(to[1]-from[1]))
+			lon, lat := projection.Inverse(x, y)
+			if math.IsNaN(lon) || math.IsNaN(lat) || math.IsInf(lon, 0) || math.IsInf(lat, 0) {
+				continue
+			}
+			// Keep longitudes continuous across the antimeridian
+			if n := len(ring); n > 0 {
+				lon = wrapLongitude(lon, ring[n-1][0]-180)
+			}
+			ring = append(ring, orb.Point{lon, lat})
+		}
+	}
+	if len(ring) < 3 {
+		return nil, fmt.Errorf("failed to locate the image outline")
+	}
+	ring = append(ring, ring[0])
+
+	// Clip the outline to the world, shifted by each multiple of 360° it
+	// reaches
+	world := orb.Bound{Min: orb.Point{-180, -maxMercatorLatitude}, Max: orb.Point{180, maxMercatorLatitude}}
+	bound := ring.Bound()
+	var footprint orb.MultiPolygon
+	for shift := 360 * math.Ceil((-180-bound.Max[0])/360); bound.Min[0]+shift < 180; shift += 360 {
+		shifted := make(orb.Ring, len(ring))
+		for i, p := range ring {
+			shifted[i] = orb.Point{p[0] + shift, p[1]}
+		}
+		if clipped := clip.Ring(world, shifted); len(clipped) >= 4 && planar.Area(clipped) != 0 {
+			footprint = append(footprint, orb.Polygon{clipped})
+		}
+	}
+	if len(footprint) == 0 {
+		return nil, fmt.Errorf("image lies outside the Web Mercator latitude range")
+	}
+	return footprint, nil
+}
+
+// TileCoverage returns the Web Mercator map tiles at a zoom level that
+// intersect the image's footprint (see LonLatFootprint), e.g. for seeding
+// tile caches
+func (c *COG) TileCoverage(zoom maptile.Zoom) (maptile.Set, error) {
+	footprint, err := c.LonLatFootprint()
+	if err != nil {
+		return nil, err
+	}
+	return coverFootprint(footprint, zoom)
+}
+
+// TileCoverageRange returns the tiles intersecting the image's footprint at
+// every zoom level from minZoom to maxZoom
+func (c *COG) TileCoverageRange(minZoom, maxZoom maptile.Zoom) (map[maptile.Zoom]maptile.Set, error) {
+	if minZoom > maxZoom {
+		return nil, fmt.Errorf("minimum zoom %d exceeds maximum zoom %d", minZoom, maxZoom)
+	}
+	footprint, err := c.LonLatFootprint()
+	if err != nil {
+		return nil, err
+	}
+	coverage := make(map[maptile.Zoom]maptile.Set, maxZoom-minZoom+1)
+	for z := minZoom; z <= maxZoom; z++ {
+		set, err := coverFootprint(footprint, z)
+		if err != nil {
+			return nil, err
+		}
+		coverage[z] = set
+	}
+	return coverage, nil
+}
+
+// coverFootprint returns the tiles at zoom intersecting a footprint
+func coverFootprint(footprint orb.MultiPolygon, zoom maptile.Zoom) (maptile.Set, error) {
+	set, err := tilecover.MultiPolygon(footprint, zoom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cover the footprint at zoom %d: %w", zoom, err)
+	}
+	// Edges on the antimeridian or the latitude limits touch tiles beyond
+	// the matrix
+	n := uint32(1) << zoom
+	for tile := range set {
+		if tile.X >= n || tile.Y >= n {
+			delete(set, tile)
+		}
+	}
+	return set, nil
+}
+
+// maxRecommendedZoom bounds the zoom levels recommended by ZoomRange
+const maxRecommendedZoom = 30
+
+// ZoomRange recommends the native zoom levels of the image for Web Mercator
+// tiles of tileSize pixels (256 if not given). maxZoom is the first level
+// whose pixels are at least as fine as the full-resolution pixels, so no
+// detail is lost; minZoom is the last level whose pixels are at least as
+// coarse as those of the coarsest overview, below which tiles would read
+// far more data than they show. Pixel sizes are measured at the image
+// centre.
+func (c *COG) ZoomRange(tileSize ...int) (minZoom, maxZoom maptile.Zoom, err error) {
+	if len(c.metadata) == 0 {
+		return 0, 0, fmt.Errorf("no image metadata")
+	}
+	size := 256
+	if len(tileSize) > 0 && tileSize[0] > 0 {
+		size = tileSize[0]
+	}
+	projection, err := c.Projection()
+	if err != nil {
+		return 0, 0, fmt.Errorf("unsupported CRS: %w", err)
+	}
+
+	native, err := c.mercatorResolution(0, projection)
+	if err != nil {
+		return 0, 0, err
+	}
+	coarsest, err := c.mercatorResolution(len(c.geoTIFFs)-1, projection)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Zoom z has pixels of worldResolution / 2^z metres
+	worldResolution := 360 * metresPerDegree / float64(size)
+	const tolerance = 1e-6
+	maxLevel := math.Ceil(math.Log2(worldResolution/native) - tolerance)
+	minLevel := math.Floor(math.Log2(worldResolution/coarsest) + tolerance)
+	maxLevel = math.Min(math.Max(maxLevel, 0), maxRecommendedZoom)
+	minLevel = math.Min(math.Max(minLevel, 0), maxLevel)
+	return maptile.Zoom(minLevel), maptile.Zoom(maxLevel), nil
+}
+
+// mercatorResolution returns the size in Web Mercator metres of the finer
+// side of the pixel at the centre of an IFD
+func (c *COG) mercatorResolution(ifdIndex int, projection proj.Projection) (float64, error) {
+	gtr := c.geoTIFFs[ifdIndex]
+	if !gtr.georeferenced() {
+		return 0, fmt.Errorf("image is not georeferenced")
+	}
+	meta := c.metadata[ifdIndex]
+	cx, cy := float64(meta.Width)/2, float64(meta.Height)/2
+
+	mercator := func(px, py float64) orb.Point {
+		x, y := gtr.pixelToGeo(px, py)
+		lon, lat := projection.Inverse(x, y)
+		mx, my := proj.WebMercator.Forward(lon, lat)
+		return orb.Point{mx, my}
+	}
+	centre, right, below := mercator(cx, cy), mercator(cx+1, cy), mercator(cx, cy+1)
+	resolution := math.Min(
+		math.Hypot(right[0]-centre[0], right[1]-centre[1]),
+		math.Hypot(below[0]-centre[0], below[1]-centre[1]),
+	)
+	if !(resolution > 0) || math.IsInf(resolution, 0) {
+		return 0, fmt.Errorf("failed to measure the pixel size of IFD %d", ifdIndex)
+	}
+	return resolution, nil
+}
@@ -0,0 +1,96 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/paulmach/orb/maptile/tilecover"
+)
+
+func TestTileCoverage(t *testing.T) {
+	// A diamond: the image rotated by 45° about its top-left corner
+	step := 0.01 / math.Sqrt2
+	c := testRaster{
+		Width: 128, Height: 128, EPSG: 4326,
+		Transform: GeoTransform{10, step, step, 50, step, -step},
+	}.cog(t)
+
+	footprint, err := c.LonLatFootprint()
+	if err != nil {
+		t.Fatalf("LonLatFootprint failed: %v", err)
+	}
+	if len(footprint) != 1 {
+		t.Fatalf("expected one polygon, got %d", len(footprint))
+	}
+
+	tiles, err := c.TileCoverage(10)
+	if err != nil {
+		t.Fatalf("TileCoverage failed: %v", err)
+	}
+	if !tiles[maptile.At(orb.Point{10.9, 50}, 10)] {
+		t.Error("expected the tile at the image centre")
+	}
+	if tiles[maptile.At(orb.Point{10.02, 50.88}, 10)] {
+		t.Error("expected the corner of the bounding box outside the diamond not to be covered")
+	}
+	if bbox := tilecover.Bound(c.Bounds(), 10); len(tiles) >= len(bbox) {
+		t.Errorf("expected fewer tiles than the bounding box's %d, got %d", len(bbox), len(tiles))
+	}
+
+	coverage, err := c.TileCoverageRange(8, 10)
+	if err != nil {
+		t.Fatalf("TileCoverageRange failed: %v", err)
+	}
+	if len(coverage) != 3 || len(coverage[10]) != len(tiles) || len(coverage[8]) == 0 {
+		t.Errorf("unexpected coverage %v", coverage)
+	}
+	if _, err := c.TileCoverageRange(10, 8); err == nil {
+		t.Error("expected error for an inverted zoom range")
+	}
+}
+
+func TestTileCoverageAntimeridian(t *testing.T) {
+	c := testRaster{Width: 20, Height: 20, EPSG: 4326, Origin: [2]float64{170, 30}}.cog(t)
+	footprint, err := c.LonLatFootprint()
+	if err != nil {
+		t.Fatalf("LonLatFootprint failed: %v", err)
+	}
+	if len(footprint) != 2 {
+		t.Fatalf("expected a polygon on each side of the antimeridian, got %d", len(footprint))
+	}
+
+	tiles, err := c.TileCoverage(3)
+	if err != nil {
+		t.Fatalf("TileCoverage failed: %v", err)
+	}
+	for _, tile := range []maptile.Tile{{X: 7, Y: 3, Z: 3}, {X: 0, Y: 3, Z: 3}} {
+		if !tiles[tile] {
+			t.Errorf("expected tile %v to be covered", tile)
+		}
+	}
+	if len(tiles) != 2 {
+		t.Errorf("expected 2 tiles, got %v", tiles)
+	}
+}
+
+func TestZoomRange(t *testing.T) {
+	c := testRaster{
+		Width: 128, Height: 128, TileSize: 32, Overviews: 2, EPSG: 4326,
+		Origin: [2]float64{10, 50}, PixelSize: [2]float64{0.01, 0.01},
+	}.cog(t)
+
+	// 0.01° is 1113 m, between zoom 7 (1223 m) and 8 (611 m); the 0.04°
+	// overview is 4453 m, between zoom 5 (4892 m) and 6 (2446 m)
+	minZoom, maxZoom, err := c.ZoomRange()
+	if err != nil {
+		t.Fatalf("ZoomRange failed: %v", err)
+	}
+	if minZoom != 5 || maxZoom != 8 {
+		t.Errorf("expected zooms 5 to 8, got %d to %d", minZoom, maxZoom)
+	}
+	if minZoom, maxZoom, _ = c.ZoomRange(512); minZoom != 4 || maxZoom != 7 {
+		t.Errorf("expected zooms 4 to 7 for 512 pixel tiles, got %d to %d", minZoom, maxZoom)
+	}
+}
@@ -0,0 +1,588 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tingold/gocog/proj"
+)
+
+// CRS describes a geographic or projected coordinate reference system
+type CRS struct {
+	Name string // e.g. "WGS 84 / UTM zone 33N"
+	Code int    // EPSG code, 0 if user-defined
+
+	// Geographic is the CRS itself if it is geographic, or the base CRS
+	// of a projected CRS
+	Geographic GeographicCRS
+
+	Conversion *Conversion // Map projection, nil for a geographic CRS
+	LinearUnit Unit        // Unit of projected coordinates
+
+	// AxisOrder is the official axis order of the CRS. GeoTIFF coordinates
+	// are always longitude/easting first.
+	AxisOrder proj.AxisOrder
+	AreaOfUse *proj.AreaOfUse // nil if unknown
+}
+
+// GeographicCRS is a geographic (longitude/latitude) coordinate reference system
+type GeographicCRS struct {
+	Name          string
+	Code          int // EPSG code, 0 if user-defined
+	Datum         Datum
+	PrimeMeridian PrimeMeridian
+	AngularUnit   Unit
+}
+
+// Datum is a geodetic datum
+type Datum struct {
+	Name      string
+	Code      int // EPSG code, 0 if user-defined
+	Ellipsoid Ellipsoid
+}
+
+// Ellipsoid is a named reference ellipsoid
+type Ellipsoid struct {
+	Name string
+	Code int // EPSG code, 0 if user-defined
+	proj.Ellipsoid
+}
+
+// PrimeMeridian is the meridian from which longitudes are measured
+type PrimeMeridian struct {
+	Name      string
+	Code      int     // EPSG code, 0 if user-defined
+	Longitude float64 // Degrees east of Greenwich
+}
+
+// Unit is a unit of measure
+type Unit struct {
+	Name   string
+	Code   int     // EPSG code, 0 if user-defined
+	Factor float64 // Metres per unit for linear units, radians per unit for angular units
+}
+
+// Conversion is the map projection of a projected CRS
+type Conversion struct {
+	Name string
+	Code int // EPSG code, 0 if user-defined
+
+	// Params holds the method and its parameters, with angles in degrees
+	// and distances in metres. The ellipsoid is that of the datum.
+	Params proj.Params
+}
+
+var greenwich = PrimeMeridian{Name: "Greenwich", Code: 8901}
+
+// IsGeographic reports whether the CRS is geographic
+func (c *CRS) IsGeographic() bool {
+	return c.Conversion == nil
+}
+
+// String returns "EPSG:<code>" for registered systems and the name otherwise
+func (c *CRS) String() string {
+	if c.Code != 0 {
+		return fmt.Sprintf("EPSG:%d", c.Code)
+	}
+	return c.Name
+}
+
+// Projection returns the projection converting between longitude/latitude
+// (relative to Greenwich) and the coordinates of the CRS
+func (c *CRS) Projection() (proj.Projection, error) {
+	var p proj.Projection = proj.Geographic{}
+	if c.Conversion != nil {
+		params := c.Conversion.Params
+		if params.Method != proj.MethodWebMercator {
+			params.Ellipsoid = c.Geographic.Datum.Ellipsoid.Ellipsoid
+		}
+		var err error
+		if p, err = proj.New(params); err != nil {
+			return nil, err
+		}
+		if factor := c.LinearUnit.Factor; factor != 0 && factor != 1 {
+			p = proj.WithUnits(p, factor)
+		}
+	}
+	if pm := c.Geographic.PrimeMeridian.Longitude; pm != 0 {
+		p = primeMeridianProjection{Projection: p, longitude: pm}
+	}
+	return p, nil
+}
+
+// primeMeridianProjection measures longitudes from a prime meridian other
+// than Greenwich
+type primeMeridianProjection struct {
+	proj.Projection
+	longitude float64
+}
+
+func (p primeMeridianProjection) Forward(lon, lat float64) (float64, float64) {
+	return p.Projection.Forward(lon-p.longitude, lat)
+}
+
+func (p primeMeridianProjection) Inverse(x, y float64) (float64, float64) {
+	lon, lat := p.Projection.Inverse(x, y)
+	return lon + p.longitude, lat
+}
+
+// Equivalent reports whether two CRSs define the same coordinates, ignoring
+// names. Datums with EPSG codes are compared by code; otherwise their
+// ellipsoids are compared.
+func (c *CRS) Equivalent(other *CRS) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if c.IsGeographic() != other.IsGeographic() || !c.Geographic.equivalent(other.Geographic) {
+		return false
+	}
+	if c.IsGeographic() {
+		return true
+	}
+	if !closeTo(c.LinearUnit.Factor, other.LinearUnit.Factor, 1e-12) || c.Conversion.Params.Method != other.Conversion.Params.Method {
+		return false
+	}
+	a, b := c.Conversion.parameters(), other.Conversion.parameters()
+	for i := range a {
+		tolerance := 1e-9 // Degrees and scale factors
+		if a[i].kind == parameterLength {
+			tolerance = 1e-4 // Metres
+		}
+		if math.Abs(a[i].value-b[i].value) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func (g GeographicCRS) equivalent(other GeographicCRS) bool {
+	if g.Datum.Code != 0 && other.Datum.Code != 0 {
+		if g.Datum.Code != other.Datum.Code {
+			return false
+		}
+	} else {
+		a, b := g.Datum.Ellipsoid, other.Datum.Ellipsoid
+		if math.Abs(a.SemiMajor-b.SemiMajor) > 1e-3 || math.Abs(a.Flattening()-b.Flattening()) > 1e-12 {
+			return false
+		}
+	}
+	return math.Abs(g.PrimeMeridian.Longitude-other.PrimeMeridian.Longitude) < 1e-9 &&
+		closeTo(g.AngularUnit.Factor, other.AngularUnit.Factor, 1e-12)
+}
+
+// closeTo reports whether a and b agree to a relative tolerance
+func closeTo(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// parameterKind is the kind of quantity of a projection parameter
+type parameterKind int
+
+const (
+	parameterAngle parameterKind = iota
+	parameterScale
+	parameterLength
+)
+
+// parameter is a projection parameter with its EPSG name and code
+type parameter struct {
+	name  string
+	code  int
+	kind  parameterKind
+	value float64 // Degrees, unity or metres
+}
+
+// method returns the EPSG name and code of the conversion's method
+func (cv *Conversion) method() (string, int) {
+	switch cv.Params.Method {
+	case proj.MethodTransverseMercator:
+		return "Transverse Mercator", 9807
+	case proj.MethodLambertConformalConic1SP:
+		return "Lambert Conic Conformal (1SP)", 9801
+	case proj.MethodLambertConformalConic2SP:
+		return "Lambert Conic Conformal (2SP)", 9802
+	case proj.MethodAlbersEqualArea:
+		return "Albers Equal Area", 9822
+	case proj.MethodLambertAzimuthalEqualArea:
+		return "Lambert Azimuthal Equal Area", 9820
+	case proj.MethodPolarStereographicA:
+		return "Polar Stereographic (variant A)", 9810
+	case proj.MethodPolarStereographicB:
+		return "Polar Stereographic (variant B)", 9829
+	case proj.MethodWebMercator:
+		return "Popular Visualisation Pseudo Mercator", 1024
+	}
+	return "unknown", 0
+}
+
+// parameters returns the EPSG parameters of the conversion's method
+func (cv *Conversion) parameters() []parameter {
+	p := cv.Params
+	scale := p.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	natural := []parameter{
+		{"Latitude of natural origin", 8801, parameterAngle, p.LatOrigin},
+		{"Longitude of natural origin", 8802, parameterAngle, p.LonOrigin},
+		{"Scale factor at natural origin", 8805, parameterScale, scale},
+		{"False easting", 8806, parameterLength, p.FalseEasting},
+		{"False northing", 8807, parameterLength, p.FalseNorthing},
+	}
+
+	switch p.Method {
+	case proj.MethodTransverseMercator, proj.MethodLambertConformalConic1SP, proj.MethodPolarStereographicA:
+		return natural
+	case proj.MethodLambertAzimuthalEqualArea, proj.MethodWebMercator:
+		return append(natural[:2:2], natural[3:]...)
+	case proj.MethodLambertConformalConic2SP, proj.MethodAlbersEqualArea:
+		return []parameter{
+			{"Latitude of false origin", 8821, parameterAngle, p.LatOrigin},
+			{"Longitude of false origin", 8822, parameterAngle, p.LonOrigin},
+			{"Latitude of 1st standard parallel", 8823, parameterAngle, p.StdParallel1},
+			{"Latitude of 2nd standard parallel", 8824, parameterAngle, p.StdParallel2},
+			{"Easting at false origin", 8826, parameterLength, p.FalseEasting},
+			{"Northing at false origin", 8827, parameterLength, p.FalseNorthing},
+		}
+	case proj.MethodPolarStereographicB:
+		return []parameter{
+			{"Latitude of standard parallel", 8832, parameterAngle, p.StdParallel1},
+			{"Longitude of origin", 8833, parameterAngle, p.LonOrigin},
+			natural[3],
+			natural[4],
+		}
+	}
+	return nil
+}
+
+// CoordinateReferenceSystem returns the full definition of the image's CRS,
+// built from its GeoKeys: datum, ellipsoid, prime meridian, projection
+// method and parameters, and units. User-defined systems (code 32767) are
+// described by their individual GeoKeys. CRS returns the EPSG code alone.
+func (c *COG) CoordinateReferenceSystem() (*CRS, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image metadata")
+	}
+	return crsFromGeoKeys(crsGeoKeys(c.metadata[0]))
+}
+
+// CRSName returns the name of the image's CRS (e.g. "WGS 84 / UTM zone
+// 33N"), or "" if it is unknown
+func (c *COG) CRSName() string {
+	crs, err := c.CoordinateReferenceSystem()
+	if err != nil {
+		return ""
+	}
+	return crs.Name
+}
+
+// CRSUnit returns the unit of the image's coordinates: the linear unit of a
+// projected CRS or the angular unit of a geographic CRS
+func (c *COG) CRSUnit() (Unit, error) {
+	crs, err := c.CoordinateReferenceSystem()
+	if err != nil {
+		return Unit{}, err
+	}
+	if crs.IsGeographic() {
+		return crs.Geographic.AngularUnit, nil
+	}
+	return crs.LinearUnit, nil
+}
+
+// AreaOfUse returns the area of use of the image's CRS, if it is registered
+func (c *COG) AreaOfUse() (proj.AreaOfUse, bool) {
+	crs, err := c.CoordinateReferenceSystem()
+	if err != nil || crs.AreaOfUse == nil {
+		return proj.AreaOfUse{}, false
+	}
+	return *crs.AreaOfUse, true
+}
+
+// geoKeys provides typed access to a GeoKey directory
+type geoKeys map[uint16]interface{}
+
+// code returns the value of a SHORT GeoKey
+func (k geoKeys) code(id uint16) (int, bool) {
+	v, ok := k[id].(uint16)
+	return int(v), ok
+}
+
+// double returns the value of the first of the DOUBLE GeoKeys that is set
+func (k geoKeys) double(ids ...uint16) (float64, bool) {
+	for _, id := range ids {
+		if v, ok := k[id].(float64); ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// citation returns an ASCII GeoKey without GDAL's trailing "|" separators
+func (k geoKeys) citation(id uint16) string {
+	s, _ := k[id].(string)
+	return strings.TrimSpace(strings.TrimRight(s, "|"))
+}
+
+// crsFromGeoKeys builds the CRS described by a GeoKey directory
+func crsFromGeoKeys(keys map[uint16]interface{}) (*CRS, error) {
+	k := geoKeys(keys)
+	modelType, hasModelType := k.code(GTModelTypeGeoKey)
+	projectedCode, hasProjectedCode := k.code(ProjectedCSTypeGeoKey)
+	_, hasGeographicCode := k.code(GeographicTypeGeoKey)
+	_, hasCoordTrans := k.code(ProjCoordTransGeoKey)
+	_, hasProjection := k.code(ProjectionGeoKey)
+
+	if hasProjectedCode && projectedCode != 0 && projectedCode != userDefined {
+		return projectedCRSFromEPSG(projectedCode)
+	}
+	if !hasModelType && !hasProjectedCode && !hasGeographicCode && !hasCoordTrans && !hasProjection {
+		return nil, fmt.Errorf("image has no CRS")
+	}
+
+	geographic, err := k.geographicCRS()
+	if err != nil {
+		return nil, err
+	}
+	if modelType != GTModelTypeProjected && !hasProjectedCode && !hasCoordTrans && !hasProjection {
+		crs := &CRS{Name: geographic.Name, Code: geographic.Code, Geographic: geographic, AxisOrder: proj.AxisNorthEast}
+		if info, ok := proj.LookupCRS(geographic.Code); ok && info.Geographic {
+			crs.AreaOfUse = &info.AreaOfUse
+		}
+		return crs, nil
+	}
+
+	unit := unitMetre
+	if code, ok := k.code(ProjLinearUnitsGeoKey); ok {
+		if code == userDefined {
+			size, _ := k.double(ProjLinearUnitSizeGeoKey)
+			if size <= 0 {
+				return nil, fmt.Errorf("user-defined linear unit without a size")
+			}
+			unit = Unit{Name: "unknown", Factor: size}
+		} else if unit, ok = linearUnits[code]; !ok {
+			return nil, fmt.Errorf("unsupported linear unit %d", code)
+		}
+	}
+
+	conversion, err := k.conversion(unit, geographic.AngularUnit)
+	if err != nil {
+		return nil, err
+	}
+	name := k.citation(PCSCitationGeoKey)
+	if name == "" {
+		name = k.citation(GTCitationGeoKey)
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return &CRS{Name: name, Geographic: geographic, Conversion: conversion, LinearUnit: unit}, nil
+}
+
+// geographicCRS returns the geographic CRS of the GeoKeys, from its code or
+// from the user-defined datum, ellipsoid, prime meridian and angular unit
+func (k geoKeys) geographicCRS() (GeographicCRS, error) {
+	if code, ok := k.code(GeographicTypeGeoKey); ok && code != userDefined && code != 0 {
+		if g, ok := geographicCRSFromEPSG(code); ok {
+			return g, nil
+		}
+	}
+
+	g := GeographicCRS{Name: k.citation(GeogCitationGeoKey), PrimeMeridian: greenwich, AngularUnit: unitDegree}
+	if code, ok := k.code(GeographicTypeGeoKey); ok && code != userDefined {
+		g.Code = code
+		if g.Name == "" {
+			g.Name = fmt.Sprintf("EPSG:%d", code)
+		}
+	}
+	if g.Name == "" {
+		g.Name = "unknown"
+	}
+
+	datum, ok := Datum{}, false
+	if code, hasCode := k.code(GeogGeodeticDatumGeoKey); hasCode && code != userDefined {
+		if datum, ok = datumFromEPSG(code); !ok {
+			return GeographicCRS{}, fmt.Errorf("unsupported geodetic datum %d", code)
+		}
+	}
+	if !ok {
+		// User-defined datum on a coded or user-defined ellipsoid, WGS 84 by default
+		wgs84, _ := proj.LookupEllipsoid(7030)
+		datum = Datum{Name: "unknown", Ellipsoid: ellipsoidFromInfo(wgs84)}
+		if code, hasCode := k.code(GeogEllipsoidGeoKey); hasCode && code != userDefined {
+			info, ok := proj.LookupEllipsoid(code)
+			if !ok {
+				return GeographicCRS{}, fmt.Errorf("unsupported ellipsoid %d", code)
+			}
+			datum.Ellipsoid = ellipsoidFromInfo(info)
+		} else if a, hasA := k.double(GeogSemiMajorAxisGeoKey); hasA && a > 0 {
+			datum.Ellipsoid = Ellipsoid{Name: "unknown", Ellipsoid: proj.Ellipsoid{SemiMajor: a}}
+			if invF, _ := k.double(GeogInvFlatteningGeoKey); invF != 0 {
+				datum.Ellipsoid.InvFlattening = invF
+			} else if b, _ := k.double(GeogSemiMinorAxisGeoKey); b != 0 && b != a {
+				datum.Ellipsoid.InvFlattening = a / (a - b)
+			}
+		}
+	}
+	g.Datum = datum
+
+	if code, ok := k.code(GeogAngularUnitsGeoKey); ok {
+		if code == userDefined {
+			size, _ := k.double(GeogAngularUnitSizeGeoKey)
+			if size <= 0 {
+				return GeographicCRS{}, fmt.Errorf("user-defined angular unit without a size")
+			}
+			g.AngularUnit = Unit{Name: "unknown", Factor: size}
+		} else if g.AngularUnit, ok = angularUnits[code]; !ok {
+			return GeographicCRS{}, fmt.Errorf("unsupported angular unit %d", code)
+		}
+	}
+	// The prime meridian's longitude is in the angular unit
+	if code, ok := k.code(GeogPrimeMeridianGeoKey); ok {
+		if code == userDefined {
+			longitude, _ := k.double(GeogPrimeMeridianLongGeoKey)
+			g.PrimeMeridian = PrimeMeridian{Name: "unknown", Longitude: toDegrees(longitude, g.AngularUnit)}
+		} else if g.PrimeMeridian, ok = primeMeridians[code]; !ok {
+			return GeographicCRS{}, fmt.Errorf("unsupported prime meridian %d", code)
+		}
+	}
+	return g, nil
+}
+
+// conversion returns the map projection of the GeoKeys, from a coded
+// projection (UTM zones) or from the method and parameter GeoKeys, whose
+// false easting and northing are in unit and angles in angularUnit (the
+// GeogAngularUnits)
+func (k geoKeys) conversion(unit, angularUnit Unit) (*Conversion, error) {
+	if code, ok := k.code(ProjectionGeoKey); ok && code != userDefined {
+		// EPSG conversion codes 16001-16060 and 16101-16160 are UTM zones
+		zone, north := code-16000, true
+		if code > 16100 {
+			zone, north = code-16100, false
+		}
+		if zone < 1 || zone > 60 {
+			return nil, fmt.Errorf("unsupported projection %d", code)
+		}
+		return utmConversion(zone, north), nil
+	}
+
+	double := func(ids ...uint16) float64 {
+		v, _ := k.double(ids...)
+		return v
+	}
+	angle := func(ids ...uint16) float64 {
+		return toDegrees(double(ids...), angularUnit)
+	}
+	params := proj.Params{
+		Scale:         double(ProjScaleAtNatOriginGeoKey, ProjScaleAtCenterGeoKey),
+		FalseEasting:  double(ProjFalseEastingGeoKey, ProjFalseOriginEastingGeoKey, ProjCenterEastingGeoKey) * unit.Factor,
+		FalseNorthing: double(ProjFalseNorthingGeoKey, ProjFalseOriginNorthingGeoKey, ProjCenterNorthingGeoKey) * unit.Factor,
+	}
+
+	method, _ := k.code(ProjCoordTransGeoKey)
+	switch method {
+	case CTTransverseMercator:
+		params.Method = proj.MethodTransverseMercator
+		params.LatOrigin = angle(ProjNatOriginLatGeoKey)
+		params.LonOrigin = angle(ProjNatOriginLongGeoKey)
+	case CTLambertConfConic1SP:
+		params.Method = proj.MethodLambertConformalConic1SP
+		params.LatOrigin = angle(ProjNatOriginLatGeoKey)
+		params.LonOrigin = angle(ProjNatOriginLongGeoKey)
+	case CTLambertConfConic2SP, CTAlbersEqualArea:
+		params.Method = proj.MethodLambertConformalConic2SP
+		if method == CTAlbersEqualArea {
+			params.Method = proj.MethodAlbersEqualArea
+		}
+		params.StdParallel1 = angle(ProjStdParallel1GeoKey)
+		params.StdParallel2 = angle(ProjStdParallel2GeoKey)
+		params.LatOrigin = angle(ProjFalseOriginLatGeoKey, ProjNatOriginLatGeoKey, ProjCenterLatGeoKey)
+		params.LonOrigin = angle(ProjFalseOriginLongGeoKey, ProjNatOriginLongGeoKey, ProjCenterLongGeoKey)
+	case CTLambertAzimEqualArea:
+		params.Method = proj.MethodLambertAzimuthalEqualArea
+		params.LatOrigin = angle(ProjCenterLatGeoKey, ProjNatOriginLatGeoKey)
+		params.LonOrigin = angle(ProjCenterLongGeoKey, ProjNatOriginLongGeoKey)
+	case CTPolarStereographic:
+		// A latitude of origin other than ±90 is the latitude of true scale (variant B)
+		lat := angle(ProjNatOriginLatGeoKey)
+		params.LonOrigin = angle(ProjStraightVertPoleLongGeoKey, ProjNatOriginLongGeoKey)
+		if math.Abs(math.Abs(lat)-90) < 1e-9 {
+			params.Method = proj.MethodPolarStereographicA
+			params.LatOrigin = math.Copysign(90, lat)
+		} else {
+			params.Method = proj.MethodPolarStereographicB
+			params.StdParallel1 = lat
+		}
+	default:
+		return nil, fmt.Errorf("unsupported projection method %d", method)
+	}
+	return &Conversion{Name: "unnamed", Params: params}, nil
+}
+
+// toDegrees converts an angle in unit to degrees
+func toDegrees(angle float64, unit Unit) float64 {
+	if unit.Factor == unitDegree.Factor || unit.Factor == 0 {
+		return angle
+	}
+	return angle * unit.Factor / unitDegree.Factor
+}
+
+// utmConversion returns the conversion of a UTM zone
+func utmConversion(zone int, north bool) *Conversion {
+	p := proj.Params{
+		Method:       proj.MethodTransverseMercator,
+		LonOrigin:    float64(zone)*6 - 183,
+		Scale:        0.9996,
+		FalseEasting: 500000,
+	}
+	hemisphere, code := "N", 16000+zone
+	if !north {
+		p.FalseNorthing = 10000000
+		hemisphere, code = "S", 16100+zone
+	}
+	return &Conversion{Name: fmt.Sprintf("UTM zone %d%s", zone, hemisphere), Code: code, Params: p}
+}
+
+func ellipsoidFromInfo(e proj.EllipsoidInfo) Ellipsoid {
+	return Ellipsoid{Name: e.Name, Code: e.Code, Ellipsoid: e.Ellipsoid}
+}
+
+func datumFromInfo(d proj.DatumInfo) Datum {
+	return Datum{Name: d.Name, Code: d.Code, Ellipsoid: ellipsoidFromInfo(d.Ellipsoid)}
+}
+
+// datumFromEPSG returns a registered geodetic datum
+func datumFromEPSG(code int) (Datum, bool) {
+	d, ok := proj.LookupDatum(code)
+	if !ok {
+		return Datum{}, false
+	}
+	return datumFromInfo(d), true
+}
+
+// geographicCRSFromEPSG returns a registered geographic CRS
+func geographicCRSFromEPSG(code int) (GeographicCRS, bool) {
+	info, ok := proj.LookupCRS(code)
+	if !ok || !info.Geographic {
+		return GeographicCRS{}, false
+	}
+	return GeographicCRS{Name: info.Name, Code: code, Datum: datumFromInfo(info.Datum), PrimeMeridian: greenwich, AngularUnit: unitDegree}, true
+}
+
+// projectedCRSFromEPSG returns a registered projected CRS
+func projectedCRSFromEPSG(code int) (*CRS, error) {
+	info, ok := proj.LookupCRS(code)
+	if !ok || info.Geographic {
+		return nil, fmt.Errorf("unsupported EPSG code %d", code)
+	}
+	crs := &CRS{
+		Name:       info.Name,
+		Code:       code,
+		Conversion: &Conversion{Name: info.ConversionName, Code: info.ConversionCode, Params: info.Params},
+		LinearUnit: linearUnits[info.UnitCode],
+		AxisOrder:  info.AxisOrder,
+		AreaOfUse:  &info.AreaOfUse,
+	}
+	crs.Geographic, _ = geographicCRSFromEPSG(info.BaseCode)
+	return crs, nil
+}
@@ -0,0 +1,77 @@
+package gocog
+
+import "math"
+
+// userDefined is the GeoKey value of a user-defined code, whose definition
+// follows in other GeoKeys
+const userDefined = 32767
+
+// EPSG units of measure referenced by GeoKeys
+var (
+	unitMetre        = Unit{Name: "metre", Code: 9001, Factor: 1}
+	unitFoot         = Unit{Name: "foot", Code: 9002, Factor: 0.3048}
+	unitUSSurveyFoot = Unit{Name: "US survey foot", Code: 9003, Factor: 1200.0 / 3937}
+	unitDegree       = Unit{Name: "degree", Code: 9102, Factor: math.Pi / 180}
+)
+
+var linearUnits = map[int]Unit{
+	9001: unitMetre,
+	9002: unitFoot,
+	9003: unitUSSurveyFoot,
+	9005: {Name: "Clarke's foot", Code: 9005, Factor: 0.3047972654},
+	9014: {Name: "fathom", Code: 9014, Factor: 1.8288},
+	9030: {Name: "nautical mile", Code: 9030, Factor: 1852},
+	9036: {Name: "kilometre", Code: 9036, Factor: 1000},
+	9093: {Name: "Statute mile", Code: 9093, Factor: 1609.344},
+}
+
+var angularUnits = map[int]Unit{
+	9101: {Name: "radian", Code: 9101, Factor: 1},
+	9102: unitDegree,
+	9103: {Name: "arc-minute", Code: 9103, Factor: math.Pi / 10800},
+	9104: {Name: "arc-second", Code: 9104, Factor: math.Pi / 648000},
+	9105: {Name: "grad", Code: 9105, Factor: math.Pi / 200},
+	9122: {Name: "degree", Code: 9122, Factor: math.Pi / 180},
+}
+
+var primeMeridians = map[int]PrimeMeridian{
+	8901: {Name: "Greenwich", Code: 8901, Longitude: 0},
+	8903: {Name: "Paris", Code: 8903, Longitude: 2.33722917},
+	8904: {Name: "Bogota", Code: 8904, Longitude: -74.08091666666667},
+	8905: {Name: "Madrid", Code: 8905, Longitude: -3.687938888888889},
+	8906: {Name: "Rome", Code: 8906, Longitude: 12.45233333333333},
+	8907: {Name: "Bern", Code: 8907, Longitude: 7.439583333333333},
+	8908: {Name: "Jakarta", Code: 8908, Longitude: 106.8077194444444},
+	8909: {Name: "Ferro", Code: 8909, Longitude: -17.66666666666667},
+	8910: {Name: "Brussels", Code: 8910, Longitude: 4.367975},
+	8913: {Name: "Oslo", Code: 8913, Longitude: 10.72291666666667},
+}
+
+// verticalCRSs lists vertical CRS codes of VerticalCSTypeGeoKey. Codes
+// 5001-5033 are GeoTIFF 1.0 ellipsoidal heights and geographic 3D codes are
+// GeoTIFF 1.1 ellipsoidal heights.
+var verticalCRSs = map[int]VerticalCRS{
+	3855: {Name: "EGM2008 height", Datum: VerticalDatum{Name: "EGM2008 geoid", Code: 1027}, Unit: unitMetre},
+	5773: {Name: "EGM96 height", Datum: VerticalDatum{Name: "EGM96 geoid", Code: 5171}, Unit: unitMetre},
+	5798: {Name: "EGM84 height", Datum: VerticalDatum{Name: "EGM84 geoid", Code: 5203}, Unit: unitMetre},
+	5714: {Name: "MSL height", Datum: VerticalDatum{Name: "Mean Sea Level", Code: 5100}, Unit: unitMetre},
+	5703: {Name: "NAVD88 height", Datum: navd88, Unit: unitMetre},
+	6360: {Name: "NAVD88 height (ftUS)", Datum: navd88, Unit: unitUSSurveyFoot},
+	8228: {Name: "NAVD88 height (ft)", Datum: navd88, Unit: unitFoot},
+	5702: {Name: "NGVD29 height (ftUS)", Datum: VerticalDatum{Name: "National Geodetic Vertical Datum 1929", Code: 5102}, Unit: unitUSSurveyFoot},
+	6647: {Name: "CGVD2013(CGG2013) height", Datum: VerticalDatum{Name: "Canadian Geodetic Vertical Datum of 2013 (CGG2013)", Code: 1127}, Unit: unitMetre},
+	5701: {Name: "ODN height", Datum: VerticalDatum{Name: "Ordnance Datum Newlyn", Code: 5101}, Unit: unitMetre},
+	5709: {Name: "NAP height", Datum: VerticalDatum{Name: "Normaal Amsterdams Peil", Code: 5109}, Unit: unitMetre},
+	5783: {Name: "DHHN92 height", Datum: VerticalDatum{Name: "Deutsches Haupthoehennetz 1992", Code: 5181}, Unit: unitMetre},
+	7837: {Name: "DHHN2016 height", Datum: VerticalDatum{Name: "Deutsches Haupthoehennetz 2016", Code: 1170}, Unit: unitMetre},
+	5711: {Name: "AHD height", Datum: VerticalDatum{Name: "Australian Height Datum", Code: 5111}, Unit: unitMetre},
+	7839: {Name: "NZVD2016 height", Datum: VerticalDatum{Name: "New Zealand Vertical Datum 2016", Code: 1169}, Unit: unitMetre},
+
+	5019: {Name: "GRS 1980 ellipsoid", Unit: unitMetre, Ellipsoidal: true},
+	5030: {Name: "WGS 84 ellipsoid", Unit: unitMetre, Ellipsoidal: true},
+	4979: {Name: "WGS 84", Datum: VerticalDatum{Name: "World Geodetic System 1984", Code: 6326}, Unit: unitMetre, Ellipsoidal: true},
+	4937: {Name: "ETRS89", Datum: VerticalDatum{Name: "European Terrestrial Reference System 1989", Code: 6258}, Unit: unitMetre, Ellipsoidal: true},
+	7843: {Name: "GDA2020", Datum: VerticalDatum{Name: "Geocentric Datum of Australia 2020", Code: 1168}, Unit: unitMetre, Ellipsoidal: true},
+}
+
+var navd88 = VerticalDatum{Name: "North American Vertical Datum 1988", Code: 5103}
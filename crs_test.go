@@ -0,0 +1,200 @@
+package gocog
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCoordinateReferenceSystemEPSG(t *testing.T) {
+	c := testRaster{Width: 4, Height: 4, EPSG: 32633}.cog(t)
+	crs, err := c.CoordinateReferenceSystem()
+	if err != nil {
+		t.Fatalf("CoordinateReferenceSystem failed: %v", err)
+	}
+	if crs.Name != "WGS 84 / UTM zone 33N" || crs.String() != "EPSG:32633" || crs.IsGeographic() {
+		t.Errorf("unexpected CRS %q (%s)", crs.Name, crs)
+	}
+	if crs.Geographic.Code != 4326 || crs.Geographic.Datum.Code != 6326 || crs.Geographic.Datum.Ellipsoid.Code != 7030 {
+		t.Errorf("expected a WGS 84 base, got %+v", crs.Geographic)
+	}
+	if crs.Conversion.Code != 16033 || crs.Conversion.Params.LonOrigin != 15 {
+		t.Errorf("expected the UTM zone 33N conversion, got %+v", crs.Conversion)
+	}
+	if unit, err := c.CRSUnit(); err != nil || unit.Code != 9001 || c.CRSName() != crs.Name {
+		t.Errorf("expected metres, got %+v (%v)", unit, err)
+	}
+	if area, ok := c.AreaOfUse(); !ok || area.West != 12 || area.East != 18 {
+		t.Errorf("expected an area of use between 12°E and 18°E, got %+v", area)
+	}
+
+	wkt := crs.WKT()
+	for _, want := range []string{
+		`PROJCRS["WGS 84 / UTM zone 33N",BASEGEOGCRS["WGS 84",DATUM["World Geodetic System 1984",ELLIPSOID["WGS 84",6378137,298.257223563,`,
+		`METHOD["Transverse Mercator",ID["EPSG",9807]]`,
+		`PARAMETER["Longitude of natural origin",15,ANGLEUNIT["degree",`,
+		`PARAMETER["False easting",500000,LENGTHUNIT["metre",1,ID["EPSG",9001]],ID["EPSG",8806]]`,
+		`USAGE[SCOPE["unknown"],AREA["Between 12°E and 18°E, northern hemisphere between equator and 84°N"],BBOX[0,12,84,18]],ID["EPSG",32633]]`,
+	} {
+		if !strings.Contains(wkt, want) {
+			t.Errorf("expected WKT to contain %s, got %s", want, wkt)
+		}
+	}
+
+	data, err := crs.PROJJSON()
+	if err != nil {
+		t.Fatalf("PROJJSON failed: %v", err)
+	}
+	var doc struct {
+		Type    string `json:"type"`
+		BaseCRS struct {
+			Datum struct {
+				Ellipsoid struct {
+					SemiMajorAxis float64 `json:"semi_major_axis"`
+				} `json:"ellipsoid"`
+			} `json:"datum"`
+		} `json:"base_crs"`
+		Conversion struct {
+			Parameters []struct {
+				Name  string  `json:"name"`
+				Value float64 `json:"value"`
+			} `json:"parameters"`
+		} `json:"conversion"`
+		ID struct {
+			Code int `json:"code"`
+		} `json:"id"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid PROJJSON: %v", err)
+	}
+	if doc.Type != "ProjectedCRS" || doc.ID.Code != 32633 || doc.BaseCRS.Datum.Ellipsoid.SemiMajorAxis != 6378137 {
+		t.Errorf("unexpected PROJJSON %s", data)
+	}
+	if len(doc.Conversion.Parameters) != 5 || doc.Conversion.Parameters[2].Value != 0.9996 {
+		t.Errorf("expected five Transverse Mercator parameters, got %+v", doc.Conversion.Parameters)
+	}
+
+	geographic, err := testRaster{Width: 4, Height: 4, EPSG: 4326}.cog(t).CoordinateReferenceSystem()
+	if err != nil {
+		t.Fatalf("CoordinateReferenceSystem failed: %v", err)
+	}
+	if wkt := geographic.WKT(); !geographic.IsGeographic() || !strings.HasPrefix(wkt, `GEOGCRS["WGS 84",`) ||
+		!strings.Contains(wkt, `CS[ellipsoidal,2],AXIS["geodetic latitude (Lat)",north,ORDER[1]`) {
+		t.Errorf("expected WGS 84 with latitude first, got %s", wkt)
+	}
+}
+
+func TestCoordinateReferenceSystemUserDefined(t *testing.T) {
+	// User-defined Transverse Mercator in feet on a user-defined GRS 1980 datum
+	tr := testRaster{
+		Width: 4, Height: 4, Origin: [2]float64{1000, 2000},
+		GeoKeys: []uint16{
+			GTModelTypeGeoKey, 0, 1, GTModelTypeProjected,
+			ProjectedCSTypeGeoKey, 0, 1, userDefined,
+			GeographicTypeGeoKey, 0, 1, userDefined,
+			GeogGeodeticDatumGeoKey, 0, 1, userDefined,
+			GeogEllipsoidGeoKey, 0, 1, 7019,
+			ProjCoordTransGeoKey, 0, 1, CTTransverseMercator,
+			ProjLinearUnitsGeoKey, 0, 1, uint16(unitFoot.Code),
+			ProjNatOriginLongGeoKey, TagGeoDoubleParams, 1, 0,
+			ProjNatOriginLatGeoKey, TagGeoDoubleParams, 1, 1,
+			ProjScaleAtNatOriginGeoKey, TagGeoDoubleParams, 1, 2,
+			ProjFalseEastingGeoKey, TagGeoDoubleParams, 1, 3,
+		},
+		ExtraTags: []testTag{doublesTag(TagGeoDoubleParams, 15, 0, 0.9996, 500000/0.3048)},
+	}
+	c := tr.cog(t)
+	if c.CRS() != "" {
+		t.Errorf("expected no EPSG code for a user-defined CRS, got %q", c.CRS())
+	}
+
+	crs, err := c.CoordinateReferenceSystem()
+	if err != nil {
+		t.Fatalf("CoordinateReferenceSystem failed: %v", err)
+	}
+	if crs.Code != 0 || crs.LinearUnit.Code != 9002 || crs.Geographic.Datum.Ellipsoid.Code != 7019 {
+		t.Errorf("unexpected CRS %+v", crs)
+	}
+	if fe := crs.Conversion.Params.FalseEasting; math.Abs(fe-500000) > 1e-6 {
+		t.Errorf("expected a false easting of 500000 m, got %v", fe)
+	}
+	if wkt := crs.WKT(); !strings.Contains(wkt, `LENGTHUNIT["foot",0.3048,ID["EPSG",9002]]`) || strings.Contains(wkt, `ID["EPSG",32767]`) {
+		t.Errorf("unexpected WKT %s", wkt)
+	}
+
+	// Equivalent to UTM zone 33N on ETRS89 in feet, not in metres
+	utm, _ := projectedCRSFromEPSG(25833)
+	if crs.Equivalent(utm) {
+		t.Error("expected a CRS in feet not to be equivalent to one in metres")
+	}
+	crs.LinearUnit = unitMetre
+	utm.Geographic.Datum.Code = 0
+	if !crs.Equivalent(utm) {
+		t.Error("expected user-defined UTM zone 33N to be equivalent to EPSG:25833")
+	}
+}
+
+func TestCoordinateReferenceSystemGrads(t *testing.T) {
+	// Lambert zone II style: the prime meridian and projection angles are in
+	// grads, as GeogAngularUnits says
+	tr := testRaster{
+		Width: 4, Height: 4, Origin: [2]float64{600000, 2200000},
+		GeoKeys: []uint16{
+			GTModelTypeGeoKey, 0, 1, GTModelTypeProjected,
+			ProjectedCSTypeGeoKey, 0, 1, userDefined,
+			GeographicTypeGeoKey, 0, 1, userDefined,
+			GeogGeodeticDatumGeoKey, 0, 1, userDefined,
+			GeogEllipsoidGeoKey, 0, 1, 7019,
+			GeogAngularUnitsGeoKey, 0, 1, 9105,
+			GeogPrimeMeridianGeoKey, 0, 1, userDefined,
+			GeogPrimeMeridianLongGeoKey, TagGeoDoubleParams, 1, 0,
+			ProjCoordTransGeoKey, 0, 1, CTLambertConfConic1SP,
+			ProjNatOriginLatGeoKey, TagGeoDoubleParams, 1, 1,
+			ProjNatOriginLongGeoKey, TagGeoDoubleParams, 1, 2,
+			ProjScaleAtNatOriginGeoKey, TagGeoDoubleParams, 1, 3,
+			ProjFalseEastingGeoKey, TagGeoDoubleParams, 1, 4,
+			ProjFalseNorthingGeoKey, TagGeoDoubleParams, 1, 5,
+		},
+		ExtraTags: []testTag{doublesTag(TagGeoDoubleParams, 2.5969213, 52, 0, 0.99987742, 600000, 2200000)},
+	}
+	crs, err := tr.cog(t).CoordinateReferenceSystem()
+	if err != nil {
+		t.Fatalf("CoordinateReferenceSystem failed: %v", err)
+	}
+	if pm := crs.Geographic.PrimeMeridian.Longitude; math.Abs(pm-2.33722917) > 1e-8 {
+		t.Errorf("expected the Paris meridian at 2.33722917°, got %v", pm)
+	}
+	if lat := crs.Conversion.Params.LatOrigin; math.Abs(lat-46.8) > 1e-12 {
+		t.Errorf("expected a latitude of origin of 46.8°, got %v", lat)
+	}
+
+	// The natural origin, on the Paris meridian, projects to the false origin
+	p, err := crs.Projection()
+	if err != nil {
+		t.Fatalf("Projection failed: %v", err)
+	}
+	if x, y := p.Forward(2.33722917, 46.8); math.Abs(x-600000) > 0.01 || math.Abs(y-2200000) > 0.01 {
+		t.Errorf("expected (600000, 2200000), got (%v, %v)", x, y)
+	}
+}
+
+func TestCRSEquivalent(t *testing.T) {
+	webMercator, _ := projectedCRSFromEPSG(3857)
+	google, _ := projectedCRSFromEPSG(900913)
+	if !webMercator.Equivalent(google) {
+		t.Error("expected EPSG:3857 and EPSG:900913 to be equivalent")
+	}
+
+	north, _ := projectedCRSFromEPSG(32633)
+	south, _ := projectedCRSFromEPSG(32733)
+	if north.Equivalent(south) || north.Equivalent(webMercator) {
+		t.Error("expected different projections not to be equivalent")
+	}
+
+	wgs84, _ := crsFromGeoKeys(map[uint16]interface{}{GTModelTypeGeoKey: uint16(GTModelTypeGeographic), GeographicTypeGeoKey: uint16(4326)})
+	nad83, _ := crsFromGeoKeys(map[uint16]interface{}{GTModelTypeGeoKey: uint16(GTModelTypeGeographic), GeographicTypeGeoKey: uint16(4269)})
+	if wgs84.Equivalent(nad83) || wgs84.Equivalent(north) || !wgs84.Equivalent(wgs84) {
+		t.Error("expected geographic CRSs to be compared by datum")
+	}
+}
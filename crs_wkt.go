@@ -0,0 +1,208 @@
+package gocog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tingold/gocog/proj"
+)
+
+// WKT returns the CRS as single-line OGC WKT2 (ISO 19162:2019)
+func (c *CRS) WKT() string {
+	var b strings.Builder
+	g := c.Geographic
+	if c.IsGeographic() {
+		fmt.Fprintf(&b, "GEOGCRS[%s,", wktString(c.Name))
+	} else {
+		fmt.Fprintf(&b, "PROJCRS[%s,BASEGEOGCRS[%s,", wktString(c.Name), wktString(g.Name))
+	}
+	fmt.Fprintf(&b, "DATUM[%s,ELLIPSOID[%s,%s,%s,LENGTHUNIT[\"metre\",1]%s]%s],",
+		wktString(g.Datum.Name), wktString(g.Datum.Ellipsoid.Name),
+		wktNumber(g.Datum.Ellipsoid.SemiMajor), wktNumber(g.Datum.Ellipsoid.InvFlattening),
+		wktID(g.Datum.Ellipsoid.Code), wktID(g.Datum.Code))
+	fmt.Fprintf(&b, "PRIMEM[%s,%s,ANGLEUNIT[\"degree\",%s]%s]",
+		wktString(g.PrimeMeridian.Name), wktNumber(g.PrimeMeridian.Longitude), wktNumber(unitDegree.Factor), wktID(g.PrimeMeridian.Code))
+
+	if c.IsGeographic() {
+		unit := wktUnit("ANGLEUNIT", g.AngularUnit)
+		b.WriteString(",CS[ellipsoidal,2]")
+		wktAxes(&b, c.AxisOrder, `"geodetic longitude (Lon)"`, `"geodetic latitude (Lat)"`, unit)
+		fmt.Fprintf(&b, "%s%s]", wktUsage(c.AreaOfUse), wktID(c.Code))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%s],", wktID(g.Code))
+	name, code := c.Conversion.method()
+	fmt.Fprintf(&b, "CONVERSION[%s,METHOD[%s%s]", wktString(c.Conversion.Name), wktString(name), wktID(code))
+	for _, p := range c.Conversion.parameters() {
+		var unit string
+		switch p.kind {
+		case parameterAngle:
+			unit = wktUnit("ANGLEUNIT", unitDegree)
+		case parameterScale:
+			unit = "SCALEUNIT[\"unity\",1]"
+		case parameterLength:
+			unit = wktUnit("LENGTHUNIT", unitMetre)
+		}
+		fmt.Fprintf(&b, ",PARAMETER[%s,%s,%s%s]", wktString(p.name), wktNumber(p.value), unit, wktID(p.code))
+	}
+	fmt.Fprintf(&b, "%s],CS[Cartesian,2]", wktID(c.Conversion.Code))
+	wktAxes(&b, c.AxisOrder, `"easting (E)"`, `"northing (N)"`, wktUnit("LENGTHUNIT", c.LinearUnit))
+	fmt.Fprintf(&b, "%s%s]", wktUsage(c.AreaOfUse), wktID(c.Code))
+	return b.String()
+}
+
+// wktAxes writes the AXIS clauses of the east and north axes in axis order
+func wktAxes(b *strings.Builder, order proj.AxisOrder, east, north, unit string) {
+	axes := []string{east + ",east", north + ",north"}
+	if order == proj.AxisNorthEast {
+		axes[0], axes[1] = axes[1], axes[0]
+	}
+	for i, axis := range axes {
+		fmt.Fprintf(b, ",AXIS[%s,ORDER[%d],%s]", axis, i+1, unit)
+	}
+}
+
+// wktUsage returns the USAGE clause of an area of use, or nothing if it is nil
+func wktUsage(area *proj.AreaOfUse) string {
+	if area == nil {
+		return ""
+	}
+	return fmt.Sprintf(",USAGE[SCOPE[\"unknown\"],AREA[%s],BBOX[%s,%s,%s,%s]]", wktString(area.Name),
+		wktNumber(area.South), wktNumber(area.West), wktNumber(area.North), wktNumber(area.East))
+}
+
+// wktString quotes s, doubling embedded quotes
+func wktString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func wktNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// wktID returns the ID clause of an EPSG code, or nothing if code is 0
+func wktID(code int) string {
+	if code == 0 {
+		return ""
+	}
+	return fmt.Sprintf(",ID[\"EPSG\",%d]", code)
+}
+
+func wktUnit(keyword string, u Unit) string {
+	return fmt.Sprintf("%s[%s,%s%s]", keyword, wktString(u.Name), wktNumber(u.Factor), wktID(u.Code))
+}
+
+// PROJJSON returns the CRS encoded as PROJJSON (schema v0.7)
+func (c *CRS) PROJJSON() ([]byte, error) {
+	type object = map[string]interface{}
+	id := func(o object, code int) object {
+		if code != 0 {
+			o["id"] = object{"authority": "EPSG", "code": code}
+		}
+		return o
+	}
+	unit := func(kind string, u Unit) interface{} {
+		switch {
+		case u.Code == unitMetre.Code:
+			return "metre"
+		case u.Code == unitDegree.Code:
+			return "degree"
+		}
+		return id(object{"type": kind, "name": u.Name, "conversion_factor": u.Factor}, u.Code)
+	}
+
+	g := c.Geographic
+	ellipsoid := g.Datum.Ellipsoid
+	base := object{
+		"name": g.Name,
+		"datum": id(object{
+			"type": "GeodeticReferenceFrame",
+			"name": g.Datum.Name,
+			"ellipsoid": id(object{
+				"name":               ellipsoid.Name,
+				"semi_major_axis":    ellipsoid.SemiMajor,
+				"inverse_flattening": ellipsoid.InvFlattening,
+			}, ellipsoid.Code),
+			"prime_meridian": id(object{
+				"name":      g.PrimeMeridian.Name,
+				"longitude": g.PrimeMeridian.Longitude,
+			}, g.PrimeMeridian.Code),
+		}, g.Datum.Code),
+	}
+
+	if c.IsGeographic() {
+		angular := unit("AngularUnit", g.AngularUnit)
+		base["$schema"] = "https://proj.org/schemas/v0.7/projjson.schema.json"
+		base["type"] = "GeographicCRS"
+		base["coordinate_system"] = object{
+			"subtype": "ellipsoidal",
+			"axis": projjsonAxes(c.AxisOrder,
+				object{"name": "Geodetic longitude", "abbreviation": "Lon", "direction": "east", "unit": angular},
+				object{"name": "Geodetic latitude", "abbreviation": "Lat", "direction": "north", "unit": angular}),
+		}
+		projjsonUsage(base, c.AreaOfUse)
+		return json.Marshal(id(base, c.Code))
+	}
+
+	base["type"] = "GeographicCRS"
+	id(base, g.Code)
+	name, code := c.Conversion.method()
+	var parameters []object
+	for _, p := range c.Conversion.parameters() {
+		var u interface{}
+		switch p.kind {
+		case parameterAngle:
+			u = "degree"
+		case parameterScale:
+			u = "unity"
+		case parameterLength:
+			u = "metre"
+		}
+		parameters = append(parameters, id(object{"name": p.name, "value": p.value, "unit": u}, p.code))
+	}
+	linear := unit("LinearUnit", c.LinearUnit)
+	crs := object{
+		"$schema":  "https://proj.org/schemas/v0.7/projjson.schema.json",
+		"type":     "ProjectedCRS",
+		"name":     c.Name,
+		"base_crs": base,
+		"conversion": id(object{
+			"name":       c.Conversion.Name,
+			"method":     id(object{"name": name}, code),
+			"parameters": parameters,
+		}, c.Conversion.Code),
+		"coordinate_system": object{
+			"subtype": "Cartesian",
+			"axis": projjsonAxes(c.AxisOrder,
+				object{"name": "Easting", "abbreviation": "E", "direction": "east", "unit": linear},
+				object{"name": "Northing", "abbreviation": "N", "direction": "north", "unit": linear}),
+		},
+	}
+	projjsonUsage(crs, c.AreaOfUse)
+	return json.Marshal(id(crs, c.Code))
+}
+
+// projjsonAxes returns the east and north axes in axis order
+func projjsonAxes(order proj.AxisOrder, east, north map[string]interface{}) []map[string]interface{} {
+	if order == proj.AxisNorthEast {
+		return []map[string]interface{}{north, east}
+	}
+	return []map[string]interface{}{east, north}
+}
+
+// projjsonUsage adds the area of use to a PROJJSON object
+func projjsonUsage(o map[string]interface{}, area *proj.AreaOfUse) {
+	if area == nil {
+		return
+	}
+	o["area"] = area.Name
+	o["bbox"] = map[string]float64{
+		"south_latitude": area.South,
+		"west_longitude": area.West,
+		"north_latitude": area.North,
+		"east_longitude": area.East,
+	}
+}
@@ -0,0 +1,615 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/paulmach/orb"
+)
+
+// Expression is a parsed band math expression such as "(b4-b3)/(b4+b3)".
+//
+// Expressions support numbers, variables, the arithmetic operators
+// + - * / % and ^ (power), comparisons (== != < <= > >=), logical operators
+// (&& || !), the constants pi and nan, and the functions where(cond, a, b),
+// min, max, clamp(x, lo, hi), abs, sqrt, exp, log, log10, pow, sin, cos, tan,
+// asin, acos, atan, atan2, floor, ceil and round. Comparisons and logical
+// operators yield 1 or 0; any non-zero value is true.
+//
+// Evaluation is done in float64. A pixel is NoData in the result if any
+// variable referenced by the expression is NoData at that pixel, or if the
+// expression evaluates to NaN.
+type Expression struct {
+	src  string
+	root exprNode
+	vars []string
+}
+
+// exprNode evaluates part of an expression given the current variable values
+type exprNode func(vals []float64) float64
+
+// ParseExpression parses a band math expression.
+func ParseExpression(src string) (*Expression, error) {
+	tokens, err := tokenizeExpression(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, varIndex: make(map[string]int)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+
+	return &Expression{src: src, root: root, vars: p.vars}, nil
+}
+
+// String returns the source text of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Variables returns the names of the variables referenced by the expression,
+// in order of first appearance.
+func (e *Expression) Variables() []string {
+	return append([]string(nil), e.vars...)
+}
+
+// BandRef binds an expression variable to a band (0-based) of a raster.
+type BandRef struct {
+	Raster *RasterData
+	Band   int
+}
+
+// Evaluate evaluates the expression for every pixel. All variables must be
+// bound and all bound rasters must have the same dimensions. The result is
+// a single-band DTDouble raster with NaN as its NoData value and the bounds
+// of the first bound raster.
+func (e *Expression) Evaluate(vars map[string]BandRef) (*RasterData, error) {
+	refs := make([]BandRef, len(e.vars))
+	for i, name := range e.vars {
+		ref, ok := vars[name]
+		if !ok || ref.Raster == nil {
+			return nil, fmt.Errorf("unbound variable %q", name)
+		}
+		if ref.Band < 0 || ref.Band >= ref.Raster.Bands {
+			return nil, fmt.Errorf("variable %q: band %d out of range (raster has %d bands)", name, ref.Band, ref.Raster.Bands)
+		}
+		if i > 0 && (ref.Raster.Width != refs[0].Raster.Width || ref.Raster.Height != refs[0].Raster.Height) {
+			return nil, fmt.Errorf("variable %q: raster size %dx%d does not match %dx%d",
+				name, ref.Raster.Width, ref.Raster.Height, refs[0].Raster.Width, refs[0].Raster.Height)
+		}
+		refs[i] = ref
+	}
+
+	// Constant expressions have no raster to take their size from
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("expression references no bands")
+	}
+
+	return e.evaluate(refs, refs[0].Raster.Width, refs[0].Raster.Height, refs[0].Raster.Bounds), nil
+}
+
+// evaluate computes the expression over a width x height grid of bound bands
+func (e *Expression) evaluate(refs []BandRef, width, height int, bounds orb.Bound) *RasterData {
+	result := &RasterData{
+		Data:      make([]uint64, width*height),
+		Width:     width,
+		Height:    height,
+		Bands:     1,
+		Bounds:    bounds,
+		DataType:  DTDouble,
+		NoData:    math.NaN(),
+		HasNoData: true,
+	}
+
+	nan := math.Float64bits(math.NaN())
+	vals := make([]float64, len(refs))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			valid := true
+			for i, ref := range refs {
+				v := SampleToFloat64(ref.Raster.AtUnchecked(ref.Band, x, y), ref.Raster.DataType)
+				if ref.Raster.masked(y*width+x) || ref.Raster.isNoDataValue(v) {
+					valid = false
+					break
+				}
+				vals[i] = v
+			}
+			if !valid {
+				result.Data[y*width+x] = nan
+				continue
+			}
+			result.Data[y*width+x] = math.Float64bits(e.root(vals))
+		}
+	}
+
+	return result
+}
+
+// EvaluateRaster evaluates the expression over the bands of a single raster,
+// binding b1, b2, ... bN to its bands (1-based, as in GDAL and rasterio).
+func (e *Expression) EvaluateRaster(data *RasterData) (*RasterData, error) {
+	refs := make([]BandRef, len(e.vars))
+	for i, name := range e.vars {
+		band, err := strconv.Atoi(strings.TrimPrefix(name, "b"))
+		if !strings.HasPrefix(name, "b") || err != nil || band < 1 || band > data.Bands {
+			return nil, fmt.Errorf("unbound variable %q (raster has bands b1-b%d)", name, data.Bands)
+		}
+		refs[i] = BandRef{Raster: data, Band: band - 1}
+	}
+	return e.evaluate(refs, data.Width, data.Height, data.Bounds), nil
+}
+
+// COGBand binds an expression variable to a band (0-based) of a COG.
+type COGBand struct {
+	COG  *COG
+	Band int
+}
+
+// EvaluateWindow reads the same pixel window from each referenced COG and
+// evaluates the expression over it. This allows combining bands stored in
+// separate files that share a grid, e.g. Sentinel-2's B04.tif and B08.tif:
+//
+//	expr, _ := gocog.ParseExpression("(nir - red) / (nir + red)")
+//	ndvi, err := expr.EvaluateWindow(map[string]gocog.COGBand{
+//		"red": {COG: b04}, "nir": {COG: b08},
+//	}, rect)
+//
+// All COGs must have the same dimensions and bounds. The window is in pixels
+// of the full-resolution images and is always read at full resolution, so
+// COGs with different overviews line up.
+func (e *Expression) EvaluateWindow(sources map[string]COGBand, rect Rectangle) (*RasterData, error) {
+	if rect.X < 0 || rect.Y < 0 || rect.Width <= 0 || rect.Height <= 0 {
+		return nil, fmt.Errorf("invalid window %+v", rect)
+	}
+	// Read each COG only once, even if several of its bands are referenced
+	windows := make(map[*COG]*RasterData)
+	var reference *COG
+	vars := make(map[string]BandRef, len(e.vars))
+
+	for _, name := range e.vars {
+		src, ok := sources[name]
+		if !ok || src.COG == nil {
+			return nil, fmt.Errorf("unbound variable %q", name)
+		}
+
+		if reference == nil {
+			reference = src.COG
+			if rect.X+rect.Width > reference.Width() || rect.Y+rect.Height > reference.Height() {
+				return nil, fmt.Errorf("window %+v extends beyond the image", rect)
+			}
+		} else if !sameGrid(reference, src.COG) {
+			return nil, fmt.Errorf("variable %q: COG grid does not match the other sources", name)
+		}
+
+		data, ok := windows[src.COG]
+		if !ok {
+			var err error
+			data, err = src.COG.readRaster(0, rect.X, rect.Y, rect.Width, rect.Height)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read window for %q: %w", name, err)
+			}
+			windows[src.COG] = data
+		}
+		vars[name] = BandRef{Raster: data, Band: src.Band}
+	}
+
+	return e.Evaluate(vars)
+}
+
+// sameGrid reports whether two COGs share dimensions and georeferencing
+func sameGrid(a, b *COG) bool {
+	if a.Width() != b.Width() || a.Height() != b.Height() {
+		return false
+	}
+	ba, bb := a.Bounds(), b.Bounds()
+	// Allow for rounding differences well below a pixel
+	tolX := (ba.Max[0] - ba.Min[0]) / float64(a.Width()) * 1e-3
+	tolY := (ba.Max[1] - ba.Min[1]) / float64(a.Height()) * 1e-3
+	return math.Abs(ba.Min[0]-bb.Min[0]) <= tolX && math.Abs(ba.Max[0]-bb.Max[0]) <= tolX &&
+		math.Abs(ba.Min[1]-bb.Min[1]) <= tolY && math.Abs(ba.Max[1]-bb.Max[1]) <= tolY
+}
+
+// Expression tokens
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// exprOperators lists operators, longest first so that "<=" wins over "<"
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "^", "<", ">", "!"}
+
+// tokenizeExpression splits an expression into tokens
+func tokenizeExpression(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	i := 0
+	for i < len(src) {
+		ch := rune(src[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '(':
+			tokens = append(tokens, exprToken{kind: tokLParen, text: "(", pos: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, exprToken{kind: tokRParen, text: ")", pos: i})
+			i++
+		case ch == ',':
+			tokens = append(tokens, exprToken{kind: tokComma, text: ",", pos: i})
+			i++
+		case unicode.IsDigit(ch) || ch == '.':
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			// Exponent, e.g. 1e-3
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				j := i + 1
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				if j < len(src) && unicode.IsDigit(rune(src[j])) {
+					i = j
+					for i < len(src) && unicode.IsDigit(rune(src[i])) {
+						i++
+					}
+				}
+			}
+			num, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", src[start:i], start)
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: src[start:i], num: num, pos: start})
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: src[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, exprToken{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", ch, i)
+			}
+		}
+	}
+	return append(tokens, exprToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+// exprParser is a recursive descent parser compiling tokens into closures
+type exprParser struct {
+	tokens   []exprToken
+	pos      int
+	vars     []string
+	varIndex map[string]int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// acceptOp consumes the next token if it is one of the given operators
+func (p *exprParser) acceptOp(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// parseBinary parses a left-associative chain of operators at one precedence level
+func (p *exprParser) parseBinary(operand func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode(op, left, right)
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseAdditive, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.acceptOp("-", "+", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "-":
+			return func(v []float64) float64 { return -operand(v) }, nil
+		case "!":
+			return func(v []float64) float64 { return boolToFloat(operand(v) == 0) }, nil
+		default:
+			return operand, nil
+		}
+	}
+	return p.parsePower()
+}
+
+// parsePower parses right-associative exponentiation, binding tighter than unary minus
+func (p *exprParser) parsePower() (exprNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.acceptOp("^"); ok {
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(v []float64) float64 { return math.Pow(base(v), exponent(v)) }, nil
+	}
+	return base, nil
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		num := tok.num
+		return func([]float64) float64 { return num }, nil
+
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing ')' for '(' at position %d", tok.pos)
+		}
+		return node, nil
+
+	case tokIdent:
+		if p.peek().kind == tokLParen {
+			p.next()
+			return p.parseCall(tok)
+		}
+		switch tok.text {
+		case "pi":
+			return func([]float64) float64 { return math.Pi }, nil
+		case "nan":
+			return func([]float64) float64 { return math.NaN() }, nil
+		}
+		idx, ok := p.varIndex[tok.text]
+		if !ok {
+			idx = len(p.vars)
+			p.varIndex[tok.text] = idx
+			p.vars = append(p.vars, tok.text)
+		}
+		return func(v []float64) float64 { return v[idx] }, nil
+
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// parseCall parses the arguments of a function call after its opening parenthesis
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	var args []exprNode
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.next().kind != tokRParen {
+		return nil, fmt.Errorf("missing ')' in call to %s at position %d", name.text, name.pos)
+	}
+
+	node, err := functionNode(name.text, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w at position %d", err, name.pos)
+	}
+	return node, nil
+}
+
+// exprFunctions1 are single-argument math functions
+var exprFunctions1 = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+	"exp":   math.Exp,
+	"log":   math.Log,
+	"log10": math.Log10,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+}
+
+// functionNode builds the node for a function call
+func functionNode(name string, args []exprNode) (exprNode, error) {
+	if fn, ok := exprFunctions1[name]; ok {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s expects 1 argument, got %d", name, len(args))
+		}
+		arg := args[0]
+		return func(v []float64) float64 { return fn(arg(v)) }, nil
+	}
+
+	switch name {
+	case "pow", "atan2":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s expects 2 arguments, got %d", name, len(args))
+		}
+		a, b := args[0], args[1]
+		if name == "pow" {
+			return func(v []float64) float64 { return math.Pow(a(v), b(v)) }, nil
+		}
+		return func(v []float64) float64 { return math.Atan2(a(v), b(v)) }, nil
+
+	case "where":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("where expects 3 arguments, got %d", len(args))
+		}
+		cond, a, b := args[0], args[1], args[2]
+		return func(v []float64) float64 {
+			if cond(v) != 0 {
+				return a(v)
+			}
+			return b(v)
+		}, nil
+
+	case "clamp":
+		if len(args) != 3 {
+			return nil, fmt.Errorf("clamp expects 3 arguments, got %d", len(args))
+		}
+		x, lo, hi := args[0], args[1], args[2]
+		return func(v []float64) float64 { return math.Max(lo(v), math.Min(hi(v), x(v))) }, nil
+
+	case "min", "max":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s expects at least 1 argument", name)
+		}
+		pick := math.Min
+		if name == "max" {
+			pick = math.Max
+		}
+		return func(v []float64) float64 {
+			result := args[0](v)
+			for _, arg := range args[1:] {
+				result = pick(result, arg(v))
+			}
+			return result
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown function %q", name)
+}
+
+// binaryNode builds the node for a binary operator
+func binaryNode(op string, a, b exprNode) exprNode {
+	switch op {
+	case "+":
+		return func(v []float64) float64 { return a(v) + b(v) }
+	case "-":
+		return func(v []float64) float64 { return a(v) - b(v) }
+	case "*":
+		return func(v []float64) float64 { return a(v) * b(v) }
+	case "/":
+		return func(v []float64) float64 { return a(v) / b(v) }
+	case "%":
+		return func(v []float64) float64 { return math.Mod(a(v), b(v)) }
+	case "==":
+		return func(v []float64) float64 { return boolToFloat(a(v) == b(v)) }
+	case "!=":
+		return func(v []float64) float64 { return boolToFloat(a(v) != b(v)) }
+	case "<":
+		return func(v []float64) float64 { return boolToFloat(a(v) < b(v)) }
+	case "<=":
+		return func(v []float64) float64 { return boolToFloat(a(v) <= b(v)) }
+	case ">":
+		return func(v []float64) float64 { return boolToFloat(a(v) > b(v)) }
+	case ">=":
+		return func(v []float64) float64 { return boolToFloat(a(v) >= b(v)) }
+	case "&&":
+		return func(v []float64) float64 { return boolToFloat(a(v) != 0 && b(v) != 0) }
+	default: // "||"
+		return func(v []float64) float64 { return boolToFloat(a(v) != 0 || b(v) != 0) }
+	}
+}
+
+// boolToFloat converts a boolean to 1 or 0
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ExpressionFunctions returns the names of the functions available in expressions
+func ExpressionFunctions() []string {
+	names := []string{"pow", "atan2", "where", "clamp", "min", "max"}
+	for name := range exprFunctions1 {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,157 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+)
+
+// constRaster returns a 1x1 raster with the given band values
+func constRaster(dataType DataType, values ...float64) *RasterData {
+	data := &RasterData{Data: make([]uint64, len(values)), Width: 1, Height: 1, Bands: len(values), DataType: dataType}
+	for i, v := range values {
+		data.Data[i] = Float64ToSample(v, dataType)
+	}
+	return data
+}
+
+func TestExpressionEvaluate(t *testing.T) {
+	data := constRaster(DTSShortS, 10, -4, 3, 5)
+
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"(b4-b3)/(b4+b3)", 0.25},
+		{"b1 + b2 * b3", -2},
+		{"-b3^2", -9},
+		{"2^3^2", 512},
+		{"b1 % 3", 1},
+		{"b1 > 5 && b2 < 0", 1},
+		{"!(b1 == 10) || b3 != 3", 0},
+		{"where(b2 < 0, 0, b2)", 0},
+		{"min(b1, b2, b3)", -4},
+		{"max(b1, b2, 100)", 100},
+		{"clamp(b1, 0, 5)", 5},
+		{"abs(b2) + sqrt(b1 - 1) + pow(2, b3)", 15},
+		{"round(atan2(1, 1) * 4 / pi)", 1},
+		{"1.5e1 - log10(100)", 13},
+	}
+
+	for _, tt := range tests {
+		expr, err := ParseExpression(tt.expr)
+		if err != nil {
+			t.Errorf("ParseExpression(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		result, err := expr.EvaluateRaster(data)
+		if err != nil {
+			t.Errorf("EvaluateRaster(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := result.Float(0, 0, 0); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	for _, src := range []string{"", "b1 +", "(b1", "b1 b2", "foo(b1)", "sqrt(b1, b2)", "b1 # 2", "where(b1, 2)"} {
+		if _, err := ParseExpression(src); err == nil {
+			t.Errorf("expected parse error for %q", src)
+		}
+	}
+
+	expr, _ := ParseExpression("b1 + b9")
+	if _, err := expr.EvaluateRaster(constRaster(DTByte, 1, 2)); err == nil {
+		t.Error("expected error for unbound variable")
+	}
+
+	expr, _ = ParseExpression("a + b")
+	if vars := expr.Variables(); len(vars) != 2 || vars[0] != "a" || vars[1] != "b" {
+		t.Errorf("unexpected variables %v", vars)
+	}
+	big := &RasterData{Data: make([]uint64, 4), Width: 2, Height: 2, Bands: 1, DataType: DTByte}
+	if _, err := expr.Evaluate(map[string]BandRef{"a": {Raster: big}, "b": {Raster: constRaster(DTByte, 1)}}); err == nil {
+		t.Error("expected error for mismatched raster sizes")
+	}
+}
+
+func TestExpressionNoData(t *testing.T) {
+	data := &RasterData{
+		Data:     []uint64{Float64ToSample(-9999, DTFloat), Float64ToSample(2, DTFloat), Float64ToSample(0, DTFloat)},
+		Width:    3,
+		Height:   1,
+		Bands:    1,
+		DataType: DTFloat,
+		NoData:   -9999, HasNoData: true,
+	}
+
+	expr, _ := ParseExpression("1 / b1")
+	result, err := expr.EvaluateRaster(data)
+	if err != nil {
+		t.Fatalf("EvaluateRaster failed: %v", err)
+	}
+	if result.DataType != DTDouble {
+		t.Errorf("expected DTDouble result, got %d", result.DataType)
+	}
+	if !result.IsNoData(0, 0, 0) {
+		t.Error("expected NoData input to propagate")
+	}
+	if result.IsNoData(0, 1, 0) || result.Float(0, 1, 0) != 0.5 {
+		t.Errorf("expected 0.5, got %v", result.Float(0, 1, 0))
+	}
+	if !math.IsInf(result.Float(0, 2, 0), 1) {
+		t.Errorf("expected +Inf for division by zero, got %v", result.Float(0, 2, 0))
+	}
+}
+
+func TestExpressionEvaluateWindow(t *testing.T) {
+	grid := testRaster{Width: 8, Height: 8, TileSize: 4, DataType: DTSShort, EPSG: 32618, Origin: [2]float64{500000, 4000000}, PixelSize: [2]float64{10, 10}}
+
+	red := grid
+	red.Value = func(band, x, y int) float64 { return 100 }
+	nir := grid
+	nir.Value = func(band, x, y int) float64 { return float64(100 + 100*x) }
+
+	expr, err := ParseExpression("(nir - red) / (nir + red)")
+	if err != nil {
+		t.Fatalf("ParseExpression failed: %v", err)
+	}
+	ndvi, err := expr.EvaluateWindow(map[string]COGBand{
+		"red": {COG: red.cog(t)},
+		"nir": {COG: nir.cog(t)},
+	}, Rectangle{X: 0, Y: 0, Width: 8, Height: 8})
+	if err != nil {
+		t.Fatalf("EvaluateWindow failed: %v", err)
+	}
+	if got := ndvi.Float(0, 1, 3); got != 1.0/3.0 {
+		t.Errorf("expected NDVI 1/3, got %v", got)
+	}
+	if got := ndvi.Float(0, 0, 0); got != 0 {
+		t.Errorf("expected NDVI 0, got %v", got)
+	}
+
+	// Sources are read at full resolution whatever their overviews
+	pyramid := nir
+	pyramid.Overviews = 2
+	ndvi, err = expr.EvaluateWindow(map[string]COGBand{
+		"red": {COG: red.cog(t)},
+		"nir": {COG: pyramid.cog(t)},
+	}, Rectangle{X: 0, Y: 0, Width: 8, Height: 8})
+	if err != nil {
+		t.Fatalf("EvaluateWindow failed: %v", err)
+	}
+	if ndvi.Width != 8 || ndvi.Float(0, 1, 3) != 1.0/3.0 {
+		t.Errorf("expected a full-resolution NDVI of 1/3, got %d pixels wide with %v", ndvi.Width, ndvi.Float(0, 1, 3))
+	}
+
+	shifted := grid
+	shifted.Origin = [2]float64{600000, 4000000}
+	_, err = expr.EvaluateWindow(map[string]COGBand{
+		"red": {COG: red.cog(t)},
+		"nir": {COG: shifted.cog(t)},
+	}, Rectangle{X: 0, Y: 0, Width: 8, Height: 8})
+	if err == nil {
+		t.Error("expected error for COGs on different grids")
+	}
+}
@@ -0,0 +1,500 @@
+package gocog
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+	"github.com/paulmach/orb/simplify"
+)
+
+// FootprintOptions configures DataFootprint
+type FootprintOptions struct {
+	// Overview is the level traced (0 = main image), not counting internal
+	// mask IFDs. Coarser overviews are faster and give simpler outlines.
+	Overview int
+
+	// Simplify is the Douglas-Peucker tolerance in pixels of the traced
+	// level; 0 keeps every corner of the pixel outline
+	Simplify float64
+
+	// MinArea drops polygons and fills holes smaller than this many pixels
+	// of the traced level, e.g. isolated specks of valid or NoData pixels
+	MinArea float64
+
+	// Densify inserts vertices so that no edge is longer than this many
+	// pixels before the outline is transformed, so it follows curved edges
+	// when reprojected; 0 disables densification
+	Densify float64
+
+	// LonLat returns WGS 84 longitude/latitude instead of the image's CRS
+	LonLat bool
+}
+
+// DataFootprint returns the outline of the image's valid data as polygons,
+// unlike Bounds and GetImagePolygon, which include NoData areas such as the
+// wedges at the edges of satellite scenes. A pixel is valid if its internal
+// mask (GDAL's per-dataset mask IFD), if any, is not 0, its alpha band
+// (ExtraSamples), if any, is not 0 and any other band is not NoData (or
+// NaN). Diagonally touching pixels belong to separate polygons, and
+// enclosed invalid areas are holes. Outer rings are counter-clockwise and
+// holes clockwise, as in GeoJSON. The traced level is read a row of blocks
+// at a time.
+func (c *COG) DataFootprint(opts FootprintOptions) (orb.MultiPolygon, error) {
+	images := c.imageIFDs()
+	if opts.Overview < 0 || opts.Overview >= len(images) {
+		return nil, fmt.Errorf("overview %d not found", opts.Overview)
+	}
+	ifdIndex := images[opts.Overview]
+	gtr := c.geoTIFFs[ifdIndex]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+	transform := gtr.pixelToGeo
+	if opts.LonLat {
+		projection, err := c.Projection()
+		if err != nil {
+			return nil, fmt.Errorf("unsupported CRS: %w", err)
+		}
+		transform = func(x, y float64) (float64, float64) {
+			return projection.Inverse(gtr.pixelToGeo(x, y))
+		}
+	}
+
+	valid, err := c.validPixels(ifdIndex)
+	if err != nil {
+		return nil, err
+	}
+	if mask := c.maskIFD(ifdIndex); mask >= 0 {
+		if err := c.applyMask(valid, mask); err != nil {
+			return nil, fmt.Errorf("failed to read mask IFD %d: %w", mask, err)
+		}
+	}
+	polygons := valid.trace()
+
+	var footprint orb.MultiPolygon
+	for _, polygon := range polygons {
+		if opts.MinArea > 0 {
+			polygon = dropSmallRings(polygon, opts.MinArea)
+			if len(polygon) == 0 {
+				continue
+			}
+		}
+		if opts.Simplify > 0 {
+			polygon = simplifyRings(polygon, opts.Simplify)
+			if len(polygon) == 0 {
+				continue
+			}
+		}
+		for i, ring := range polygon {
+			if opts.Densify > 0 {
+				ring = densifyRing(ring, opts.Densify)
+			}
+			out := make(orb.Ring, len(ring))
+			for j, p := range ring {
+				x, y := transform(p[0], p[1])
+				out[j] = orb.Point{x, y}
+			}
+			// Enforce the orientation, which transforms may flip
+			if (i == 0) != (out.Orientation() == orb.CCW) {
+				out.Reverse()
+			}
+			polygon[i] = out
+		}
+		footprint = append(footprint, polygon)
+	}
+	return footprint, nil
+}
+
+// validMask records which pixels of an IFD hold valid data
+type validMask struct {
+	width, height int
+	valid         []bool
+}
+
+// at reports whether pixel (x, y) is valid; pixels outside are not
+func (m *validMask) at(x, y int) bool {
+	return x >= 0 && y >= 0 && x < m.width && y < m.height && m.valid[y*m.width+x]
+}
+
+// validPixels reads an IFD a row of blocks at a time and records the pixels
+// that are not transparent and have at least one band that is not NoData
+func (c *COG) validPixels(ifdIndex int) (*validMask, error) {
+	meta := c.metadata[ifdIndex]
+	alpha := c.alphaBand()
+	m := &validMask{width: meta.Width, height: meta.Height, valid: make([]bool, meta.Width*meta.Height)}
+	_, rows := c.blockSize(ifdIndex)
+	if rows <= 0 {
+		rows = meta.Height
+	}
+	for y0 := 0; y0 < meta.Height; y0 += rows {
+		h := min(rows, meta.Height-y0)
+		data, err := c.readRaster(ifdIndex, 0, y0, meta.Width, h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rows %d to %d: %w", y0, y0+h, err)
+		}
+		for y := 0; y < h; y++ {
+			for x := 0; x < meta.Width; x++ {
+				if alpha >= 0 && data.Float(alpha, x, y) == 0 {
+					continue
+				}
+				for b := 0; b < data.Bands; b++ {
+					if b != alpha && !data.IsNoData(b, x, y) {
+						m.valid[(y0+y)*meta.Width+x] = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// imageIFDs returns the indices of the IFDs holding the main image and its
+// overviews, skipping internal masks
+func (c *COG) imageIFDs() []int {
+	var images []int
+	for i := range c.geoTIFFs {
+		if !c.isMask(i) {
+			images = append(images, i)
+		}
+	}
+	return images
+}
+
+// isMask reports whether an IFD is an internal transparency mask, whose
+// NewSubfileType has the mask flag (4) set
+func (c *COG) isMask(ifdIndex int) bool {
+	ifd := c.tiffReader.GetIFD(ifdIndex)
+	if ifd == nil || ifd.Tags[254] == nil { // NewSubfileType
+		return false
+	}
+	var subfileType uint32
+	switch v := ifd.Tags[254].Value.(type) {
+	case uint32:
+		subfileType = v
+	case []uint32:
+		if len(v) > 0 {
+			subfileType = v[0]
+		}
+	case uint16:
+		subfileType = uint32(v)
+	}
+	return subfileType&4 != 0
+}
+
+// maskIFD returns the index of the mask IFD with the dimensions of an image
+// IFD, or -1 if there is none
+func (c *COG) maskIFD(ifdIndex int) int {
+	meta := c.metadata[ifdIndex]
+	for i, m := range c.metadata {
+		if c.isMask(i) && m.Width == meta.Width && m.Height == meta.Height {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyMask invalidates the pixels whose sample in a mask IFD of 1 or 8
+// bits per pixel is 0. The mask is read a block at a time.
+func (c *COG) applyMask(m *validMask, ifdIndex int) error {
+	ifd := c.tiffReader.GetIFD(ifdIndex)
+	bits := 8
+	if tag := ifd.Tags[258]; tag != nil { // BitsPerSample
+		switch v := tag.Value.(type) {
+		case uint16:
+			bits = int(v)
+		case []uint16:
+			if len(v) > 0 {
+				bits = int(v[0])
+			}
+		}
+	}
+	if bits != 1 && bits != 8 {
+		return fmt.Errorf("unsupported mask of %d bits per pixel", bits)
+	}
+	compression := uint16(CompressionNone)
+	if tag := ifd.Tags[259]; tag != nil { // Compression
+		if v, ok := tag.Value.(uint16); ok {
+			compression = v
+		}
+	}
+
+	offsetsTag, countsTag := uint16(273), uint16(279) // StripOffsets, StripByteCounts
+	if ifd.Tags[324] != nil {
+		offsetsTag, countsTag = 324, 325 // TileOffsets, TileByteCounts
+	}
+	offsets, err := c.uint32Tag(ifd, offsetsTag)
+	if err != nil {
+		return err
+	}
+	counts, err := c.uint32Tag(ifd, countsTag)
+	if err != nil {
+		return err
+	}
+
+	blockWidth, blockHeight := c.blockSize(ifdIndex)
+	if blockHeight <= 0 {
+		blockHeight = m.height
+	}
+	rowBytes := blockWidth
+	if bits == 1 {
+		rowBytes = (blockWidth + 7) / 8
+	}
+	blocksPerRow := (m.width + blockWidth - 1) / blockWidth
+	for i := range offsets {
+		if i >= len(counts) {
+			break
+		}
+		x0, y0 := (i%blocksPerRow)*blockWidth, (i/blocksPerRow)*blockHeight
+		if y0 >= m.height {
+			break
+		}
+		raw := make([]byte, counts[i])
+		if _, err := c.reader.Seek(int64(offsets[i]), io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek to block %d: %w", i, err)
+		}
+		if _, err := io.ReadFull(c.reader, raw); err != nil {
+			return fmt.Errorf("failed to read block %d: %w", i, err)
+		}
+		// Strips may be shorter at the bottom of the image
+		rows := min(blockHeight, m.height-y0)
+		if ifd.Tags[324] != nil {
+			rows = blockHeight
+		}
+		block, err := c.decompressTile(raw, compression, ifd, rowBytes, rows, 1, DTByte)
+		if err != nil {
+			return fmt.Errorf("failed to decompress block %d: %w", i, err)
+		}
+		for y := 0; y < rows && y0+y < m.height; y++ {
+			row := block[y*rowBytes:]
+			for x := 0; x < blockWidth && x0+x < m.width; x++ {
+				var sample byte
+				if bits == 1 {
+					sample = row[x/8] & (0x80 >> (x % 8))
+				} else {
+					sample = row[x]
+				}
+				if sample == 0 {
+					m.valid[(y0+y)*m.width+x0+x] = false
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// uint32Tag returns the values of a LONG or SHORT array tag, loading them
+// if they were deferred
+func (c *COG) uint32Tag(ifd *IFD, id uint16) ([]uint32, error) {
+	tag := ifd.Tags[id]
+	if tag == nil {
+		return nil, fmt.Errorf("tag %d not found", id)
+	}
+	if tag.Value == nil && tag.IsOffset {
+		if err := c.tiffReader.ReadTagValue(ifd, id); err != nil {
+			return nil, fmt.Errorf("failed to read tag %d: %w", id, err)
+		}
+	}
+	switch v := tag.Value.(type) {
+	case uint32:
+		return []uint32{v}, nil
+	case []uint32:
+		return v, nil
+	case uint16:
+		return []uint32{uint32(v)}, nil
+	case []uint16:
+		values := make([]uint32, len(v))
+		for i, x := range v {
+			values[i] = uint32(x)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("tag %d has unexpected type %T", id, tag.Value)
+}
+
+// alphaBand returns the 0-based band holding alpha, the first extra sample
+// marked as associated or unassociated alpha, or -1 if there is none
+func (c *COG) alphaBand() int {
+	ifd := c.tiffReader.GetIFD(0)
+	if ifd == nil || ifd.Tags[338] == nil { // ExtraSamples
+		return -1
+	}
+	var extra []uint16
+	switch v := ifd.Tags[338].Value.(type) {
+	case uint16:
+		extra = []uint16{v}
+	case []uint16:
+		extra = v
+	}
+	bands := c.metadata[0].BandCount
+	for i, kind := range extra {
+		if band := bands - len(extra) + i; (kind == 1 || kind == 2) && band >= 0 {
+			return band
+		}
+	}
+	return -1
+}
+
+// Boundary directions in pixel space (y down), clockwise from east
+var traceDirections = [4][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+// edge reports whether a boundary edge leaves vertex (x, y) in direction d:
+// the pixel on its right is valid and the one on its left is not
+func (m *validMask) edge(x, y, d int) bool {
+	var right, left [2]int
+	switch d {
+	case 0: // East
+		right, left = [2]int{x, y}, [2]int{x, y - 1}
+	case 1: // South
+		right, left = [2]int{x - 1, y}, [2]int{x, y}
+	case 2: // West
+		right, left = [2]int{x - 1, y - 1}, [2]int{x - 1, y}
+	default: // North
+		right, left = [2]int{x, y - 1}, [2]int{x - 1, y - 1}
+	}
+	return m.at(right[0], right[1]) && !m.at(left[0], left[1])
+}
+
+// trace returns the outlines of the 4-connected regions of valid pixels in
+// pixel coordinates, each with its holes. Boundaries are walked with valid
+// pixels on the right, preferring right turns, so outer rings run clockwise
+// on screen and holes anticlockwise; only corners are kept.
+func (m *validMask) trace() []orb.Polygon {
+	labels, count := m.label()
+	outers := make([]orb.Ring, count)
+	holes := make([][]orb.Ring, count)
+
+	// Every ring has an eastward edge, the top of a valid pixel
+	visited := make([]bool, m.width*(m.height+1))
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			if visited[y*m.width+x] || !m.edge(x, y, 0) {
+				continue
+			}
+			ring := m.traceRing(x, y, visited)
+			label := labels[y*m.width+x]
+			if ring.Orientation() == orb.CCW { // Clockwise with y down
+				outers[label] = ring
+			} else {
+				holes[label] = append(holes[label], ring)
+			}
+		}
+	}
+
+	polygons := make([]orb.Polygon, 0, count)
+	for label, outer := range outers {
+		polygons = append(polygons, append(orb.Polygon{outer}, holes[label]...))
+	}
+	return polygons
+}
+
+// traceRing walks the boundary starting eastward from vertex (x, y),
+// marking its eastward edges in visited
+func (m *validMask) traceRing(x0, y0 int, visited []bool) orb.Ring {
+	ring := orb.Ring{{float64(x0), float64(y0)}}
+	x, y, d := x0, y0, 0
+	for {
+		if d == 0 {
+			visited[y*m.width+x] = true
+		}
+		x, y = x+traceDirections[d][0], y+traceDirections[d][1]
+		next := d
+		for _, turn := range []int{1, 0, 3} { // Right, straight, left
+			if m.edge(x, y, (d+turn)%4) {
+				next = (d + turn) % 4
+				break
+			}
+		}
+		if x == x0 && y == y0 && next == 0 {
+			if d == 0 {
+				ring = ring[1:] // The start is not a corner
+			}
+			return append(ring, ring[0])
+		}
+		if next != d {
+			ring = append(ring, orb.Point{float64(x), float64(y)})
+		}
+		d = next
+	}
+}
+
+// label assigns each valid pixel the index of its 4-connected region
+func (m *validMask) label() ([]int32, int) {
+	labels := make([]int32, len(m.valid))
+	for i := range labels {
+		labels[i] = -1
+	}
+	var count int32
+	var stack []int
+	for start, ok := range m.valid {
+		if !ok || labels[start] >= 0 {
+			continue
+		}
+		labels[start] = count
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%m.width, i/m.width
+			for _, d := range traceDirections {
+				nx, ny := x+d[0], y+d[1]
+				if j := ny*m.width + nx; m.at(nx, ny) && labels[j] < 0 {
+					labels[j] = count
+					stack = append(stack, j)
+				}
+			}
+		}
+		count++
+	}
+	return labels, int(count)
+}
+
+// dropSmallRings removes a polygon smaller than minArea, or its holes that
+// are
+func dropSmallRings(polygon orb.Polygon, minArea float64) orb.Polygon {
+	if math.Abs(planar.Area(polygon[0])) < minArea {
+		return nil
+	}
+	kept := polygon[:1]
+	for _, hole := range polygon[1:] {
+		if math.Abs(planar.Area(hole)) >= minArea {
+			kept = append(kept, hole)
+		}
+	}
+	return kept
+}
+
+// simplifyRings simplifies every ring of a polygon, dropping holes that
+// collapse, or the polygon if its outer ring does
+func simplifyRings(polygon orb.Polygon, tolerance float64) orb.Polygon {
+	dp := simplify.DouglasPeucker(tolerance)
+	kept := polygon[:0]
+	for i, ring := range polygon {
+		ring = dp.Ring(ring)
+		if len(ring) < 4 || planar.Area(ring) == 0 {
+			if i == 0 {
+				return nil
+			}
+			continue
+		}
+		kept = append(kept, ring)
+	}
+	return kept
+}
+
+// densifyRing inserts evenly spaced vertices into edges longer than maxLength
+func densifyRing(ring orb.Ring, maxLength float64) orb.Ring {
+	out := make(orb.Ring, 0, len(ring))
+	for i := 0; i+1 < len(ring); i++ {
+		a, b := ring[i], ring[i+1]
+		steps := int(math.Ceil(math.Hypot(b[0]-a[0], b[1]-a[1]) / maxLength))
+		for s := 0; s < max(steps, 1); s++ {
+			f := float64(s) / float64(max(steps, 1))
+			out = append(out, orb.Point{a[0] + f*(b[0]-a[0]), a[1] + f*(b[1]-a[1])})
+		}
+	}
+	return append(out, ring[len(ring)-1])
+}
@@ -0,0 +1,153 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+)
+
+// wedgeRaster is a 10 m UTM scene with a NoData wedge in the north-west
+// corner, a 4 x 4 NoData hole and a valid speck inside the wedge
+var wedgeRaster = testRaster{
+	Width: 64, Height: 64, TileSize: 16, Overviews: 1, EPSG: 32633, NoData: "0",
+	Origin: [2]float64{500000, 4000000}, PixelSize: [2]float64{10, 10},
+	Value: func(band, x, y int) float64 {
+		switch {
+		case x == 1 && y == 1:
+			return 1
+		case x+y < 16, x >= 30 && x < 34 && y >= 30 && y < 34:
+			return 0
+		}
+		return 1
+	},
+}
+
+func TestDataFootprint(t *testing.T) {
+	c := wedgeRaster.cog(t)
+	footprint, err := c.DataFootprint(FootprintOptions{})
+	if err != nil {
+		t.Fatalf("DataFootprint failed: %v", err)
+	}
+	if len(footprint) != 2 || len(footprint[0]) != 2 || len(footprint[1]) != 1 {
+		t.Fatalf("expected a polygon with a hole and a speck, got %v", footprint)
+	}
+	// 136 pixels of wedge and 16 of hole, plus the speck
+	if area := planar.Area(footprint); math.Abs(area-(64*64-136-16+1)*100) > 1e-6 {
+		t.Errorf("expected %v m², got %v", (64*64-136-16+1)*100, area)
+	}
+	if footprint[0][0].Orientation() != orb.CCW || footprint[0][1].Orientation() != orb.CW {
+		t.Error("expected counter-clockwise outer rings and clockwise holes")
+	}
+	if b := footprint.Bound(); b.Min[0] != 500000 || b.Max[1] != 4000000 || b.Max[0] != 500640 {
+		t.Errorf("unexpected footprint bounds %v", b)
+	}
+
+	// Specks and then holes are dropped by area
+	if footprint, _ = c.DataFootprint(FootprintOptions{MinArea: 2}); len(footprint) != 1 || len(footprint[0]) != 2 {
+		t.Errorf("expected the speck to be dropped, got %v", footprint)
+	}
+	if footprint, _ = c.DataFootprint(FootprintOptions{MinArea: 20}); len(footprint) != 1 || len(footprint[0]) != 1 {
+		t.Errorf("expected the hole to be filled, got %v", footprint)
+	}
+
+	// The staircase along the wedge simplifies to a straight edge
+	exact, _ := c.DataFootprint(FootprintOptions{MinArea: 20})
+	simple, err := c.DataFootprint(FootprintOptions{MinArea: 20, Simplify: 1.5})
+	if err != nil {
+		t.Fatalf("DataFootprint failed: %v", err)
+	}
+	if len(simple[0][0]) >= len(exact[0][0])/4 {
+		t.Errorf("expected far fewer than %d vertices, got %d", len(exact[0][0]), len(simple[0][0]))
+	}
+	if diff := math.Abs(planar.Area(simple) - planar.Area(exact)); diff > 16*100 {
+		t.Errorf("expected simplification to change the area by under 16 pixels, got %v m²", diff)
+	}
+
+	// Longitude/latitude with densified edges
+	lonLat, err := c.DataFootprint(FootprintOptions{Overview: 1, MinArea: 20, Simplify: 1, Densify: 4, LonLat: true})
+	if err != nil {
+		t.Fatalf("DataFootprint failed: %v", err)
+	}
+	if len(lonLat) != 1 || len(lonLat[0][0]) < 16 {
+		t.Fatalf("expected one densified polygon, got %v", lonLat)
+	}
+	if b := lonLat.Bound(); b.Min[0] < 14 || b.Max[0] > 16 || b.Min[1] < 36 || b.Max[1] > 36.2 {
+		t.Errorf("expected a footprint near 15°E 36°N, got %v", b)
+	}
+	if lonLat[0][0].Orientation() != orb.CCW {
+		t.Error("expected a counter-clockwise outer ring in longitude/latitude")
+	}
+
+	if _, err := c.DataFootprint(FootprintOptions{Overview: 5}); err == nil {
+		t.Error("expected error for a missing overview")
+	}
+}
+
+func TestDataFootprintDiagonal(t *testing.T) {
+	// Diagonally touching pixels are separate polygons
+	c := testRaster{
+		Width: 8, Height: 8, EPSG: 4326, NoData: "0",
+		Value: func(band, x, y int) float64 {
+			if (x == 2 && y == 2) || (x == 3 && y == 3) || (x == 4 && y == 2) {
+				return 1
+			}
+			return 0
+		},
+	}.cog(t)
+	footprint, err := c.DataFootprint(FootprintOptions{})
+	if err != nil {
+		t.Fatalf("DataFootprint failed: %v", err)
+	}
+	if len(footprint) != 3 {
+		t.Fatalf("expected 3 polygons, got %v", footprint)
+	}
+	for _, polygon := range footprint {
+		if len(polygon[0]) != 5 || planar.Area(polygon) != 1 {
+			t.Errorf("expected a single pixel, got %v", polygon)
+		}
+	}
+}
+
+func TestDataFootprintAlpha(t *testing.T) {
+	// RGBA: transparent pixels in the western half are outside, whatever
+	// their colour
+	c := testRaster{
+		Width: 16, Height: 16, Bands: 4, EPSG: 4326,
+		ExtraTags: []testTag{shortsTag(338, 2)}, // ExtraSamples: unassociated alpha
+		Value: func(band, x, y int) float64 {
+			if band == 3 && x < 8 {
+				return 0
+			}
+			return 255
+		},
+	}.cog(t)
+	footprint, err := c.DataFootprint(FootprintOptions{})
+	if err != nil {
+		t.Fatalf("DataFootprint failed: %v", err)
+	}
+	if len(footprint) != 1 || planar.Area(footprint) != 8*16 {
+		t.Errorf("expected the opaque half, got %v", footprint)
+	}
+}
+
+func TestDataFootprintMask(t *testing.T) {
+	// The internal mask hides the eastern columns; the mask IFDs follow
+	// their levels and are not overviews
+	tr := slopeRaster
+	tr.Mask = func(x, y int) bool { return x < 40 }
+	c := tr.cog(t)
+	for overview := 0; overview <= 1; overview++ {
+		footprint, err := c.DataFootprint(FootprintOptions{Overview: overview})
+		if err != nil {
+			t.Fatalf("overview %d: DataFootprint failed: %v", overview, err)
+		}
+		if b := footprint.Bound(); len(footprint) != 1 || b.Min[0] != 500000 || b.Max[0] != 500400 || planar.Area(footprint) != 400*640 {
+			t.Errorf("overview %d: expected the unmasked western part, got %v", overview, footprint)
+		}
+	}
+	if _, err := c.DataFootprint(FootprintOptions{Overview: 2}); err == nil {
+		t.Error("expected error for a mask IFD index")
+	}
+}
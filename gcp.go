@@ -0,0 +1,388 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+)
+
+// GCPMethod selects how ground control points are fitted
+type GCPMethod int
+
+const (
+	// GCPAuto fits a first-order polynomial, or a second-order one with
+	// ten or more GCPs, as GDAL does
+	GCPAuto GCPMethod = iota
+	GCPPolynomial1
+	GCPPolynomial2
+	GCPPolynomial3
+	// GCPThinPlateSpline passes exactly through every GCP, bending smoothly
+	// between them
+	GCPThinPlateSpline
+)
+
+// String returns the name of the method
+func (m GCPMethod) String() string {
+	switch m {
+	case GCPAuto:
+		return "auto"
+	case GCPPolynomial1:
+		return "polynomial order 1"
+	case GCPPolynomial2:
+		return "polynomial order 2"
+	case GCPPolynomial3:
+		return "polynomial order 3"
+	case GCPThinPlateSpline:
+		return "thin plate spline"
+	}
+	return fmt.Sprintf("GCPMethod(%d)", int(m))
+}
+
+// GCPTransform maps between pixel and georeferenced coordinates of an image
+// georeferenced by ground control points (tie points without a pixel scale).
+// Forward and inverse mappings are fitted separately.
+type GCPTransform struct {
+	Method    GCPMethod // The fitted method, never GCPAuto
+	GCPs      []TiePoint
+	Residuals []GCPResidual // Per GCP, in the order of GCPs
+	RMSE      float64       // Root mean square of the georeferenced residuals
+
+	forward gcpFunc // Pixel to georeferenced
+	inverse gcpFunc // Georeferenced to pixel
+	affine  GeoTransform
+}
+
+// GCPResidual is the misfit of a GCP: the fitted minus the control
+// coordinates, in georeferenced units (forward) and pixels (inverse)
+type GCPResidual struct {
+	DX, DY           float64
+	PixelDX, PixelDY float64
+}
+
+// gcpFunc is a fitted 2D mapping
+type gcpFunc interface {
+	apply(x, y float64) (float64, float64)
+}
+
+// FitGCPTransform fits a transform to ground control points. Polynomials
+// need at least 3, 6 and 10 GCPs for orders 1, 2 and 3; thin plate splines
+// need 3 that are not collinear.
+func FitGCPTransform(gcps []TiePoint, method GCPMethod) (*GCPTransform, error) {
+	if method == GCPAuto {
+		method = GCPPolynomial1
+		if len(gcps) >= 10 {
+			method = GCPPolynomial2
+		}
+	}
+
+	pixels := make([][2]float64, len(gcps))
+	geos := make([][2]float64, len(gcps))
+	for i, g := range gcps {
+		pixels[i] = [2]float64{g.PixelX, g.PixelY}
+		geos[i] = [2]float64{g.GeoX, g.GeoY}
+	}
+
+	t := &GCPTransform{Method: method, GCPs: gcps}
+	var err error
+	switch method {
+	case GCPPolynomial1, GCPPolynomial2, GCPPolynomial3:
+		order := int(method - GCPPolynomial1 + 1)
+		if t.forward, err = fitPolynomial(pixels, geos, order); err == nil {
+			t.inverse, err = fitPolynomial(geos, pixels, order)
+		}
+	case GCPThinPlateSpline:
+		if t.forward, err = fitThinPlateSpline(pixels, geos); err == nil {
+			t.inverse, err = fitThinPlateSpline(geos, pixels)
+		}
+	default:
+		return nil, fmt.Errorf("unknown GCP method %d", method)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit %v to %d GCPs: %w", method, len(gcps), err)
+	}
+
+	affine, err := fitPolynomial(pixels, geos, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fit %v to %d GCPs: %w", method, len(gcps), err)
+	}
+	t.affine = affine.geoTransform()
+
+	var sum float64
+	t.Residuals = make([]GCPResidual, len(gcps))
+	for i, g := range gcps {
+		x, y := t.forward.apply(g.PixelX, g.PixelY)
+		px, py := t.inverse.apply(g.GeoX, g.GeoY)
+		r := GCPResidual{DX: x - g.GeoX, DY: y - g.GeoY, PixelDX: px - g.PixelX, PixelDY: py - g.PixelY}
+		t.Residuals[i] = r
+		sum += r.DX*r.DX + r.DY*r.DY
+	}
+	if len(gcps) > 0 {
+		t.RMSE = math.Sqrt(sum / float64(len(gcps)))
+	}
+	return t, nil
+}
+
+// PixelToGeo converts pixel coordinates of the full-resolution image to
+// georeferenced coordinates
+func (t *GCPTransform) PixelToGeo(pixelX, pixelY float64) (float64, float64) {
+	return t.forward.apply(pixelX, pixelY)
+}
+
+// GeoToPixel converts georeferenced coordinates to pixel coordinates of the
+// full-resolution image
+func (t *GCPTransform) GeoToPixel(geoX, geoY float64) (float64, float64) {
+	return t.inverse.apply(geoX, geoY)
+}
+
+// Affine returns the least-squares affine approximation of the transform,
+// e.g. for estimating the pixel size
+func (t *GCPTransform) Affine() GeoTransform {
+	return t.affine
+}
+
+// normalization centres and scales coordinates to about unit size, so the
+// fitted systems are well conditioned
+type normalization struct {
+	cx, cy, scale float64
+}
+
+func newNormalization(points [][2]float64) normalization {
+	var n normalization
+	for _, p := range points {
+		n.cx += p[0]
+		n.cy += p[1]
+	}
+	n.cx /= float64(len(points))
+	n.cy /= float64(len(points))
+	for _, p := range points {
+		n.scale = math.Max(n.scale, math.Max(math.Abs(p[0]-n.cx), math.Abs(p[1]-n.cy)))
+	}
+	if n.scale == 0 {
+		n.scale = 1
+	}
+	return n
+}
+
+func (n normalization) apply(x, y float64) (float64, float64) {
+	return (x - n.cx) / n.scale, (y - n.cy) / n.scale
+}
+
+// polynomial is a least-squares polynomial mapping of order 1 to 3
+type polynomial struct {
+	order  int
+	norm   normalization
+	coeffs [2][]float64
+}
+
+// polynomialTerms returns the monomials of (u, v) up to order
+func polynomialTerms(u, v float64, order int, terms []float64) []float64 {
+	terms = append(terms[:0], 1, u, v)
+	if order >= 2 {
+		terms = append(terms, u*u, u*v, v*v)
+	}
+	if order >= 3 {
+		terms = append(terms, u*u*u, u*u*v, u*v*v, v*v*v)
+	}
+	return terms
+}
+
+func fitPolynomial(src, dst [][2]float64, order int) (*polynomial, error) {
+	count := (order + 1) * (order + 2) / 2
+	if len(src) < count {
+		return nil, fmt.Errorf("order %d needs at least %d GCPs", order, count)
+	}
+
+	// Normal equations of the least-squares fit, with both outputs as
+	// right-hand sides
+	p := &polynomial{order: order, norm: newNormalization(src)}
+	system := make([][]float64, count)
+	for i := range system {
+		system[i] = make([]float64, count+2)
+	}
+	var terms []float64
+	for i, s := range src {
+		u, v := p.norm.apply(s[0], s[1])
+		terms = polynomialTerms(u, v, order, terms)
+		for r := range terms {
+			for c := range terms {
+				system[r][c] += terms[r] * terms[c]
+			}
+			system[r][count] += terms[r] * dst[i][0]
+			system[r][count+1] += terms[r] * dst[i][1]
+		}
+	}
+	solution, err := solveLinear(system)
+	if err != nil {
+		return nil, err
+	}
+	p.coeffs = solution
+	return p, nil
+}
+
+func (p *polynomial) apply(x, y float64) (float64, float64) {
+	var buf [10]float64
+	u, v := p.norm.apply(x, y)
+	terms := polynomialTerms(u, v, p.order, buf[:0])
+	var rx, ry float64
+	for i, t := range terms {
+		rx += p.coeffs[0][i] * t
+		ry += p.coeffs[1][i] * t
+	}
+	return rx, ry
+}
+
+// geoTransform returns a first-order polynomial as a geotransform
+func (p *polynomial) geoTransform() GeoTransform {
+	// x' = a0 + a1*(x-cx)/s + a2*(y-cy)/s
+	s := p.norm.scale
+	gt := GeoTransform{0, p.coeffs[0][1] / s, p.coeffs[0][2] / s, 0, p.coeffs[1][1] / s, p.coeffs[1][2] / s}
+	gt[0] = p.coeffs[0][0] - gt[1]*p.norm.cx - gt[2]*p.norm.cy
+	gt[3] = p.coeffs[1][0] - gt[4]*p.norm.cx - gt[5]*p.norm.cy
+	return gt
+}
+
+// thinPlateSpline interpolates exactly through its control points with the
+// radial basis r² log r² plus an affine part
+type thinPlateSpline struct {
+	norm    normalization
+	points  [][2]float64 // Normalized control points
+	weights [2][]float64 // Per point, followed by the affine coefficients
+}
+
+func tpsKernel(dx, dy float64) float64 {
+	r2 := dx*dx + dy*dy
+	if r2 == 0 {
+		return 0
+	}
+	return r2 * math.Log(r2)
+}
+
+func fitThinPlateSpline(src, dst [][2]float64) (*thinPlateSpline, error) {
+	n := len(src)
+	if n < 3 {
+		return nil, fmt.Errorf("thin plate splines need at least 3 GCPs")
+	}
+
+	t := &thinPlateSpline{norm: newNormalization(src), points: make([][2]float64, n)}
+	for i, s := range src {
+		u, v := t.norm.apply(s[0], s[1])
+		t.points[i] = [2]float64{u, v}
+	}
+
+	// [K P; Pᵀ 0] [w; a] = [dst; 0]
+	size := n + 3
+	system := make([][]float64, size)
+	for i := range system {
+		system[i] = make([]float64, size+2)
+	}
+	for i, p := range t.points {
+		for j, q := range t.points {
+			system[i][j] = tpsKernel(p[0]-q[0], p[1]-q[1])
+		}
+		affine := [3]float64{1, p[0], p[1]}
+		for k, a := range affine {
+			system[i][n+k] = a
+			system[n+k][i] = a
+		}
+		system[i][size] = dst[i][0]
+		system[i][size+1] = dst[i][1]
+	}
+	solution, err := solveLinear(system)
+	if err != nil {
+		return nil, err
+	}
+	t.weights = solution
+	return t, nil
+}
+
+func (t *thinPlateSpline) apply(x, y float64) (float64, float64) {
+	u, v := t.norm.apply(x, y)
+	n := len(t.points)
+	wx, wy := t.weights[0], t.weights[1]
+	rx := wx[n] + wx[n+1]*u + wx[n+2]*v
+	ry := wy[n] + wy[n+1]*u + wy[n+2]*v
+	for i, p := range t.points {
+		k := tpsKernel(u-p[0], v-p[1])
+		rx += wx[i] * k
+		ry += wy[i] * k
+	}
+	return rx, ry
+}
+
+// solveLinear solves an n x n system given as an augmented n x (n+2) matrix
+// with two right-hand sides, by Gaussian elimination with partial pivoting.
+// The matrix is modified.
+func solveLinear(m [][]float64) ([2][]float64, error) {
+	n := len(m)
+	var largest float64
+	for _, row := range m {
+		for _, v := range row[:n] {
+			largest = math.Max(largest, math.Abs(v))
+		}
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(m[r][col]) > math.Abs(m[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(m[pivot][col]) <= 1e-12*largest {
+			return [2][]float64{}, fmt.Errorf("GCPs are degenerate (collinear or duplicated)")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for r := col + 1; r < n; r++ {
+			f := m[r][col] / m[col][col]
+			if f == 0 {
+				continue
+			}
+			for c := col; c < n+2; c++ {
+				m[r][c] -= f * m[col][c]
+			}
+		}
+	}
+
+	var solution [2][]float64
+	for k := range solution {
+		x := make([]float64, n)
+		for r := n - 1; r >= 0; r-- {
+			sum := m[r][n+k]
+			for c := r + 1; c < n; c++ {
+				sum -= m[r][c] * x[c]
+			}
+			x[r] = sum / m[r][r]
+		}
+		solution[k] = x
+	}
+	return solution, nil
+}
+
+// GCPTransform returns the transform of an image georeferenced by ground
+// control points, and false if the image has a geotransform or no GCPs
+func (c *COG) GCPTransform() (*GCPTransform, bool) {
+	if len(c.geoTIFFs) == 0 {
+		return nil, false
+	}
+	t, ok := c.geoTIFFs[0].model.(*GCPTransform)
+	return t, ok
+}
+
+// SetGCPMethod refits the GCPs of an image georeferenced by ground control
+// points with another method. Bounds, pixel conversions and reads use the
+// new transform.
+func (c *COG) SetGCPMethod(method GCPMethod) error {
+	current, ok := c.GCPTransform()
+	if !ok {
+		return fmt.Errorf("image is not georeferenced by GCPs")
+	}
+	t, err := FitGCPTransform(current.GCPs, method)
+	if err != nil {
+		return err
+	}
+	for _, gtr := range c.geoTIFFs {
+		if _, ok := gtr.model.(*GCPTransform); ok {
+			gtr.model = t
+		}
+	}
+	return nil
+}
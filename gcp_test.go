@@ -0,0 +1,161 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+)
+
+// gcpGrid returns GCPs on a grid over a width x height image, located by f
+func gcpGrid(width, height, n int, f func(x, y float64) (float64, float64)) []TiePoint {
+	var gcps []TiePoint
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			x := float64(width) * float64(i) / float64(n-1)
+			y := float64(height) * float64(j) / float64(n-1)
+			gx, gy := f(x, y)
+			gcps = append(gcps, TiePoint{PixelX: x, PixelY: y, GeoX: gx, GeoY: gy})
+		}
+	}
+	return gcps
+}
+
+func TestGCPGeoreferencing(t *testing.T) {
+	// 0.01° pixels from 10°E 50°N, values are the column
+	lonLat := func(x, y float64) (float64, float64) { return 10 + x*0.01, 50 - y*0.01 }
+	tr := testRaster{
+		Width: 128, Height: 128, DataType: DTSShort, TileSize: 32, Overviews: 1, EPSG: 4326,
+		GCPs:  gcpGrid(128, 128, 3, lonLat),
+		Value: func(band, x, y int) float64 { return float64(x) },
+	}
+	c := tr.cog(t)
+
+	if _, ok := c.GeoTransform(0); ok {
+		t.Error("expected no geotransform for a GCP-only image")
+	}
+	transform, ok := c.GCPTransform()
+	if !ok || transform.Method != GCPPolynomial1 || transform.RMSE > 1e-9 || len(transform.Residuals) != 9 {
+		t.Fatalf("expected an exact first-order fit, got %+v", transform)
+	}
+
+	bounds := c.Bounds()
+	want := orb.Bound{Min: orb.Point{10, 48.72}, Max: orb.Point{11.28, 50}}
+	if math.Abs(bounds.Min[0]-want.Min[0]) > 1e-9 || math.Abs(bounds.Min[1]-want.Min[1]) > 1e-9 ||
+		math.Abs(bounds.Max[0]-want.Max[0]) > 1e-9 || math.Abs(bounds.Max[1]-want.Max[1]) > 1e-9 {
+		t.Errorf("expected bounds %v, got %v", want, bounds)
+	}
+	if x, y := c.PixelFromPoint(orb.Point{10.505, 49.495}, 0); x != 50 || y != 50 {
+		t.Errorf("expected pixel (50, 50), got (%d, %d)", x, y)
+	}
+	if p := c.PointFromPixel(32, 32, 1); math.Abs(p[0]-10.64) > 1e-9 || math.Abs(p[1]-49.36) > 1e-9 {
+		t.Errorf("expected the overview pixel at (10.64, 49.36), got %v", p)
+	}
+
+	s, err := c.Sample(orb.Point{10.705, 49.5})
+	if err != nil {
+		t.Fatalf("Sample failed: %v", err)
+	}
+	if !s.Inside || s.Values[0] != 70 {
+		t.Errorf("expected column 70, got %+v", s)
+	}
+
+	data, err := c.ReadRegion(orb.Bound{Min: orb.Point{10.205, 49.495}, Max: orb.Point{10.405, 49.695}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	if data.Width != 20 || data.At(0, 0, 0) != 20 {
+		t.Errorf("expected 20 columns from column 20, got %d from %d", data.Width, data.At(0, 0, 0))
+	}
+
+	tile := maptile.At(orb.Point{10.64, 49.36}, 9)
+	tileData, err := c.ReadTile(tile)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if tileData.Mask != nil {
+		t.Error("expected the tile to lie inside the image")
+	}
+}
+
+func TestFitGCPTransform(t *testing.T) {
+	// A quadratic distortion of an affine mapping
+	quadratic := func(x, y float64) (float64, float64) {
+		return 1000 + 2*x + 0.5*y + 0.001*x*x, 5000 - 2*y + 0.3*x - 0.002*x*y
+	}
+	gcps := gcpGrid(1000, 1000, 4, quadratic)
+
+	auto, err := FitGCPTransform(gcps, GCPAuto)
+	if err != nil {
+		t.Fatalf("FitGCPTransform failed: %v", err)
+	}
+	if auto.Method != GCPPolynomial2 || auto.RMSE > 1e-6 {
+		t.Errorf("expected an exact second-order fit for 16 GCPs, got %v with RMSE %v", auto.Method, auto.RMSE)
+	}
+	x, y := auto.PixelToGeo(333, 777)
+	wantX, wantY := quadratic(333, 777)
+	if math.Abs(x-wantX) > 1e-6 || math.Abs(y-wantY) > 1e-6 {
+		t.Errorf("expected (%v, %v), got (%v, %v)", wantX, wantY, x, y)
+	}
+
+	linear, err := FitGCPTransform(gcps, GCPPolynomial1)
+	if err != nil {
+		t.Fatalf("FitGCPTransform failed: %v", err)
+	}
+	if linear.RMSE < 1 {
+		t.Errorf("expected a first-order fit to leave residuals, got RMSE %v", linear.RMSE)
+	}
+	if gt := linear.Affine(); math.Abs(gt[1]-3) > 0.5 || math.Abs(gt[5]+3) > 0.5 {
+		t.Errorf("unexpected affine approximation %v", gt)
+	}
+
+	if _, err := FitGCPTransform(gcps, GCPPolynomial3); err != nil {
+		t.Errorf("expected a third-order fit to 16 GCPs, got %v", err)
+	}
+	if _, err := FitGCPTransform(gcps[:8], GCPPolynomial3); err == nil {
+		t.Error("expected error for too few GCPs for order 3")
+	}
+
+	// Thin plate splines pass through every GCP in both directions
+	tps, err := FitGCPTransform(gcps, GCPThinPlateSpline)
+	if err != nil {
+		t.Fatalf("FitGCPTransform failed: %v", err)
+	}
+	for i, r := range tps.Residuals {
+		if math.Hypot(r.DX, r.DY) > 1e-6 || math.Hypot(r.PixelDX, r.PixelDY) > 1e-6 {
+			t.Errorf("GCP %d: expected no residual, got %+v", i, r)
+		}
+	}
+	gcp := gcps[5]
+	if px, py := tps.GeoToPixel(gcp.GeoX, gcp.GeoY); math.Abs(px-gcp.PixelX) > 1e-6 || math.Abs(py-gcp.PixelY) > 1e-6 {
+		t.Errorf("expected pixel (%v, %v), got (%v, %v)", gcp.PixelX, gcp.PixelY, px, py)
+	}
+
+	collinear := []TiePoint{{PixelX: 0, GeoX: 0}, {PixelX: 1, GeoX: 1}, {PixelX: 2, GeoX: 2}}
+	if _, err := FitGCPTransform(collinear, GCPPolynomial1); err == nil {
+		t.Error("expected error for collinear GCPs")
+	}
+}
+
+func TestSetGCPMethod(t *testing.T) {
+	tr := testRaster{Width: 64, Height: 64, EPSG: 4326, GCPs: gcpGrid(64, 64, 4, func(x, y float64) (float64, float64) {
+		return x + 0.01*y*y, -y
+	})}
+	c := tr.cog(t)
+	if err := c.SetGCPMethod(GCPThinPlateSpline); err != nil {
+		t.Fatalf("SetGCPMethod failed: %v", err)
+	}
+	transform, _ := c.GCPTransform()
+	if transform.Method != GCPThinPlateSpline || transform.RMSE > 1e-9 {
+		t.Errorf("expected an exact thin plate spline, got %v with RMSE %v", transform.Method, transform.RMSE)
+	}
+	// The curved right edge is enclosed by the bounds
+	if b := c.Bounds(); b.Max[0] < 64+0.01*64*64-1e-6 {
+		t.Errorf("expected bounds to enclose the curved edges, got %v", b)
+	}
+
+	if err := sampleRaster.cog(t).SetGCPMethod(GCPPolynomial2); err == nil {
+		t.Error("expected error for an image with a geotransform")
+	}
+}
@@ -1,6 +1,8 @@
 package gocog
 
 import (
+	"math"
+
 	"github.com/paulmach/orb"
 )
 
@@ -36,27 +38,12 @@ func (c *COG) PointFromPixel(x, y int, overview int) orb.Point {
 // PixelFromPoint converts a geographic point to pixel coordinates
 func (c *COG) PixelFromPoint(point orb.Point, overview int) (int, int) {
 	overviewIndex := overview // overview 0 = main image (IFD 0), overview 1+ = overviews (IFD 1+)
-	if overviewIndex < 0 || overviewIndex >= len(c.metadata) {
-		return 0, 0
-	}
-
-	meta := c.metadata[overviewIndex]
-	gtr := c.geoTIFFs[overviewIndex]
-
-	imgBounds := gtr.Bounds()
-
-	// Calculate pixel coordinates
-	geoWidth := imgBounds.Max[0] - imgBounds.Min[0]
-	geoHeight := imgBounds.Max[1] - imgBounds.Min[1]
-
-	if geoWidth == 0 || geoHeight == 0 {
+	if overviewIndex < 0 || overviewIndex >= len(c.geoTIFFs) {
 		return 0, 0
 	}
 
-	pixelX := int((point[0] - imgBounds.Min[0]) / geoWidth * float64(meta.Width))
-	pixelY := int((imgBounds.Max[1] - point[1]) / geoHeight * float64(meta.Height)) // Y is inverted
-
-	return pixelX, pixelY
+	pixelX, pixelY := c.geoTIFFs[overviewIndex].geoToPixel(point[0], point[1])
+	return int(math.Floor(pixelX)), int(math.Floor(pixelY))
 }
 
 // GetImagePolygon returns the image bounds as a polygon
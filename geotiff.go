@@ -2,9 +2,9 @@ package gocog
 
 import (
 	"encoding/binary"
+	"encoding/xml"
 	"fmt"
 	"io"
-	"math"
 	"strconv"
 	"strings"
 
@@ -19,6 +19,8 @@ const (
 	TagGeoKeyDirectory     = 34735
 	TagGeoDoubleParams     = 34736
 	TagGeoAsciiParams      = 34737
+	TagGDALMetadata        = 42112
+	TagGDALNoData          = 42113
 )
 
 // GeoKeys
@@ -31,21 +33,27 @@ const (
 	GTRasterTypePixelIsArea  = 1
 	GTRasterTypePixelIsPoint = 2
 
-	GeographicTypeGeoKey    = 2048
-	GeogCitationGeoKey      = 2049
-	GeogGeodeticDatumGeoKey = 2050
-	GeogPrimeMeridianGeoKey = 2051
-	GeogLinearUnitsGeoKey   = 2052
-	GeogAngularUnitsGeoKey  = 2053
-	GeogSemiMajorAxisGeoKey = 2057
-	GeogSemiMinorAxisGeoKey = 2058
-	GeogInvFlatteningGeoKey = 2059
+	GTCitationGeoKey = 1026
+
+	GeographicTypeGeoKey        = 2048
+	GeogCitationGeoKey          = 2049
+	GeogGeodeticDatumGeoKey     = 2050
+	GeogPrimeMeridianGeoKey     = 2051
+	GeogLinearUnitsGeoKey       = 2052
+	GeogAngularUnitsGeoKey      = 2053
+	GeogAngularUnitSizeGeoKey   = 2054
+	GeogEllipsoidGeoKey         = 2056
+	GeogSemiMajorAxisGeoKey     = 2057
+	GeogSemiMinorAxisGeoKey     = 2058
+	GeogInvFlatteningGeoKey     = 2059
+	GeogPrimeMeridianLongGeoKey = 2061
 
 	ProjectedCSTypeGeoKey          = 3072
 	PCSCitationGeoKey              = 3073
 	ProjectionGeoKey               = 3074
 	ProjCoordTransGeoKey           = 3075
 	ProjLinearUnitsGeoKey          = 3076
+	ProjLinearUnitSizeGeoKey       = 3077
 	ProjStdParallel1GeoKey         = 3078
 	ProjStdParallel2GeoKey         = 3079
 	ProjNatOriginLongGeoKey        = 3080
@@ -64,6 +72,20 @@ const (
 	ProjScaleAtCenterGeoKey        = 3093
 	ProjAzimuthAngleGeoKey         = 3094
 	ProjStraightVertPoleLongGeoKey = 3095
+
+	// Vertical CS parameter keys
+	VerticalCSTypeGeoKey   = 4096
+	VerticalCitationGeoKey = 4097
+	VerticalDatumGeoKey    = 4098
+	VerticalUnitsGeoKey    = 4099
+
+	// ProjCoordTransGeoKey values
+	CTTransverseMercator   = 1
+	CTLambertConfConic2SP  = 8
+	CTLambertConfConic1SP  = 9
+	CTLambertAzimEqualArea = 10
+	CTAlbersEqualArea      = 11
+	CTPolarStereographic   = 15
 )
 
 // GeoTIFF metadata
@@ -79,7 +101,13 @@ type GeoTIFFMetadata struct {
 	Height                    int
 	BandCount                 int
 	DataType                  DataType
-	PhotometricInterpretation uint16 // Tag 262: 0=WhiteIsZero, 1=BlackIsZero, 2=RGB, 3=Palette
+	PhotometricInterpretation uint16  // Tag 262: 0=WhiteIsZero, 1=BlackIsZero, 2=RGB, 3=Palette
+	NoData                    float64 // Tag 42113 (GDAL_NODATA), only meaningful if HasNoData is set
+	HasNoData                 bool
+	Scales                    []float64 // Per-band scale from GDAL_METADATA (tag 42112), nil if absent
+	Offsets                   []float64 // Per-band offset from GDAL_METADATA (tag 42112), nil if absent
+	RasterType                uint16    // GTRasterTypePixelIsArea (default) or GTRasterTypePixelIsPoint
+	RPC                       *RPC      // Tag 50844 (RPCCoefficientTag), nil if absent
 }
 
 // TiePoint represents a georeferencing tie point
@@ -92,6 +120,16 @@ type TiePoint struct {
 type GeoTIFFReader struct {
 	tr       *TIFFReader
 	metadata *GeoTIFFMetadata
+
+	geoTransform        GeoTransform
+	inverseGeoTransform GeoTransform
+	hasGeoTransform     bool
+
+	// GCP or RPC georeferencing, used when there is no geotransform.
+	// modelScale is the number of full-resolution pixels per pixel of this
+	// IFD.
+	model      pixelModel
+	modelScale [2]float64
 }
 
 // NewGeoTIFFReader creates a new GeoTIFF reader
@@ -208,6 +246,41 @@ func (gtr *GeoTIFFReader) readMetadata(ifdIndex int) error {
 		}
 	}
 
+	// Read GDAL_NODATA (ASCII, load on demand if not already loaded)
+	if tag := ifd.Tags[TagGDALNoData]; tag != nil {
+		if tag.Value == nil && tag.IsOffset {
+			gtr.tr.ReadTagValue(ifd, TagGDALNoData)
+		}
+		if str, ok := tag.Value.(string); ok {
+			if noData, err := parseNoData(str); err == nil {
+				gtr.metadata.NoData = noData
+				gtr.metadata.HasNoData = true
+			}
+		}
+	}
+
+	// Read GDAL_METADATA (XML, load on demand if not already loaded)
+	if tag := ifd.Tags[TagGDALMetadata]; tag != nil {
+		if tag.Value == nil && tag.IsOffset {
+			gtr.tr.ReadTagValue(ifd, TagGDALMetadata)
+		}
+		if str, ok := tag.Value.(string); ok {
+			gtr.metadata.Scales, gtr.metadata.Offsets = parseGDALMetadata(str, gtr.metadata.BandCount)
+		}
+	}
+
+	// Read RPCCoefficientTag (load on demand if not already loaded)
+	if tag := ifd.Tags[TagRPCCoefficients]; tag != nil {
+		if tag.Value == nil && tag.IsOffset {
+			gtr.tr.ReadTagValue(ifd, TagRPCCoefficients)
+		}
+		if values, ok := tag.Value.([]float64); ok {
+			if rpc, err := parseRPC(values); err == nil {
+				gtr.metadata.RPC = rpc
+			}
+		}
+	}
+
 	// Read GeoKeys
 	if err := gtr.readGeoKeys(ifd); err != nil {
 		return fmt.Errorf("failed to read GeoKeys: %w", err)
@@ -216,6 +289,26 @@ func (gtr *GeoTIFFReader) readMetadata(ifdIndex int) error {
 	// Determine CRS
 	gtr.metadata.CRS = gtr.determineCRS()
 
+	// Determine raster space convention
+	gtr.metadata.RasterType = GTRasterTypePixelIsArea
+	if rasterType, ok := gtr.metadata.GeoKeys[GTRasterTypeGeoKey].(uint16); ok && rasterType == GTRasterTypePixelIsPoint {
+		gtr.metadata.RasterType = GTRasterTypePixelIsPoint
+	}
+
+	// Build the affine geotransform
+	if gt, ok := geoTransformFromMetadata(gtr.metadata); ok {
+		gtr.setGeoTransform(gt)
+	} else if len(gtr.metadata.TiePoints) >= 3 {
+		// Ground control points only; images whose GCPs cannot be fitted
+		// are left ungeoreferenced
+		if t, err := FitGCPTransform(gtr.metadata.TiePoints, GCPAuto); err == nil {
+			gtr.model, gtr.modelScale = t, [2]float64{1, 1}
+		}
+	} else if rpcGeoreferenced(gtr.metadata) {
+		gtr.model = newRPCModel(gtr.metadata.RPC, gtr.metadata.Width, gtr.metadata.Height)
+		gtr.modelScale = [2]float64{1, 1}
+	}
+
 	return nil
 }
 
@@ -274,6 +367,65 @@ func (gtr *GeoTIFFReader) determineDataType(ifd *IFD) DataType {
 	}
 }
 
+// parseNoData parses a GDAL_NODATA string such as "0", "-9999" or "nan"
+func parseNoData(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimRight(s, "\x00"))
+	if s == "" {
+		return 0, fmt.Errorf("empty nodata value")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// gdalMetadata is the XML document stored in the GDAL_METADATA tag
+type gdalMetadata struct {
+	Items []struct {
+		Name   string `xml:"name,attr"`
+		Sample string `xml:"sample,attr"`
+		Role   string `xml:"role,attr"`
+		Value  string `xml:",chardata"`
+	} `xml:"Item"`
+}
+
+// parseGDALMetadata extracts per-band scale and offset values from GDAL_METADATA XML.
+// Bands without an explicit value get scale 1 and offset 0; nil is returned if no
+// band has a scale or offset.
+func parseGDALMetadata(doc string, bands int) ([]float64, []float64) {
+	var md gdalMetadata
+	if err := xml.Unmarshal([]byte(strings.TrimRight(doc, "\x00")), &md); err != nil {
+		return nil, nil
+	}
+
+	var scales, offsets []float64
+	for _, item := range md.Items {
+		role := strings.ToLower(item.Role)
+		if role != "scale" && role != "offset" {
+			continue
+		}
+		band, err := strconv.Atoi(item.Sample)
+		if err != nil || band < 0 || band >= bands {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(item.Value), 64)
+		if err != nil {
+			continue
+		}
+
+		if scales == nil {
+			scales = make([]float64, bands)
+			offsets = make([]float64, bands)
+			for i := range scales {
+				scales[i] = 1
+			}
+		}
+		if role == "scale" {
+			scales[band] = value
+		} else {
+			offsets[band] = value
+		}
+	}
+	return scales, offsets
+}
+
 // parseTiePoints parses tie point values
 func parseTiePoints(values []float64) []TiePoint {
 	if len(values) < 6 {
@@ -404,22 +556,31 @@ func (gtr *GeoTIFFReader) readGeoKeys(ifd *IFD) error {
 	return nil
 }
 
-// determineCRS determines the CRS from GeoKeys
+// determineCRS determines the CRS from GeoKeys. User-defined systems have no
+// code; their definition is available from CoordinateReferenceSystem.
 func (gtr *GeoTIFFReader) determineCRS() string {
+	if rpcGeoreferenced(gtr.metadata) {
+		return "EPSG:4326"
+	}
+
 	// Check for ProjectedCSTypeGeoKey first
 	if projCSType, ok := gtr.metadata.GeoKeys[ProjectedCSTypeGeoKey]; ok {
 		if code, ok := projCSType.(uint16); ok && code != 0 {
+			if code == userDefined {
+				return ""
+			}
 			return fmt.Sprintf("EPSG:%d", code)
 		}
 	}
 
+	// The geographic code of a projected CRS is only its base
+	if modelType, ok := gtr.metadata.GeoKeys[GTModelTypeGeoKey].(uint16); ok && modelType == GTModelTypeProjected {
+		return ""
+	}
+
 	// Check for GeographicTypeGeoKey
 	if geoType, ok := gtr.metadata.GeoKeys[GeographicTypeGeoKey]; ok {
-		if code, ok := geoType.(uint16); ok && code != 0 {
-			// Common geographic codes
-			if code == 4326 {
-				return "EPSG:4326"
-			}
+		if code, ok := geoType.(uint16); ok && code != 0 && code != userDefined {
 			return fmt.Sprintf("EPSG:%d", code)
 		}
 	}
@@ -429,49 +590,13 @@ func (gtr *GeoTIFFReader) determineCRS() string {
 
 // pixelToGeo converts pixel coordinates to geographic coordinates
 func (gtr *GeoTIFFReader) pixelToGeo(pixelX, pixelY float64) (float64, float64) {
-	// Use ModelTransformation if available (affine transformation matrix)
-	if gtr.hasTransformation() {
-		return gtr.transformPixel(pixelX, pixelY)
-	}
-
-	// Use ModelTiepoint and ModelPixelScale
-	if len(gtr.metadata.TiePoints) > 0 && gtr.metadata.PixelScale[0] != 0 {
-		tp := gtr.metadata.TiePoints[0]
-
-		geoX := tp.GeoX + (pixelX-tp.PixelX)*gtr.metadata.PixelScale[0]
-		geoY := tp.GeoY - (pixelY-tp.PixelY)*gtr.metadata.PixelScale[1] // Note: Y is inverted
-
-		return geoX, geoY
-	}
-
-	return 0, 0
-}
-
-// hasTransformation checks if ModelTransformation is available
-func (gtr *GeoTIFFReader) hasTransformation() bool {
-	// Check if transformation matrix is non-zero
-	for i := 0; i < 16; i++ {
-		if gtr.metadata.Transformation[i] != 0 {
-			return true
+	if !gtr.hasGeoTransform {
+		if gtr.model != nil {
+			return gtr.modelPixelToGeo(pixelX, pixelY)
 		}
+		return 0, 0
 	}
-	return false
-}
-
-// transformPixel transforms pixel coordinates using the transformation matrix
-func (gtr *GeoTIFFReader) transformPixel(pixelX, pixelY float64) (float64, float64) {
-	t := gtr.metadata.Transformation
-
-	// Transformation matrix format:
-	// [0] [1] [2] [3]
-	// [4] [5] [6] [7]
-	// [8] [9] [10] [11]
-	// [12] [13] [14] [15]
-
-	geoX := t[0]*pixelX + t[1]*pixelY + t[3]
-	geoY := t[4]*pixelX + t[5]*pixelY + t[7]
-
-	return geoX, geoY
+	return gtr.geoTransform.Apply(pixelX, pixelY)
 }
 
 // Bounds calculates the geographic bounding box
@@ -479,22 +604,7 @@ func (gtr *GeoTIFFReader) Bounds() orb.Bound {
 	if gtr.metadata.Width == 0 || gtr.metadata.Height == 0 {
 		return orb.Bound{}
 	}
-
-	// Get corners
-	topLeftX, topLeftY := gtr.pixelToGeo(0, 0)
-	topRightX, topRightY := gtr.pixelToGeo(float64(gtr.metadata.Width), 0)
-	bottomLeftX, bottomLeftY := gtr.pixelToGeo(0, float64(gtr.metadata.Height))
-	bottomRightX, bottomRightY := gtr.pixelToGeo(float64(gtr.metadata.Width), float64(gtr.metadata.Height))
-
-	minX := math.Min(math.Min(topLeftX, topRightX), math.Min(bottomLeftX, bottomRightX))
-	maxX := math.Max(math.Max(topLeftX, topRightX), math.Max(bottomLeftX, bottomRightX))
-	minY := math.Min(math.Min(topLeftY, topRightY), math.Min(bottomLeftY, bottomRightY))
-	maxY := math.Max(math.Max(topLeftY, topRightY), math.Max(bottomLeftY, bottomRightY))
-
-	return orb.Bound{
-		Min: orb.Point{minX, minY},
-		Max: orb.Point{maxX, maxY},
-	}
+	return gtr.pixelBounds(0, 0, float64(gtr.metadata.Width), float64(gtr.metadata.Height))
 }
 
 // GetMetadata returns the GeoTIFF metadata
@@ -0,0 +1,90 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGDALNoData(t *testing.T) {
+	tests := []struct {
+		noData string
+		want   float64
+	}{
+		{"0", 0},
+		{"-9999", -9999},
+		{"nan", math.NaN()},
+		{" 255 ", 255},
+	}
+
+	for _, tt := range tests {
+		raster := testRaster{Width: 4, Height: 4, EPSG: 4326, PixelSize: [2]float64{0.1, 0.1}, NoData: tt.noData}
+		for name, c := range map[string]*COG{"Read": raster.cog(t), "Open": raster.open(t)} {
+			noData, ok := c.NoData()
+			if !ok {
+				t.Errorf("%s(%q): expected NoData to be set", name, tt.noData)
+				continue
+			}
+			if noData != tt.want && !(math.IsNaN(noData) && math.IsNaN(tt.want)) {
+				t.Errorf("%s(%q): expected NoData %v, got %v", name, tt.noData, tt.want, noData)
+			}
+		}
+	}
+
+	c := testRaster{Width: 4, Height: 4, EPSG: 4326}.cog(t)
+	if _, ok := c.NoData(); ok {
+		t.Error("expected no NoData for a file without GDAL_NODATA")
+	}
+}
+
+func TestRasterDataFloat(t *testing.T) {
+	tests := []struct {
+		dataType DataType
+		value    float64
+	}{
+		{DTByte, 200},
+		{DTSByte, -100},
+		{DTSShort, 60000},
+		{DTSShortS, -1234},
+		{DTSLong, 4000000000},
+		{DTSLongS, -70000},
+		{DTFloat, -1.5},
+		{DTDouble, 3.25e10},
+	}
+
+	for _, tt := range tests {
+		raster := testRaster{
+			Width: 2, Height: 2, DataType: tt.dataType, EPSG: 4326, NoData: "7",
+			Value: func(band, x, y int) float64 {
+				if x == 1 && y == 1 {
+					return 7
+				}
+				return tt.value
+			},
+		}
+		data, err := raster.cog(t).ReadWindow(Rectangle{X: 0, Y: 0, Width: 2, Height: 2})
+		if err != nil {
+			t.Fatalf("ReadWindow failed for data type %d: %v", tt.dataType, err)
+		}
+		if got := data.Float(0, 0, 0); got != tt.value {
+			t.Errorf("data type %d: expected %v, got %v", tt.dataType, tt.value, got)
+		}
+		if data.IsNoData(0, 0, 0) || !data.IsNoData(0, 1, 1) {
+			t.Errorf("data type %d: NoData detection mismatch", tt.dataType)
+		}
+		if got := SampleToFloat64(Float64ToSample(tt.value, tt.dataType), tt.dataType); got != tt.value {
+			t.Errorf("data type %d: sample round trip gave %v, want %v", tt.dataType, got, tt.value)
+		}
+	}
+}
+
+func TestFloat64ToSampleClamps(t *testing.T) {
+	if got := Float64ToSample(300, DTByte); got != 255 {
+		t.Errorf("expected 255, got %d", got)
+	}
+	if got := SampleToFloat64(Float64ToSample(-40000, DTSShortS), DTSShortS); got != math.MinInt16 {
+		t.Errorf("expected %d, got %v", math.MinInt16, got)
+	}
+	if got := Float64ToSample(2.6, DTSShort); got != 3 {
+		t.Errorf("expected 3, got %d", got)
+	}
+}
@@ -0,0 +1,243 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+)
+
+// GeoTransform is a GDAL-style affine geotransform mapping pixel/line
+// coordinates (relative to the top-left corner of the image) to georeferenced
+// coordinates:
+//
+//	geoX = GT[0] + pixelX*GT[1] + pixelY*GT[2]
+//	geoY = GT[3] + pixelX*GT[4] + pixelY*GT[5]
+//
+// GT[2] and GT[4] are zero for north-up images; non-zero values describe
+// rotation or shear.
+type GeoTransform [6]float64
+
+// Apply converts pixel coordinates to georeferenced coordinates
+func (gt GeoTransform) Apply(pixelX, pixelY float64) (float64, float64) {
+	return gt[0] + pixelX*gt[1] + pixelY*gt[2],
+		gt[3] + pixelX*gt[4] + pixelY*gt[5]
+}
+
+// Invert returns the geotransform mapping georeferenced coordinates back to
+// pixel coordinates. It fails if the transform is degenerate.
+func (gt GeoTransform) Invert() (GeoTransform, error) {
+	det := gt[1]*gt[5] - gt[2]*gt[4]
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return GeoTransform{}, fmt.Errorf("geotransform is not invertible")
+	}
+
+	inv := GeoTransform{
+		0,
+		gt[5] / det,
+		-gt[2] / det,
+		0,
+		-gt[4] / det,
+		gt[1] / det,
+	}
+	inv[0] = -(gt[0]*inv[1] + gt[3]*inv[2])
+	inv[3] = -(gt[0]*inv[4] + gt[3]*inv[5])
+	return inv, nil
+}
+
+// IsNorthUp reports whether the transform has no rotation or shear
+func (gt GeoTransform) IsNorthUp() bool {
+	return gt[2] == 0 && gt[4] == 0
+}
+
+// PixelSize returns the size of a pixel along the X and Y pixel axes in
+// georeferenced units (always positive)
+func (gt GeoTransform) PixelSize() (float64, float64) {
+	return math.Hypot(gt[1], gt[4]), math.Hypot(gt[2], gt[5])
+}
+
+// Scaled returns the transform for the same area sampled with pixels scaleX
+// and scaleY times larger, e.g. for an overview of the image
+func (gt GeoTransform) Scaled(scaleX, scaleY float64) GeoTransform {
+	return GeoTransform{gt[0], gt[1] * scaleX, gt[2] * scaleY, gt[3], gt[4] * scaleX, gt[5] * scaleY}
+}
+
+// Bound returns the georeferenced envelope of the pixel rectangle from
+// (minX, minY) to (maxX, maxY), accounting for rotation
+func (gt GeoTransform) Bound(minX, minY, maxX, maxY float64) orb.Bound {
+	x0, y0 := gt.Apply(minX, minY)
+	bound := orb.Bound{Min: orb.Point{x0, y0}, Max: orb.Point{x0, y0}}
+	for _, corner := range [][2]float64{{maxX, minY}, {minX, maxY}, {maxX, maxY}} {
+		x, y := gt.Apply(corner[0], corner[1])
+		bound = bound.Extend(orb.Point{x, y})
+	}
+	return bound
+}
+
+// geoTransformFromMetadata builds the geotransform from ModelTransformation
+// or from the first tie point and ModelPixelScale. For PixelIsPoint rasters
+// the model coordinates refer to pixel centres, so the origin is shifted by
+// half a pixel to keep the transform corner-based, as GDAL does.
+func geoTransformFromMetadata(meta *GeoTIFFMetadata) (GeoTransform, bool) {
+	gt, ok := modelGeoTransform(meta)
+	if ok && meta.RasterType == GTRasterTypePixelIsPoint {
+		gt[0] -= 0.5*gt[1] + 0.5*gt[2]
+		gt[3] -= 0.5*gt[4] + 0.5*gt[5]
+	}
+	return gt, ok
+}
+
+// modelGeoTransform returns the transform described by the model tags as-is
+func modelGeoTransform(meta *GeoTIFFMetadata) (GeoTransform, bool) {
+	t := meta.Transformation
+	for _, v := range t {
+		if v != 0 {
+			// Transformation matrix format:
+			// [0] [1] [2] [3]
+			// [4] [5] [6] [7]
+			// [8] [9] [10] [11]
+			// [12] [13] [14] [15]
+			return GeoTransform{t[3], t[0], t[1], t[7], t[4], t[5]}, true
+		}
+	}
+
+	if len(meta.TiePoints) > 0 && meta.PixelScale[0] != 0 {
+		tp := meta.TiePoints[0]
+		scaleX, scaleY := meta.PixelScale[0], meta.PixelScale[1]
+		return GeoTransform{
+			tp.GeoX - tp.PixelX*scaleX, scaleX, 0,
+			tp.GeoY + tp.PixelY*scaleY, 0, -scaleY, // Note: Y is inverted
+		}, true
+	}
+
+	return GeoTransform{}, false
+}
+
+// setGeoTransform sets the forward transform and precomputes its inverse
+func (gtr *GeoTIFFReader) setGeoTransform(gt GeoTransform) {
+	inverse, err := gt.Invert()
+	if err != nil {
+		gtr.hasGeoTransform = false
+		return
+	}
+	gtr.geoTransform = gt
+	gtr.inverseGeoTransform = inverse
+	gtr.hasGeoTransform = true
+}
+
+// pixelModel maps between full-resolution pixel and georeferenced
+// coordinates of an image georeferenced without a geotransform, by GCPs or
+// RPCs
+type pixelModel interface {
+	PixelToGeo(pixelX, pixelY float64) (float64, float64)
+	GeoToPixel(geoX, geoY float64) (float64, float64)
+	Affine() GeoTransform // Least-squares affine approximation
+}
+
+// modelPixelToGeo converts pixel coordinates of the IFD with the pixel model
+func (gtr *GeoTIFFReader) modelPixelToGeo(pixelX, pixelY float64) (float64, float64) {
+	return gtr.model.PixelToGeo(pixelX*gtr.modelScale[0], pixelY*gtr.modelScale[1])
+}
+
+// modelGeoToPixel converts georeferenced coordinates to pixel coordinates of
+// the IFD with the pixel model
+func (gtr *GeoTIFFReader) modelGeoToPixel(geoX, geoY float64) (float64, float64) {
+	x, y := gtr.model.GeoToPixel(geoX, geoY)
+	return x / gtr.modelScale[0], y / gtr.modelScale[1]
+}
+
+// modelBounds returns the envelope of a pixel rectangle, sampling its edges
+// as they may be curved. Points the model cannot locate are skipped.
+func (gtr *GeoTIFFReader) modelBounds(minX, minY, maxX, maxY float64) orb.Bound {
+	return transformBound(orb.Bound{Min: orb.Point{minX, minY}, Max: orb.Point{maxX, maxY}}, gtr.modelPixelToGeo)
+}
+
+// GeoTransform returns the affine geotransform of the image and whether the
+// image is georeferenced with one
+func (gtr *GeoTIFFReader) GeoTransform() (GeoTransform, bool) {
+	return gtr.geoTransform, gtr.hasGeoTransform
+}
+
+// georeferenced reports whether the IFD has a geotransform, GCPs or RPCs
+func (gtr *GeoTIFFReader) georeferenced() bool {
+	return gtr.hasGeoTransform || gtr.model != nil
+}
+
+// approximateGeoTransform returns the geotransform, or for GCP and RPC
+// georeferencing an affine approximation, for estimating pixel sizes
+func (gtr *GeoTIFFReader) approximateGeoTransform() (GeoTransform, bool) {
+	if gtr.hasGeoTransform {
+		return gtr.geoTransform, true
+	}
+	if gtr.model != nil {
+		return gtr.model.Affine().Scaled(gtr.modelScale[0], gtr.modelScale[1]), true
+	}
+	return GeoTransform{}, false
+}
+
+// geoToPixel converts georeferenced coordinates to (fractional) pixel coordinates
+func (gtr *GeoTIFFReader) geoToPixel(geoX, geoY float64) (float64, float64) {
+	if !gtr.hasGeoTransform {
+		if gtr.model != nil {
+			return gtr.modelGeoToPixel(geoX, geoY)
+		}
+		return 0, 0
+	}
+	return gtr.inverseGeoTransform.Apply(geoX, geoY)
+}
+
+// pixelBounds returns the geographic envelope of a pixel rectangle
+func (gtr *GeoTIFFReader) pixelBounds(minX, minY, maxX, maxY float64) orb.Bound {
+	if !gtr.hasGeoTransform {
+		if gtr.model != nil {
+			return gtr.modelBounds(minX, minY, maxX, maxY)
+		}
+		return orb.Bound{}
+	}
+	return gtr.geoTransform.Bound(minX, minY, maxX, maxY)
+}
+
+// GeoTransform returns the affine geotransform of the given overview level
+// (0 = main image) and whether one is available
+func (c *COG) GeoTransform(overview int) (GeoTransform, bool) {
+	if overview < 0 || overview >= len(c.geoTIFFs) {
+		return GeoTransform{}, false
+	}
+	return c.geoTIFFs[overview].GeoTransform()
+}
+
+// PixelIsPoint reports whether the image uses the PixelIsPoint raster type,
+// where georeferenced coordinates refer to pixel centres (common for DEMs).
+// Bounds and pixel conversions already account for it: the geotransform
+// always describes pixel corners, matching GDAL.
+func (c *COG) PixelIsPoint() bool {
+	return len(c.metadata) > 0 && c.metadata[0].RasterType == GTRasterTypePixelIsPoint
+}
+
+// deriveOverviewGeoTransforms georeferences overview IFDs that carry no
+// georeferencing tags of their own (as is usual in COGs) from the main image
+func (c *COG) deriveOverviewGeoTransforms() {
+	if len(c.geoTIFFs) == 0 {
+		return
+	}
+	main := c.geoTIFFs[0]
+	if !main.georeferenced() {
+		return
+	}
+	mainMeta := c.metadata[0]
+
+	for i := 1; i < len(c.geoTIFFs); i++ {
+		gtr := c.geoTIFFs[i]
+		meta := c.metadata[i]
+		if gtr.georeferenced() || meta.Width == 0 || meta.Height == 0 {
+			continue
+		}
+		scaleX := float64(mainMeta.Width) / float64(meta.Width)
+		scaleY := float64(mainMeta.Height) / float64(meta.Height)
+		if main.model != nil {
+			gtr.model, gtr.modelScale = main.model, [2]float64{scaleX, scaleY}
+			continue
+		}
+		gtr.setGeoTransform(main.geoTransform.Scaled(scaleX, scaleY))
+	}
+}
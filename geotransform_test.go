@@ -0,0 +1,121 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func TestGeoTransformInvert(t *testing.T) {
+	// 30 degree rotation with 10m pixels
+	sin, cos := math.Sincos(math.Pi / 6)
+	gt := GeoTransform{500000, 10 * cos, 10 * sin, 4000000, 10 * sin, -10 * cos}
+
+	inv, err := gt.Invert()
+	if err != nil {
+		t.Fatalf("Invert failed: %v", err)
+	}
+	x, y := gt.Apply(12.5, 7.25)
+	px, py := inv.Apply(x, y)
+	if math.Abs(px-12.5) > 1e-9 || math.Abs(py-7.25) > 1e-9 {
+		t.Errorf("round trip gave (%v, %v), want (12.5, 7.25)", px, py)
+	}
+	if gt.IsNorthUp() {
+		t.Error("rotated transform reported as north-up")
+	}
+	if sx, sy := gt.PixelSize(); math.Abs(sx-10) > 1e-9 || math.Abs(sy-10) > 1e-9 {
+		t.Errorf("expected 10m pixels, got %v x %v", sx, sy)
+	}
+
+	if _, err := (GeoTransform{0, 1, 1, 0, 1, 1}).Invert(); err == nil {
+		t.Error("expected error for degenerate transform")
+	}
+}
+
+func TestRotatedGeoreferencing(t *testing.T) {
+	// Rotated 90 degrees: pixel X runs south, pixel Y runs east
+	tr := testRaster{
+		Width: 20, Height: 10, TileSize: 16, Overviews: 1, EPSG: 32633,
+		Transform: GeoTransform{1000, 0, 2, 5000, -2, 0},
+		Value:     func(band, x, y int) float64 { return float64(y*20 + x) },
+	}
+	c := tr.cog(t)
+
+	gt, ok := c.GeoTransform(0)
+	if !ok || gt != tr.Transform {
+		t.Fatalf("expected geotransform %v, got %v (%v)", tr.Transform, gt, ok)
+	}
+
+	want := orb.Bound{Min: orb.Point{1000, 4960}, Max: orb.Point{1020, 5000}}
+	if b := c.Bounds(); b != want {
+		t.Errorf("expected bounds %v, got %v", want, b)
+	}
+
+	p := c.PointFromPixel(3, 4, 0)
+	if p != (orb.Point{1008, 4994}) {
+		t.Errorf("unexpected point %v", p)
+	}
+	if x, y := c.PixelFromPoint(orb.Point{1009, 4993}, 0); x != 3 || y != 4 {
+		t.Errorf("expected pixel (3, 4), got (%d, %d)", x, y)
+	}
+
+	// Overviews are georeferenced from the main image
+	if gt, ok := c.GeoTransform(1); !ok || gt != (GeoTransform{1000, 0, 4, 5000, -4, 0}) {
+		t.Errorf("unexpected overview geotransform %v", gt)
+	}
+	if x, y := c.PixelFromPoint(orb.Point{1009, 4993}, 1); x != 1 || y != 2 {
+		t.Errorf("expected overview pixel (1, 2), got (%d, %d)", x, y)
+	}
+
+	// Region covering pixels x 5..9, y 2..5
+	region, err := c.ReadRegion(orb.Bound{Min: orb.Point{1004, 4980}, Max: orb.Point{1012, 4990}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	if region.At(0, 0, 0) != 2*20+5 {
+		t.Errorf("expected region to start at pixel (5, 2), got value %d", region.At(0, 0, 0))
+	}
+}
+
+func TestPixelIsPoint(t *testing.T) {
+	tr := testRaster{
+		Width: 4, Height: 4, DataType: DTSShortS, EPSG: 4326, Overviews: 1,
+		Origin: [2]float64{100, 200}, PixelSize: [2]float64{1, 1}, RasterType: GTRasterTypePixelIsPoint,
+		Value: func(band, x, y int) float64 { return float64(y*4 + x) },
+	}
+	c := tr.cog(t)
+
+	if !c.PixelIsPoint() {
+		t.Fatal("expected PixelIsPoint raster type")
+	}
+
+	// The tie point refers to the centre of pixel (0, 0)
+	want := orb.Bound{Min: orb.Point{99.5, 196.5}, Max: orb.Point{103.5, 200.5}}
+	if b := c.Bounds(); b != want {
+		t.Errorf("expected bounds %v, got %v", want, b)
+	}
+	if p := c.PointFromPixel(1, 1, 0); p != (orb.Point{100.5, 199.5}) {
+		t.Errorf("unexpected corner of pixel (1, 1): %v", p)
+	}
+	if x, y := c.PixelFromPoint(orb.Point{101, 199}, 0); x != 1 || y != 1 {
+		t.Errorf("expected centre of pixel (1, 1), got (%d, %d)", x, y)
+	}
+	if x, y := c.PixelFromPoint(orb.Point{100.4, 200.4}, 0); x != 0 || y != 0 {
+		t.Errorf("expected pixel (0, 0), got (%d, %d)", x, y)
+	}
+
+	region, err := c.ReadRegion(orb.Bound{Min: orb.Point{101.5, 196.5}, Max: orb.Point{103.5, 198.5}}, 0)
+	if err != nil {
+		t.Fatalf("ReadRegion failed: %v", err)
+	}
+	if got := region.At(0, 0, 0); got != 2*4+2 {
+		t.Errorf("expected region to start at pixel (2, 2), got value %d", got)
+	}
+
+	area := tr
+	area.RasterType = GTRasterTypePixelIsArea
+	if c := area.cog(t); c.PixelIsPoint() || c.Bounds().Min != (orb.Point{100, 196}) {
+		t.Errorf("unexpected PixelIsArea georeferencing: %v", c.Bounds())
+	}
+}
@@ -0,0 +1,106 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+)
+
+// DefaultOversamplingThreshold is the default for SetOversamplingThreshold:
+// an overview may be used when its pixels are up to 20% larger than the
+// requested output pixels.
+const DefaultOversamplingThreshold = 1.2
+
+// SetOversamplingThreshold controls overview selection for reads with a
+// target resolution (ReadTile, ReadWindowSized, ReadRegionAtResolution). The
+// coarsest overview whose pixels are at most threshold times the size of the
+// output pixels is used. 1 never reads an overview coarser than the output;
+// larger values trade quality for speed. A threshold of 0 or less always
+// reads the full-resolution image.
+func (c *COG) SetOversamplingThreshold(threshold float64) {
+	c.oversamplingThreshold = threshold
+}
+
+// OversamplingThreshold returns the threshold set by SetOversamplingThreshold
+func (c *COG) OversamplingThreshold() float64 {
+	return c.oversamplingThreshold
+}
+
+// overviewFactor returns how many full-resolution pixels span one pixel of
+// the IFD horizontally
+func (c *COG) overviewFactor(ifdIndex int) float64 {
+	return float64(c.metadata[0].Width) / float64(c.metadata[ifdIndex].Width)
+}
+
+// bestOverview returns the coarsest IFD whose pixels are no larger than
+// factor full-resolution pixels, within the oversampling threshold
+func (c *COG) bestOverview(factor float64) int {
+	if c.oversamplingThreshold <= 0 || factor <= 0 || math.IsNaN(factor) {
+		return 0
+	}
+
+	best, bestFactor := 0, 1.0
+	for i := 1; i < len(c.metadata); i++ {
+		if c.metadata[i].Width == 0 || c.metadata[i].Height == 0 {
+			continue
+		}
+		ovFactor := c.overviewFactor(i)
+		if ovFactor <= factor*c.oversamplingThreshold && ovFactor > bestFactor {
+			best, bestFactor = i, ovFactor
+		}
+	}
+	return best
+}
+
+// mappingFactor estimates how many full-resolution pixels one output pixel
+// spans, from the mapping's local scale at a grid of sample points. The
+// smallest value is returned, so no part of the output is undersampled.
+// It returns 0 if no sample point maps to the image.
+func mappingFactor(mapping pixelMapping, width, height int) float64 {
+	const samples = 8
+
+	factor := math.Inf(1)
+	for j := 0; j <= samples; j++ {
+		for i := 0; i <= samples; i++ {
+			x := float64(width) * float64(i) / samples
+			y := float64(height) * float64(j) / samples
+			x0, y0, ok0 := mapping(x, y)
+			x1, y1, ok1 := mapping(x+1, y)
+			x2, y2, ok2 := mapping(x, y+1)
+			if !ok0 || !ok1 || !ok2 {
+				continue
+			}
+			scale := math.Min(math.Hypot(x1-x0, y1-y0), math.Hypot(x2-x0, y2-y0))
+			if scale > 0 && !math.IsNaN(scale) {
+				factor = math.Min(factor, scale)
+			}
+		}
+	}
+
+	if math.IsInf(factor, 1) {
+		return 0
+	}
+	return factor
+}
+
+// ReadRegionAtResolution reads a geographic region at a target resolution,
+// given in CRS units per pixel. The coarsest overview meeting the resolution
+// (see SetOversamplingThreshold) is read; RasterData.Overview reports which.
+func (c *COG) ReadRegionAtResolution(bound orb.Bound, resolution float64) (*RasterData, error) {
+	if len(c.geoTIFFs) == 0 {
+		return nil, fmt.Errorf("no image data available")
+	}
+	if resolution <= 0 {
+		return nil, fmt.Errorf("resolution must be positive")
+	}
+
+	gt, ok := c.geoTIFFs[0].approximateGeoTransform()
+	if !ok {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+	pixelWidth, pixelHeight := gt.PixelSize()
+	factor := resolution / math.Max(pixelWidth, pixelHeight)
+
+	return c.ReadRegion(bound, c.bestOverview(factor))
+}
@@ -0,0 +1,159 @@
+package gocog
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog/proj"
+)
+
+// mercatorTileRaster covers the given Web Mercator tile with size x size
+// pixels whose values encode the full-resolution column
+func mercatorTileRaster(tile maptile.Tile, size, overviews int) testRaster {
+	bounds := projectBound(tile.Bound(), proj.WebMercator)
+	pixel := (bounds.Max[0] - bounds.Min[0]) / float64(size)
+	return testRaster{
+		Width: size, Height: size, DataType: DTSShort, TileSize: 64, Overviews: overviews, EPSG: 3857,
+		Origin: [2]float64{bounds.Min[0], bounds.Max[1]}, PixelSize: [2]float64{pixel, pixel},
+		Value: func(band, x, y int) float64 { return float64(x) },
+	}
+}
+
+func TestBestOverview(t *testing.T) {
+	c := testRaster{Width: 64, Height: 64, TileSize: 16, Overviews: 3, EPSG: 4326,
+		Origin: [2]float64{0, 1}, PixelSize: [2]float64{1.0 / 64, 1.0 / 64}}.cog(t)
+
+	tests := []struct {
+		factor    float64
+		threshold float64
+		want      int
+	}{
+		{factor: 0.5, threshold: 1.2, want: 0},
+		{factor: 1, threshold: 1.2, want: 0},
+		{factor: 2, threshold: 1.2, want: 1},
+		{factor: 3, threshold: 1.2, want: 1},
+		{factor: 3.5, threshold: 1.2, want: 2},
+		{factor: 3.5, threshold: 1, want: 1},
+		{factor: 100, threshold: 1, want: 3},
+		{factor: 100, threshold: 0, want: 0},
+	}
+	for _, tt := range tests {
+		c.SetOversamplingThreshold(tt.threshold)
+		if got := c.bestOverview(tt.factor); got != tt.want {
+			t.Errorf("bestOverview(%v) with threshold %v = %d, want %d", tt.factor, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestReadTileSelectsOverview(t *testing.T) {
+	tile := maptile.New(8, 5, 4)
+	c := mercatorTileRaster(tile, 512, 2).cog(t)
+	if c.OversamplingThreshold() != DefaultOversamplingThreshold {
+		t.Errorf("expected default threshold %v, got %v", DefaultOversamplingThreshold, c.OversamplingThreshold())
+	}
+
+	full, err := c.ReadTile(tile, 512)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if full.Overview != 0 {
+		t.Errorf("expected a 512 pixel tile to read the main image, got overview %d", full.Overview)
+	}
+
+	half, err := c.ReadTile(tile, 256)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if half.Overview != 1 {
+		t.Fatalf("expected a 256 pixel tile to read overview 1, got %d", half.Overview)
+	}
+	for _, x := range []int{0, 1, 100, 255} {
+		if got := half.At(0, x, 10); got != uint64(2*x) {
+			t.Errorf("pixel %d: expected %d, got %d", x, 2*x, got)
+		}
+	}
+
+	// The parent tile needs a quarter of the resolution
+	parent, err := c.ReadTile(tile.Parent(), 256)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if parent.Overview != 2 {
+		t.Errorf("expected the parent tile to read overview 2, got %d", parent.Overview)
+	}
+
+	c.SetOversamplingThreshold(0)
+	parent, err = c.ReadTile(tile.Parent(), 256)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if parent.Overview != 0 {
+		t.Errorf("expected overviews to be disabled, got overview %d", parent.Overview)
+	}
+}
+
+func TestReadRegionAtResolution(t *testing.T) {
+	tr := mercatorTileRaster(maptile.New(8, 5, 4), 512, 2)
+	c := tr.cog(t)
+
+	data, err := c.ReadRegionAtResolution(c.Bounds(), 4*tr.PixelSize[0])
+	if err != nil {
+		t.Fatalf("ReadRegionAtResolution failed: %v", err)
+	}
+	if data.Overview != 2 {
+		t.Errorf("expected overview 2, got %d", data.Overview)
+	}
+	if data.Width > 128 || data.Width < 127 {
+		t.Errorf("expected about 128 pixels across, got %d", data.Width)
+	}
+
+	if _, err := c.ReadRegionAtResolution(c.Bounds(), 0); err == nil {
+		t.Error("expected error for zero resolution")
+	}
+}
+
+func TestReadWindowSized(t *testing.T) {
+	tr := mercatorTileRaster(maptile.New(8, 5, 4), 512, 2)
+	c := tr.cog(t)
+
+	rect := Rectangle{X: 64, Y: 32, Width: 256, Height: 128}
+	data, err := c.ReadWindowSized(rect, 64, 32)
+	if err != nil {
+		t.Fatalf("ReadWindowSized failed: %v", err)
+	}
+	if data.Width != 64 || data.Height != 32 {
+		t.Fatalf("expected 64x32, got %dx%d", data.Width, data.Height)
+	}
+	if data.Overview != 2 {
+		t.Errorf("expected overview 2 for a 4x reduction, got %d", data.Overview)
+	}
+	// Output pixel i is centred on full-resolution column 66+4i, which is
+	// column 16+i of overview 2 and holds its top-left sample 64+4i
+	for _, x := range []int{0, 1, 63} {
+		if got := data.At(0, x, 5); got != uint64(64+4*x) {
+			t.Errorf("pixel %d: expected %d, got %d", x, 64+4*x, got)
+		}
+	}
+
+	gt, _ := c.GeoTransform(0)
+	want := gt.Bound(64, 32, 320, 160)
+	if data.Bounds != want {
+		t.Errorf("expected bounds %v, got %v", want, data.Bounds)
+	}
+
+	// Sizes that are not a whole fraction of the window are honoured exactly
+	data, err = c.ReadWindowSized(rect, 300, 77)
+	if err != nil {
+		t.Fatalf("ReadWindowSized failed: %v", err)
+	}
+	if data.Width != 300 || data.Height != 77 || data.Overview != 0 {
+		t.Errorf("expected 300x77 from overview 0, got %dx%d from overview %d", data.Width, data.Height, data.Overview)
+	}
+
+	if _, err := c.ReadWindowSized(Rectangle{X: 400, Y: 0, Width: 200, Height: 10}, 10, 10); err == nil {
+		t.Error("expected error for a window beyond the image")
+	}
+	if _, err := c.ReadWindowSized(rect, 0, 10); err == nil {
+		t.Error("expected error for zero output width")
+	}
+}
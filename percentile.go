@@ -0,0 +1,171 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Percentiles and histogram equalisation, typically used to derive display
+// stretches. The COG variants work from the lowest-resolution overview so
+// that every tile of a dataset can be rendered with the same parameters.
+
+// Percentiles returns the given percentiles (0-100) of the valid values of a
+// band (0-based), using linear interpolation between closest ranks. Values
+// are numeric sample values as returned by Float. All results are NaN if the
+// band has no valid values.
+func (r *RasterData) Percentiles(band int, percentiles ...float64) ([]float64, error) {
+	if band < 0 || band >= r.Bands {
+		return nil, fmt.Errorf("band %d out of range (raster has %d bands)", band, r.Bands)
+	}
+	return percentilesOf(r.validValues(band, 1, 0), percentiles), nil
+}
+
+// Percentiles returns the given percentiles (0-100) of the valid values of a
+// band (0-based), computed from the lowest-resolution overview. Values are in
+// physical units when the band has a scale and offset.
+func (c *COG) Percentiles(band int, percentiles ...float64) ([]float64, error) {
+	data, err := c.readLowestOverview(band)
+	if err != nil {
+		return nil, err
+	}
+	scale, offset := c.ScaleOffset(band)
+	return percentilesOf(data.validValues(band, scale, offset), percentiles), nil
+}
+
+// Equalization maps values to 0..1 by the cumulative distribution of a
+// histogram, so that each output level is equally populated.
+type Equalization struct {
+	Histogram *Histogram
+	cdf       []float64 // cdf[i] is the fraction of values in buckets < i
+}
+
+// NewEqualization builds a histogram equalisation transform from h
+func NewEqualization(h *Histogram) *Equalization {
+	e := &Equalization{Histogram: h, cdf: make([]float64, len(h.Counts)+1)}
+	total := float64(h.Total())
+	if total == 0 {
+		return e
+	}
+	var cumulative int64
+	for i, count := range h.Counts {
+		cumulative += count
+		e.cdf[i+1] = float64(cumulative) / total
+	}
+	return e
+}
+
+// Apply maps v to 0..1, interpolating linearly within histogram buckets.
+// Values below the histogram map to 0 and values above it to 1.
+func (e *Equalization) Apply(v float64) float64 {
+	h := e.Histogram
+	if len(h.Counts) == 0 || math.IsNaN(v) {
+		return math.NaN()
+	}
+	if v <= h.Min {
+		return 0
+	}
+	if v >= h.Max {
+		return 1
+	}
+	pos := (v - h.Min) / (h.Max - h.Min) * float64(len(h.Counts))
+	i := int(pos)
+	if i >= len(h.Counts) {
+		i = len(h.Counts) - 1
+	}
+	frac := pos - float64(i)
+	return e.cdf[i] + (e.cdf[i+1]-e.cdf[i])*frac
+}
+
+// Equalization builds a histogram equalisation transform for a band (0-based)
+// from a histogram with the given number of buckets (0 for the default).
+func (r *RasterData) Equalization(band, buckets int) (*Equalization, error) {
+	stats, err := r.Statistics(StatisticsOptions{Bands: []int{band}, HistogramBuckets: buckets})
+	if err != nil {
+		return nil, err
+	}
+	return equalizationFrom(stats[0])
+}
+
+// Equalization builds a histogram equalisation transform for a band (0-based)
+// from the lowest-resolution overview, in physical units when the band has a
+// scale and offset.
+func (c *COG) Equalization(band, buckets int) (*Equalization, error) {
+	data, err := c.readLowestOverview(band)
+	if err != nil {
+		return nil, err
+	}
+	scale, offset := c.ScaleOffset(band)
+	bands := []statsBand{{band: band, scale: scale, offset: offset}}
+	stats, err := computeStatistics(bands, StatisticsOptions{HistogramBuckets: buckets}, func(visit func(data *RasterData)) error {
+		visit(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return equalizationFrom(stats[0])
+}
+
+// equalizationFrom builds an equalisation from band statistics
+func equalizationFrom(stats BandStatistics) (*Equalization, error) {
+	if stats.Histogram == nil {
+		return nil, fmt.Errorf("band %d has no valid values", stats.Band)
+	}
+	return NewEqualization(stats.Histogram), nil
+}
+
+// readLowestOverview reads the whole lowest-resolution IFD after checking band
+func (c *COG) readLowestOverview(band int) (*RasterData, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image metadata")
+	}
+	if band < 0 || band >= c.BandCount() {
+		return nil, fmt.Errorf("band %d out of range (image has %d bands)", band, c.BandCount())
+	}
+
+	level := len(c.metadata) - 1
+	meta := c.metadata[level]
+	data, err := c.readRaster(level, 0, 0, meta.Width, meta.Height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overview %d: %w", level, err)
+	}
+	return data, nil
+}
+
+// validValues returns the valid values of a band, converted with scale and offset
+func (r *RasterData) validValues(band int, scale, offset float64) []float64 {
+	values := make([]float64, 0, r.Width*r.Height)
+	for i := band; i < len(r.Data); i += r.Bands {
+		v := SampleToFloat64(r.Data[i], r.DataType)
+		if r.masked(i/r.Bands) || r.isNoDataValue(v) {
+			continue
+		}
+		values = append(values, v*scale+offset)
+	}
+	return values
+}
+
+// percentilesOf sorts values and returns the requested percentiles
+func percentilesOf(values []float64, percentiles []float64) []float64 {
+	sort.Float64s(values)
+	result := make([]float64, len(percentiles))
+	for i, p := range percentiles {
+		result[i] = percentile(values, p)
+	}
+	return result
+}
+
+// percentile returns the p-th percentile (0-100) of sorted values using
+// linear interpolation between closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	p = math.Max(0, math.Min(100, p))
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	frac := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*frac
+}
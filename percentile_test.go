@@ -0,0 +1,81 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRasterDataPercentiles(t *testing.T) {
+	data := &RasterData{Width: 12, Height: 1, Bands: 1, DataType: DTSShortS, NoData: -1, HasNoData: true}
+	for _, v := range []float64{-1, 0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100} {
+		data.Data = append(data.Data, Float64ToSample(v, DTSShortS))
+	}
+
+	p, err := data.Percentiles(0, 0, 2, 50, 98, 100)
+	if err != nil {
+		t.Fatalf("Percentiles failed: %v", err)
+	}
+	want := []float64{0, 2, 50, 98, 100}
+	for i := range want {
+		if math.Abs(p[i]-want[i]) > 1e-9 {
+			t.Errorf("percentile %d: expected %v, got %v", i, want[i], p[i])
+		}
+	}
+
+	if _, err := data.Percentiles(1, 50); err == nil {
+		t.Error("expected error for band out of range")
+	}
+}
+
+func TestEqualization(t *testing.T) {
+	eq := NewEqualization(&Histogram{Min: 0, Max: 4, Counts: []int64{6, 0, 1, 1}})
+
+	tests := []struct{ v, want float64 }{
+		{-1, 0}, {0, 0}, {0.5, 0.375}, {1, 0.75}, {2, 0.75}, {3, 0.875}, {4, 1}, {10, 1},
+	}
+	for _, tt := range tests {
+		if got := eq.Apply(tt.v); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Apply(%v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+
+	empty := &RasterData{Data: []uint64{0}, Width: 1, Height: 1, Bands: 1, DataType: DTByte, HasNoData: true}
+	if _, err := empty.Equalization(0, 0); err == nil {
+		t.Error("expected error for band without valid values")
+	}
+}
+
+func TestCOGPercentilesUseLowestOverview(t *testing.T) {
+	tr := testRaster{
+		Width: 40, Height: 8, DataType: DTSShort, TileSize: 16, Overviews: 2, NoData: "0",
+		EPSG: 4326, Origin: [2]float64{0, 8},
+		ExtraTags: []testTag{asciiTag(TagGDALMetadata, `<GDALMetadata><Item name="SCALE" sample="0" role="scale">2</Item></GDALMetadata>`)},
+		Value: func(band, x, y int) float64 {
+			if x%4 != 0 {
+				return 999 // Not sampled by the 4x overview
+			}
+			return float64(x)
+		},
+	}
+	c := tr.cog(t)
+
+	// Overview 2 holds x = 0 (NoData), 4, ..., 36, scaled by 2
+	p, err := c.Percentiles(0, 0, 100)
+	if err != nil {
+		t.Fatalf("Percentiles failed: %v", err)
+	}
+	if p[0] != 8 || p[1] != 72 {
+		t.Errorf("expected percentiles 8 and 72, got %v", p)
+	}
+
+	eq, err := c.Equalization(0, 9)
+	if err != nil {
+		t.Fatalf("Equalization failed: %v", err)
+	}
+	if eq.Histogram.Min != 8 || eq.Histogram.Max != 72 || eq.Histogram.Total() != 18 {
+		t.Errorf("unexpected equalization histogram %+v", eq.Histogram)
+	}
+	if got := eq.Apply(40); math.Abs(got-0.5) > 0.1 {
+		t.Errorf("expected median to map near 0.5, got %v", got)
+	}
+}
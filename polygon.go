@@ -0,0 +1,222 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+)
+
+// PolygonOptions controls how ReadPolygon selects pixels
+type PolygonOptions struct {
+	// AllTouched includes every pixel the polygon touches. By default only
+	// pixels whose centre lies inside the polygon are included.
+	AllTouched bool
+
+	// LonLat means the polygon is in longitude/latitude and is projected
+	// into the image's CRS. By default it is in the image's CRS.
+	LonLat bool
+
+	// Overview is the overview level to read (0 = main image)
+	Overview int
+}
+
+// ReadPolygon reads the window of the image covering an orb.Polygon or
+// orb.MultiPolygon and masks the pixels outside it. Holes are honoured.
+// Masked pixels are filled with the NoData value (or zero) and reported by
+// IsNoData, so statistics and band math skip them.
+func (c *COG) ReadPolygon(geom orb.Geometry, opts PolygonOptions) (*RasterData, error) {
+	if len(c.geoTIFFs) == 0 {
+		return nil, fmt.Errorf("no image data available")
+	}
+	if opts.Overview < 0 || opts.Overview >= len(c.geoTIFFs) {
+		return nil, fmt.Errorf("invalid overview level: %d", opts.Overview)
+	}
+	gtr := c.geoTIFFs[opts.Overview]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+	meta := c.metadata[opts.Overview]
+
+	polygons, err := multiPolygon(geom)
+	if err != nil {
+		return nil, err
+	}
+	transform := gtr.geoToPixel
+	if opts.LonLat {
+		projection, err := c.Projection()
+		if err != nil {
+			return nil, fmt.Errorf("unsupported CRS: %w", err)
+		}
+		transform = func(lon, lat float64) (float64, float64) {
+			return gtr.geoToPixel(projection.Forward(lon, lat))
+		}
+	}
+
+	// Convert the polygons to pixel space and find the window they cover
+	pixels := make(orb.MultiPolygon, len(polygons))
+	bound := orb.Bound{Min: orb.Point{math.Inf(1), math.Inf(1)}, Max: orb.Point{math.Inf(-1), math.Inf(-1)}}
+	for i, polygon := range polygons {
+		pixels[i] = make(orb.Polygon, len(polygon))
+		for j, ring := range polygon {
+			pixels[i][j] = make(orb.Ring, len(ring))
+			for k, p := range ring {
+				x, y := transform(p[0], p[1])
+				if math.IsNaN(x) || math.IsNaN(y) {
+					return nil, fmt.Errorf("failed to project polygon vertex %v", p)
+				}
+				pixels[i][j][k] = orb.Point{x, y}
+				bound = bound.Extend(orb.Point{x, y})
+			}
+		}
+	}
+
+	x0 := clampInt(math.Floor(bound.Min[0]), meta.Width)
+	y0 := clampInt(math.Floor(bound.Min[1]), meta.Height)
+	x1 := clampInt(math.Ceil(bound.Max[0]), meta.Width)
+	y1 := clampInt(math.Ceil(bound.Max[1]), meta.Height)
+	if x1 <= x0 || y1 <= y0 {
+		return nil, fmt.Errorf("polygon does not intersect the image")
+	}
+
+	data, err := c.readRaster(opts.Overview, x0, y0, x1-x0, y1-y0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rasterise in window coordinates
+	for _, polygon := range pixels {
+		for _, ring := range polygon {
+			for k := range ring {
+				ring[k][0] -= float64(x0)
+				ring[k][1] -= float64(y0)
+			}
+		}
+	}
+	mask := rasterizePolygons(pixels, data.Width, data.Height, opts.AllTouched)
+
+	fill := uint64(0)
+	if data.HasNoData {
+		fill = Float64ToSample(data.NoData, data.DataType)
+	}
+	outside := false
+	for p, m := range mask {
+		if m != 0 {
+			continue
+		}
+		outside = true
+		for b := 0; b < data.Bands; b++ {
+			data.Data[p*data.Bands+b] = fill
+		}
+	}
+	if outside {
+		data.Mask = mask
+	}
+
+	return data, nil
+}
+
+// multiPolygon returns a polygonal geometry as a MultiPolygon
+func multiPolygon(geom orb.Geometry) (orb.MultiPolygon, error) {
+	switch g := geom.(type) {
+	case orb.Polygon:
+		return orb.MultiPolygon{g}, nil
+	case orb.MultiPolygon:
+		return g, nil
+	case nil:
+		return nil, fmt.Errorf("no geometry")
+	default:
+		return nil, fmt.Errorf("unsupported geometry type: %s", geom.GeoJSONType())
+	}
+}
+
+// rasterizePolygons returns a width x height mask (255 inside, 0 outside) of
+// polygons given in pixel coordinates. Each polygon is filled with the
+// even-odd rule over its rings, so holes are excluded; the polygons of a
+// MultiPolygon are combined. A pixel is inside if its centre is, or with
+// allTouched if the polygon's boundary passes through it.
+func rasterizePolygons(polygons orb.MultiPolygon, width, height int, allTouched bool) []uint8 {
+	mask := make([]uint8, width*height)
+
+	var crossings []float64
+	for _, polygon := range polygons {
+		for y := 0; y < height; y++ {
+			centre := float64(y) + 0.5
+			crossings = crossings[:0]
+			for _, ring := range polygon {
+				for k := range ring {
+					a, b := ring[k], ring[(k+1)%len(ring)]
+					if (a[1] <= centre) != (b[1] <= centre) {
+						crossings = append(crossings, a[0]+(centre-a[1])*(b[0]-a[0])/(b[1]-a[1]))
+					}
+				}
+			}
+			sort.Float64s(crossings)
+
+			// Pixel x is inside a span when its centre x+0.5 lies in [start, end)
+			for i := 0; i+1 < len(crossings); i += 2 {
+				first := clampInt(math.Ceil(crossings[i]-0.5), width)
+				last := clampInt(math.Ceil(crossings[i+1]-0.5), width)
+				for x := first; x < last; x++ {
+					mask[y*width+x] = 255
+				}
+			}
+		}
+
+		if allTouched {
+			for _, ring := range polygon {
+				for k := range ring {
+					burnSegment(mask, width, height, ring[k], ring[(k+1)%len(ring)])
+				}
+			}
+		}
+	}
+
+	return mask
+}
+
+// burnSegment marks the pixels whose interior the segment from a to b
+// passes through
+func burnSegment(mask []uint8, width, height int, a, b orb.Point) {
+	minY, maxY := math.Min(a[1], b[1]), math.Max(a[1], b[1])
+	if minY == maxY {
+		// A horizontal segment on a row boundary only touches pixel edges
+		if minY != math.Floor(minY) && minY > 0 && minY < float64(height) {
+			burnSpan(mask, width, height, int(minY), math.Min(a[0], b[0]), math.Max(a[0], b[0]))
+		}
+		return
+	}
+
+	first := clampInt(math.Floor(minY), height)
+	last := clampInt(math.Ceil(maxY), height)
+	for y := first; y < last; y++ {
+		lo, hi := math.Max(float64(y), minY), math.Min(float64(y+1), maxY)
+		xLo := a[0] + (lo-a[1])*(b[0]-a[0])/(b[1]-a[1])
+		xHi := a[0] + (hi-a[1])*(b[0]-a[0])/(b[1]-a[1])
+		burnSpan(mask, width, height, y, math.Min(xLo, xHi), math.Max(xLo, xHi))
+	}
+}
+
+// burnSpan marks the pixels of row y whose interior overlaps [minX, maxX]
+func burnSpan(mask []uint8, width, height, y int, minX, maxX float64) {
+	if y < 0 || y >= height {
+		return
+	}
+	first, last := math.Floor(minX), math.Ceil(maxX)
+	if minX == maxX {
+		// A vertical segment on a column boundary only touches pixel edges
+		if minX == first {
+			return
+		}
+		last = first + 1
+	}
+	for x := clampInt(first, width); x < clampInt(last, width); x++ {
+		mask[y*width+x] = 255
+	}
+}
+
+// clampInt converts v to an int clamped to [0, limit]
+func clampInt(v float64, limit int) int {
+	return int(math.Max(0, math.Min(float64(limit), v)))
+}
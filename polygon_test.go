@@ -0,0 +1,150 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+func square(minX, minY, maxX, maxY float64) orb.Ring {
+	return orb.Ring{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}, {minX, minY}}
+}
+
+func countMask(mask []uint8) int {
+	n := 0
+	for _, m := range mask {
+		if m != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRasterizePolygons(t *testing.T) {
+	tests := []struct {
+		name       string
+		polygons   orb.MultiPolygon
+		allTouched bool
+		want       int
+	}{
+		{name: "aligned square", polygons: orb.MultiPolygon{{square(2, 2, 5, 5)}}, want: 9},
+		{name: "aligned square all touched", polygons: orb.MultiPolygon{{square(2, 2, 5, 5)}}, allTouched: true, want: 9},
+		{name: "small square", polygons: orb.MultiPolygon{{square(2.6, 2.6, 4.4, 4.4)}}, want: 1},
+		{name: "small square all touched", polygons: orb.MultiPolygon{{square(2.6, 2.6, 4.4, 4.4)}}, allTouched: true, want: 9},
+		{name: "sliver all touched", polygons: orb.MultiPolygon{{square(3.1, 1, 3.2, 4)}}, allTouched: true, want: 3},
+		{name: "hole", polygons: orb.MultiPolygon{{square(0, 0, 10, 10), square(3, 3, 7, 7)}}, want: 84},
+		{name: "two polygons", polygons: orb.MultiPolygon{{square(0, 0, 2, 2)}, {square(5, 5, 8, 6)}}, want: 7},
+		{name: "triangle", polygons: orb.MultiPolygon{{{{0, 0}, {10, 0}, {0, 10}, {0, 0}}}}, want: 45},
+		{name: "beyond the window", polygons: orb.MultiPolygon{{square(-1e9, -1e9, 1e9, 1e9)}}, allTouched: true, want: 100},
+	}
+	for _, tt := range tests {
+		mask := rasterizePolygons(tt.polygons, 10, 10, tt.allTouched)
+		if got := countMask(mask); got != tt.want {
+			t.Errorf("%s: expected %d pixels, got %d", tt.name, tt.want, got)
+		}
+	}
+
+	// The hole is excluded and its border is inside
+	mask := rasterizePolygons(orb.MultiPolygon{{square(0, 0, 10, 10), square(3, 3, 7, 7)}}, 10, 10, false)
+	if mask[5*10+5] != 0 || mask[2*10+5] == 0 {
+		t.Error("expected the hole to be excluded")
+	}
+}
+
+// zonalRaster is 100x100 pixels of 10 m in Web Mercator whose values are the
+// column, with column 12 NoData
+var zonalRaster = testRaster{
+	Width: 100, Height: 100, DataType: DTSShortS, TileSize: 32, EPSG: 3857,
+	Origin: [2]float64{0, 1000}, PixelSize: [2]float64{10, 10}, NoData: "-1",
+	Value: func(band, x, y int) float64 {
+		if x == 12 {
+			return -1
+		}
+		return float64(x)
+	},
+}
+
+func TestReadPolygon(t *testing.T) {
+	c := zonalRaster.cog(t)
+
+	// Columns 10-19 and rows 0-4, with a one pixel hole at column 15, row 2
+	polygon := orb.Polygon{square(100, 950, 200, 1000), square(150, 970, 160, 980)}
+	data, err := c.ReadPolygon(polygon, PolygonOptions{})
+	if err != nil {
+		t.Fatalf("ReadPolygon failed: %v", err)
+	}
+	if data.Width != 10 || data.Height != 5 {
+		t.Fatalf("expected a 10x5 window, got %dx%d", data.Width, data.Height)
+	}
+	if !data.IsNoData(0, 5, 2) || data.Float(0, 5, 2) != -1 {
+		t.Errorf("expected the hole to be masked and filled with NoData")
+	}
+	if data.IsNoData(0, 4, 2) || data.Float(0, 4, 2) != 14 {
+		t.Errorf("expected column 14 to be valid, got %v", data.Float(0, 4, 2))
+	}
+
+	// The same polygon in longitude/latitude selects the same pixels
+	lonlat := make(orb.Polygon, len(polygon))
+	for i, ring := range polygon {
+		lonlat[i] = make(orb.Ring, len(ring))
+		for j, p := range ring {
+			lon, lat := proj.WebMercator.Inverse(p[0], p[1])
+			lonlat[i][j] = orb.Point{lon, lat}
+		}
+	}
+	projected, err := c.ReadPolygon(orb.MultiPolygon{lonlat}, PolygonOptions{LonLat: true})
+	if err != nil {
+		t.Fatalf("ReadPolygon failed: %v", err)
+	}
+	if countMask(projected.Mask) != countMask(data.Mask) {
+		t.Errorf("expected %d pixels, got %d", countMask(data.Mask), countMask(projected.Mask))
+	}
+
+	if _, err := c.ReadPolygon(orb.Polygon{square(2000, 0, 3000, 100)}, PolygonOptions{}); err == nil {
+		t.Error("expected error for a polygon outside the image")
+	}
+	if _, err := c.ReadPolygon(orb.LineString{{0, 0}, {100, 100}}, PolygonOptions{}); err == nil {
+		t.Error("expected error for a line")
+	}
+}
+
+func TestZonalStatistics(t *testing.T) {
+	c := zonalRaster.cog(t)
+
+	// Columns 10-19 and rows 0-4: 50 pixels, of which column 12 is NoData
+	stats, err := c.ZonalStatistics(orb.Polygon{square(100, 950, 200, 1000)}, PolygonOptions{})
+	if err != nil {
+		t.Fatalf("ZonalStatistics failed: %v", err)
+	}
+	s := stats[0]
+	if s.Count != 45 || s.NoDataCount != 5 {
+		t.Errorf("expected 45 valid and 5 NoData pixels, got %d and %d", s.Count, s.NoDataCount)
+	}
+	if s.Sum != 665 || s.Min != 10 || s.Max != 19 {
+		t.Errorf("expected sum 665, min 10, max 19, got %v, %v, %v", s.Sum, s.Min, s.Max)
+	}
+	if math.Abs(s.Mean-665.0/45) > 1e-9 {
+		t.Errorf("expected mean %v, got %v", 665.0/45, s.Mean)
+	}
+	// Every value occurs five times, so the smallest wins
+	if s.Majority != 10 {
+		t.Errorf("expected majority 10, got %v", s.Majority)
+	}
+
+	// All touched adds the partly covered columns and rows
+	stats, err = c.ZonalStatistics(orb.Polygon{square(105, 955, 195, 1000)}, PolygonOptions{AllTouched: true})
+	if err != nil {
+		t.Fatalf("ZonalStatistics failed: %v", err)
+	}
+	if stats[0].Count+stats[0].NoDataCount != 50 {
+		t.Errorf("expected 50 touched pixels, got %d", stats[0].Count+stats[0].NoDataCount)
+	}
+
+	r := &RasterData{Data: []uint64{1, 2, 2, 3}, Width: 4, Height: 1, Bands: 1, DataType: DTByte, Mask: []uint8{255, 255, 255, 0}}
+	s = r.ZonalStatistics()[0]
+	if s.Count != 3 || s.Sum != 5 || s.Majority != 2 || s.Max != 2 {
+		t.Errorf("unexpected statistics of a masked raster: %+v", s)
+	}
+}
@@ -0,0 +1,160 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// maxProfileSamples limits the number of samples of a profile
+const maxProfileSamples = 10_000_000
+
+// ProfileOptions controls how Profile samples a line
+type ProfileOptions struct {
+	// Spacing is the distance between samples along the line: metres for a
+	// geographic line, CRS units otherwise. The default is one pixel of the
+	// main image.
+	Spacing float64
+
+	// LonLat means the line is in longitude/latitude and is projected into
+	// the image's CRS. By default it is in the image's CRS.
+	LonLat bool
+
+	// Band is the 0-based band to sample
+	Band int
+}
+
+// ProfilePoint is one sample of a profile
+type ProfilePoint struct {
+	Distance float64   // Distance from the start of the line
+	Point    orb.Point // Position, in the line's coordinates
+	Value    float64   // Interpolated value in physical units, NaN where NoData
+	NoData   bool
+}
+
+// Profile holds the values of a band along a line, such as a terrain profile
+type Profile struct {
+	Points  []ProfilePoint
+	Length  float64 // Length of the line
+	Ascent  float64 // Sum of the rises between consecutive valid samples
+	Descent float64 // Sum of the falls between consecutive valid samples, as a positive number
+}
+
+// Profile samples a band at evenly spaced points along an orb.LineString,
+// from its start to its end, with bilinear interpolation. Distances are
+// geodesic (on the WGS84 ellipsoid, in metres) for lines in longitude/
+// latitude or in a geographic CRS, and planar in CRS units otherwise; sample
+// positions are interpolated linearly between the vertices. Each tile is
+// fetched once.
+func (c *COG) Profile(line orb.LineString, opts ProfileOptions) (*Profile, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image data available")
+	}
+	if len(line) < 2 {
+		return nil, fmt.Errorf("line needs at least two points")
+	}
+	if opts.Band < 0 || opts.Band >= c.BandCount() {
+		return nil, fmt.Errorf("band %d out of range (image has %d bands)", opts.Band, c.BandCount())
+	}
+	gtr := c.geoTIFFs[0]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+
+	// Lines in the image's CRS are used as they are, so an unsupported CRS is
+	// only an error for longitude/latitude lines
+	projection, err := c.Projection()
+	if err != nil && opts.LonLat {
+		return nil, fmt.Errorf("unsupported CRS: %w", err)
+	}
+	_, geographic := projection.(proj.Geographic)
+	toImage := func(p orb.Point) orb.Point { return p }
+	if opts.LonLat && !geographic {
+		toImage = func(p orb.Point) orb.Point {
+			x, y := projection.Forward(p[0], p[1])
+			return orb.Point{x, y}
+		}
+	}
+	distance := func(a, b orb.Point) float64 { return math.Hypot(b[0]-a[0], b[1]-a[1]) }
+	if opts.LonLat || geographic {
+		distance = func(a, b orb.Point) float64 { return proj.WGS84.Distance(a[0], a[1], b[0], b[1]) }
+	}
+
+	// Cumulative distance to each vertex
+	cumulative := make([]float64, len(line))
+	for i := 1; i < len(line); i++ {
+		cumulative[i] = cumulative[i-1] + distance(line[i-1], line[i])
+	}
+	length := cumulative[len(line)-1]
+
+	spacing := opts.Spacing
+	if spacing <= 0 {
+		gt, _ := gtr.approximateGeoTransform()
+		pixelWidth, pixelHeight := gt.PixelSize()
+		spacing = math.Min(math.Abs(pixelWidth), math.Abs(pixelHeight))
+		if geographic {
+			start := line[0]
+			spacing = distance(start, orb.Point{start[0], start[1] + spacing})
+		}
+	}
+	if !(spacing > 0) || length/spacing > maxProfileSamples {
+		return nil, fmt.Errorf("invalid spacing %v for a line of length %v", spacing, length)
+	}
+
+	// Positions of the samples, with the end of the line as the last one
+	count := int(math.Ceil(length/spacing)) + 1
+	if length == 0 {
+		count = 1
+	}
+	profile := &Profile{Points: make([]ProfilePoint, count), Length: length}
+	points := make([]orb.Point, count)
+	segment := 0
+	for i := range points {
+		d := math.Min(float64(i)*spacing, length)
+		for segment < len(line)-2 && cumulative[segment+1] < d {
+			segment++
+		}
+		a, b := line[segment], line[segment+1]
+		t := 0.0
+		if span := cumulative[segment+1] - cumulative[segment]; span > 0 {
+			t = (d - cumulative[segment]) / span
+		}
+		points[i] = orb.Point{a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])}
+		profile.Points[i] = ProfilePoint{Distance: d, Point: points[i]}
+	}
+
+	// Longitudes are matched modulo 360°, as in SampleMany
+	frameWest, _, wrap := c.longitudeFrame()
+	samples, err := c.samplePoints(points, func(p orb.Point) (float64, float64) {
+		q := toImage(p)
+		if wrap {
+			q[0] = wrapLongitude(q[0], frameWest)
+		}
+		return gtr.geoToPixel(q[0], q[1])
+	}, ResampleBilinear)
+	if err != nil {
+		return nil, err
+	}
+
+	scale, offset := c.ScaleOffset(opts.Band)
+	previous := math.NaN()
+	for i, s := range samples {
+		point := &profile.Points[i]
+		if s.NoData[opts.Band] {
+			point.Value = math.NaN()
+			point.NoData = true
+			continue
+		}
+		point.Value = s.Values[opts.Band]*scale + offset
+		if change := point.Value - previous; change > 0 {
+			profile.Ascent += change
+		} else if change < 0 {
+			profile.Descent -= change
+		}
+		previous = point.Value
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,128 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+func TestProfile(t *testing.T) {
+	// 100 m UTM pixels whose values are the column, with column 50 NoData
+	tr := testRaster{
+		Width: 100, Height: 100, DataType: DTFloat, TileSize: 32, EPSG: 32633,
+		Origin: [2]float64{495000, 5005000}, PixelSize: [2]float64{100, 100}, NoData: "-9999",
+		Value: func(band, x, y int) float64 {
+			if x == 50 {
+				return -9999
+			}
+			return float64(x)
+		},
+	}
+	c := tr.cog(t)
+
+	// From the centre of column 0 to the centre of column 99 and back to column 90
+	line := orb.LineString{{495050, 5000050}, {504950, 5000050}, {504050, 5000050}}
+	profile, err := c.Profile(line, ProfileOptions{})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	if profile.Length != 10800 || len(profile.Points) != 109 {
+		t.Fatalf("expected 109 samples over 10800 m, got %d over %v", len(profile.Points), profile.Length)
+	}
+	for i, p := range profile.Points {
+		want := float64(i)
+		if i > 99 {
+			want = float64(198 - i)
+		}
+		if p.Distance != float64(i)*100 {
+			t.Fatalf("sample %d: expected distance %v, got %v", i, float64(i)*100, p.Distance)
+		}
+		if want == 50 {
+			if !p.NoData || !math.IsNaN(p.Value) {
+				t.Errorf("sample %d: expected NoData, got %v", i, p.Value)
+			}
+			continue
+		}
+		if p.NoData || math.Abs(p.Value-want) > 1e-6 {
+			t.Fatalf("sample %d: expected %v, got %v", i, want, p.Value)
+		}
+	}
+	// The NoData gap is bridged between the last and next valid samples
+	if math.Abs(profile.Ascent-99) > 1e-6 || math.Abs(profile.Descent-9) > 1e-6 {
+		t.Errorf("expected ascent 99 and descent 9, got %v and %v", profile.Ascent, profile.Descent)
+	}
+
+	// Spacing that does not divide the length ends on the last vertex
+	profile, err = c.Profile(line[:2], ProfileOptions{Spacing: 400})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	last := profile.Points[len(profile.Points)-1]
+	if len(profile.Points) != 26 || last.Distance != 9900 || last.Point != line[1] {
+		t.Errorf("expected 26 samples ending at the line's end, got %d ending at %v", len(profile.Points), last)
+	}
+
+	// The same line in longitude/latitude gives geodesic distances
+	utm, _ := proj.UTM(33, true)
+	lonlat := make(orb.LineString, 2)
+	for i, p := range line[:2] {
+		lon, lat := utm.Inverse(p[0], p[1])
+		lonlat[i] = orb.Point{lon, lat}
+	}
+	profile, err = c.Profile(lonlat, ProfileOptions{LonLat: true, Spacing: 100})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	// UTM scale is 0.9996 on the central meridian
+	if math.Abs(profile.Length-9900/0.9996) > 1 {
+		t.Errorf("expected a geodesic length of about %v m, got %v", 9900/0.9996, profile.Length)
+	}
+	if v := profile.Points[10].Value; math.Abs(v-10*0.9996) > 0.05 {
+		t.Errorf("expected about %v 1 km along the line, got %v", 10*0.9996, v)
+	}
+
+	if _, err := c.Profile(orb.LineString{{495050, 5000050}}, ProfileOptions{}); err == nil {
+		t.Error("expected error for a single point")
+	}
+	if _, err := c.Profile(line, ProfileOptions{Band: 1}); err == nil {
+		t.Error("expected error for an invalid band")
+	}
+}
+
+func TestProfileGeographic(t *testing.T) {
+	c := sampleRaster.cog(t)
+
+	// Down the centre of column 5, from row 0 to row 63
+	line := orb.LineString{pixelCentre(5, 0), pixelCentre(5, 63)}
+	profile, err := c.Profile(line, ProfileOptions{})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	want := proj.WGS84.Distance(line[0][0], line[0][1], line[1][0], line[1][1])
+	if math.Abs(profile.Length-want) > 1e-6 {
+		t.Errorf("expected geodesic length %v, got %v", want, profile.Length)
+	}
+	// The default spacing is one pixel, so every row is sampled once
+	if len(profile.Points) != 64 {
+		t.Fatalf("expected 64 samples, got %d", len(profile.Points))
+	}
+	if math.Abs(profile.Ascent-63000) > 1 || profile.Descent != 0 {
+		t.Errorf("expected ascent 63000 and no descent, got %v and %v", profile.Ascent, profile.Descent)
+	}
+}
+
+func TestProfileZeroTo360(t *testing.T) {
+	// A line at 170°W on a 0–360 image samples column 190
+	c := globalRaster(0).cog(t)
+	profile, err := c.Profile(orb.LineString{{-169.5, 10.5}, {-169.5, 19.5}}, ProfileOptions{LonLat: true})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	for _, p := range profile.Points {
+		if p.NoData || p.Value != 190 {
+			t.Fatalf("expected 190 at %v, got %+v", p.Point, p)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package proj
+
+import (
+	"math"
+)
+
+// albersEqualArea implements the ellipsoidal Albers Equal Area conic
+// projection (EPSG method 9822)
+type albersEqualArea struct {
+	a, e   float64
+	n, c   float64
+	rhoF   float64
+	lonF   float64
+	fe, fn float64
+}
+
+// NewAlbersEqualArea returns an Albers Equal Area projection with two
+// standard parallels and a false origin (degrees, metres)
+func NewAlbersEqualArea(e Ellipsoid, stdParallel1, stdParallel2, latOrigin, lonOrigin, falseEasting, falseNorthing float64) Projection {
+	ecc := e.Eccentricity()
+	phi1, phi2 := radians(stdParallel1), radians(stdParallel2)
+
+	m1, m2 := msfn(phi1, ecc), msfn(phi2, ecc)
+	q1, q2 := qsfn(phi1, ecc), qsfn(phi2, ecc)
+
+	n := math.Sin(phi1)
+	if math.Abs(phi1-phi2) > 1e-10 {
+		n = (m1*m1 - m2*m2) / (q2 - q1)
+	}
+
+	aea := &albersEqualArea{
+		a:    e.SemiMajor,
+		e:    ecc,
+		n:    n,
+		c:    m1*m1 + n*q1,
+		lonF: radians(lonOrigin),
+		fe:   falseEasting,
+		fn:   falseNorthing,
+	}
+	aea.rhoF = aea.rho(qsfn(radians(latOrigin), ecc))
+	return aea
+}
+
+// rho returns the distance from the cone apex for authalic function value q
+func (aea *albersEqualArea) rho(q float64) float64 {
+	return aea.a * math.Sqrt(math.Max(0, aea.c-aea.n*q)) / aea.n
+}
+
+// Forward implements Projection
+func (aea *albersEqualArea) Forward(lon, lat float64) (float64, float64) {
+	rho := aea.rho(qsfn(radians(lat), aea.e))
+	theta := aea.n * normalizeLon(radians(lon)-aea.lonF)
+	return aea.fe + rho*math.Sin(theta), aea.fn + aea.rhoF - rho*math.Cos(theta)
+}
+
+// Inverse implements Projection
+func (aea *albersEqualArea) Inverse(x, y float64) (float64, float64) {
+	dx := x - aea.fe
+	dy := aea.rhoF - (y - aea.fn)
+	if aea.n < 0 {
+		dx, dy = -dx, -dy
+	}
+	rho := math.Hypot(dx, dy)
+	q := (aea.c - rho*rho*aea.n*aea.n/(aea.a*aea.a)) / aea.n
+	theta := math.Atan2(dx, dy)
+	return degrees(normalizeLon(theta/aea.n + aea.lonF)), degrees(authalicLat(q, aea.e))
+}
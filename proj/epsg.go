@@ -0,0 +1,111 @@
+package proj
+
+import (
+	"fmt"
+)
+
+// Method identifies a projection method
+type Method int
+
+const (
+	MethodTransverseMercator Method = iota + 1
+	MethodLambertConformalConic1SP
+	MethodLambertConformalConic2SP
+	MethodAlbersEqualArea
+	MethodLambertAzimuthalEqualArea
+	MethodPolarStereographicA
+	MethodPolarStereographicB
+	MethodWebMercator
+)
+
+// Params holds the parameters of a projection. Angles are in degrees and
+// distances in metres. Which fields are used depends on Method:
+//
+//   - Transverse Mercator, LCC 1SP, Polar Stereographic A: LatOrigin,
+//     LonOrigin, Scale, FalseEasting, FalseNorthing
+//   - LCC 2SP, Albers: StdParallel1, StdParallel2, LatOrigin, LonOrigin,
+//     FalseEasting, FalseNorthing
+//   - LAEA: LatOrigin, LonOrigin, FalseEasting, FalseNorthing
+//   - Polar Stereographic B: StdParallel1 (latitude of true scale),
+//     LonOrigin, FalseEasting, FalseNorthing
+type Params struct {
+	Method        Method
+	Ellipsoid     Ellipsoid
+	LatOrigin     float64
+	LonOrigin     float64
+	StdParallel1  float64
+	StdParallel2  float64
+	Scale         float64
+	FalseEasting  float64
+	FalseNorthing float64
+}
+
+// New creates a projection from parameters
+func New(p Params) (Projection, error) {
+	e := p.Ellipsoid
+	if e.SemiMajor == 0 {
+		e = WGS84
+	}
+	scale := p.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	switch p.Method {
+	case MethodTransverseMercator:
+		return NewTransverseMercator(e, p.LonOrigin, p.LatOrigin, scale, p.FalseEasting, p.FalseNorthing), nil
+	case MethodLambertConformalConic1SP:
+		return NewLambertConformalConic1SP(e, p.LatOrigin, p.LonOrigin, scale, p.FalseEasting, p.FalseNorthing), nil
+	case MethodLambertConformalConic2SP:
+		return NewLambertConformalConic2SP(e, p.StdParallel1, p.StdParallel2, p.LatOrigin, p.LonOrigin, p.FalseEasting, p.FalseNorthing), nil
+	case MethodAlbersEqualArea:
+		return NewAlbersEqualArea(e, p.StdParallel1, p.StdParallel2, p.LatOrigin, p.LonOrigin, p.FalseEasting, p.FalseNorthing), nil
+	case MethodLambertAzimuthalEqualArea:
+		return NewLambertAzimuthalEqualArea(e, p.LatOrigin, p.LonOrigin, p.FalseEasting, p.FalseNorthing), nil
+	case MethodPolarStereographicA:
+		if p.LatOrigin != 90 && p.LatOrigin != -90 {
+			return nil, fmt.Errorf("polar stereographic latitude of origin must be 90 or -90, got %v", p.LatOrigin)
+		}
+		return NewPolarStereographicA(e, p.LatOrigin, p.LonOrigin, scale, p.FalseEasting, p.FalseNorthing), nil
+	case MethodPolarStereographicB:
+		return NewPolarStereographicB(e, p.StdParallel1, p.LonOrigin, p.FalseEasting, p.FalseNorthing), nil
+	case MethodWebMercator:
+		return WebMercator, nil
+	default:
+		return nil, fmt.Errorf("unsupported projection method %d", p.Method)
+	}
+}
+
+// IsGeographic reports whether an EPSG code is a registered geographic CRS
+func IsGeographic(code int) bool {
+	c, ok := LookupCRS(code)
+	return ok && c.Geographic
+}
+
+// EPSGParams returns the projection parameters of a registered projected
+// EPSG code, such as UTM zones on WGS84 (326xx, 327xx), ETRS89 (258xx) and
+// NAD83 (269xx). Distances are in metres whatever the CRS's unit.
+func EPSGParams(code int) (Params, bool) {
+	c, ok := LookupCRS(code)
+	if !ok || c.Geographic {
+		return Params{}, false
+	}
+	return c.Params, true
+}
+
+// FromEPSG returns the projection for a registered EPSG code. Geographic
+// codes return Geographic; projected coordinates are in the CRS's unit.
+func FromEPSG(code int) (Projection, error) {
+	c, ok := LookupCRS(code)
+	if !ok {
+		return nil, fmt.Errorf("unsupported EPSG code %d", code)
+	}
+	if c.Geographic {
+		return Geographic{}, nil
+	}
+	p, err := New(c.Params)
+	if err != nil {
+		return nil, err
+	}
+	return WithUnits(p, c.MetresPerUnit), nil
+}
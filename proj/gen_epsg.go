@@ -0,0 +1,388 @@
+//go:build ignore
+
+// gen_epsg generates epsg.csv, the EPSG subset embedded by the registry,
+// from the EPSG dataset in PROJ's proj.db. The CRSs are listed in
+// epsg_codes.txt; their base CRSs, datums and ellipsoids are added. The
+// database is read with the sqlite3 command-line tool.
+//
+//	go run gen_epsg.go -db /usr/share/proj/proj.db
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var db = flag.String("db", "/usr/share/proj/proj.db", "path of PROJ's proj.db")
+
+// methods maps EPSG method codes to the method names of epsg.csv
+var methods = map[int]string{
+	9807: "tmerc",
+	9801: "lcc1",
+	9802: "lcc2",
+	9822: "aea",
+	9820: "laea",
+	9810: "stere_a",
+	9829: "stere_b",
+	1024: "webmerc",
+}
+
+// Indices of the conversion parameters in a projected record
+const (
+	latOrigin = iota
+	lonOrigin
+	stdParallel1
+	stdParallel2
+	scale
+	falseEasting
+	falseNorthing
+)
+
+// parameters maps EPSG parameter codes to conversion parameters
+var parameters = map[int]int{
+	8801: latOrigin,     // Latitude of natural origin
+	8802: lonOrigin,     // Longitude of natural origin
+	8805: scale,         // Scale factor at natural origin
+	8806: falseEasting,  // False easting
+	8807: falseNorthing, // False northing
+	8821: latOrigin,     // Latitude of false origin
+	8822: lonOrigin,     // Longitude of false origin
+	8823: stdParallel1,  // Latitude of 1st standard parallel
+	8824: stdParallel2,  // Latitude of 2nd standard parallel
+	8826: falseEasting,  // Easting at false origin
+	8827: falseNorthing, // Northing at false origin
+	8832: stdParallel1,  // Latitude of standard parallel
+	8833: lonOrigin,     // Longitude of origin
+}
+
+func main() {
+	flag.Parse()
+	codes, err := readCodes("epsg_codes.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &generator{done: make(map[string]bool)}
+	for _, code := range codes {
+		if err := g.crs(code); err != nil {
+			log.Fatalf("EPSG:%d: %v", code, err)
+		}
+	}
+
+	version := query1("SELECT value FROM metadata WHERE key = 'EPSG.VERSION'")
+	date := query1("SELECT value FROM metadata WHERE key = 'EPSG.DATE'")
+	var out bytes.Buffer
+	fmt.Fprintf(&out, `# Code generated by gen_epsg.go from EPSG dataset %s (%s) in PROJ's proj.db. DO NOT EDIT.
+#
+# Subset of the EPSG Geodetic Parameter Dataset (https://epsg.org) covering
+# the CRSs of epsg_codes.txt. Angles are in degrees and distances in metres;
+# areas of use are bounding boxes in degrees, with west > east for areas
+# crossing the antimeridian.
+#
+# ellipsoid,code,name,semi-major axis,inverse flattening
+# datum,code,name,ellipsoid
+# geographic,code,name,datum,axis order,area,west,south,east,north
+# projected,code,name,base,unit,axis order,conversion code,conversion name,method,
+#   latitude of origin,longitude of origin,standard parallel 1,standard parallel 2,
+#   scale,false easting,false northing,area,west,south,east,north
+`, version, date)
+	w := csv.NewWriter(&out)
+	for _, records := range [][][]string{g.ellipsoids, g.datums, g.geographic, g.projected} {
+		if err := w.WriteAll(records); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := os.WriteFile("epsg.csv", out.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readCodes reads the EPSG codes of a file, skipping comments
+func readCodes(name string) ([]int, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var codes []int
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		code, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, s.Err()
+}
+
+// generator collects the records of epsg.csv, each object once and after
+// the objects it references
+type generator struct {
+	done                                      map[string]bool
+	ellipsoids, datums, geographic, projected [][]string
+}
+
+func (g *generator) once(kind string, code int) bool {
+	key := fmt.Sprintf("%s:%d", kind, code)
+	if g.done[key] {
+		return false
+	}
+	g.done[key] = true
+	return true
+}
+
+// crs adds a geographic or projected CRS
+func (g *generator) crs(code int) error {
+	if rows := query("SELECT name, datum_code, coordinate_system_code FROM geodetic_crs WHERE auth_name = 'EPSG' AND code = %d AND type = 'geographic 2D'", code); len(rows) == 1 {
+		if !g.once("crs", code) {
+			return nil
+		}
+		datum := atoi(rows[0][1])
+		if err := g.datum(datum); err != nil {
+			return err
+		}
+		order, _, err := axes(atoi(rows[0][2]))
+		if err != nil {
+			return err
+		}
+		record := []string{"geographic", strconv.Itoa(code), rows[0][0], strconv.Itoa(datum), order}
+		g.geographic = append(g.geographic, append(record, area("geodetic_crs", code)...))
+		return nil
+	}
+
+	rows := query("SELECT name, geodetic_crs_code, coordinate_system_code, conversion_code FROM projected_crs WHERE auth_name = 'EPSG' AND code = %d", code)
+	if len(rows) != 1 {
+		return fmt.Errorf("not a geographic 2D or projected CRS")
+	}
+	if !g.once("crs", code) {
+		return nil
+	}
+	base := atoi(rows[0][1])
+	if err := g.crs(base); err != nil {
+		return fmt.Errorf("base CRS %d: %w", base, err)
+	}
+	order, unit, err := axes(atoi(rows[0][2]))
+	if err != nil {
+		return err
+	}
+	conversion, err := conversion(atoi(rows[0][3]))
+	if err != nil {
+		return err
+	}
+	record := []string{"projected", strconv.Itoa(code), rows[0][0], strconv.Itoa(base), strconv.Itoa(unit), order}
+	record = append(record, conversion...)
+	g.projected = append(g.projected, append(record, area("projected_crs", code)...))
+	return nil
+}
+
+// datum adds a geodetic datum and its ellipsoid
+func (g *generator) datum(code int) error {
+	if !g.once("datum", code) {
+		return nil
+	}
+	rows := query("SELECT name, ellipsoid_code FROM geodetic_datum WHERE auth_name = 'EPSG' AND code = %d", code)
+	if len(rows) != 1 {
+		return fmt.Errorf("unknown datum %d", code)
+	}
+	ellipsoid := atoi(rows[0][1])
+	if err := g.ellipsoid(ellipsoid); err != nil {
+		return err
+	}
+	g.datums = append(g.datums, []string{"datum", strconv.Itoa(code), rows[0][0], strconv.Itoa(ellipsoid)})
+	return nil
+}
+
+// ellipsoid adds an ellipsoid, with its semi-major axis in metres
+func (g *generator) ellipsoid(code int) error {
+	if !g.once("ellipsoid", code) {
+		return nil
+	}
+	rows := query("SELECT name, semi_major_axis, uom_code, inv_flattening, semi_minor_axis FROM ellipsoid WHERE auth_name = 'EPSG' AND code = %d", code)
+	if len(rows) != 1 {
+		return fmt.Errorf("unknown ellipsoid %d", code)
+	}
+	factor := unitFactor(atoi(rows[0][2]))
+	a := atof(rows[0][1]) * factor
+	invF := 0.0
+	if rows[0][3] != "" {
+		invF = atof(rows[0][3])
+	} else if b := atof(rows[0][4]) * factor; b != a {
+		invF = a / (a - b)
+	}
+	g.ellipsoids = append(g.ellipsoids, []string{"ellipsoid", strconv.Itoa(code), rows[0][0], number(a), number(invF)})
+	return nil
+}
+
+// axes returns the axis order and unit of a 2D coordinate system
+func axes(cs int) (string, int, error) {
+	rows := query("SELECT abbrev, uom_code FROM axis WHERE coordinate_system_auth_name = 'EPSG' AND coordinate_system_code = %d ORDER BY coordinate_system_order", cs)
+	if len(rows) != 2 {
+		return "", 0, fmt.Errorf("coordinate system %d has %d axes", cs, len(rows))
+	}
+	order := "east-north"
+	if first := rows[0][0]; first == "N" || first == "Lat" {
+		order = "north-east"
+	}
+	return order, atoi(rows[0][1]), nil
+}
+
+// conversion returns the conversion fields of a projected record, with
+// angles in degrees and distances in metres
+func conversion(code int) ([]string, error) {
+	columns := []string{"name", "method_code"}
+	for i := 1; i <= 7; i++ {
+		columns = append(columns, fmt.Sprintf("param%d_code, param%d_value, param%d_uom_code", i, i, i))
+	}
+	rows := query("SELECT "+strings.Join(columns, ", ")+" FROM conversion WHERE auth_name = 'EPSG' AND code = %d", code)
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("unknown conversion %d", code)
+	}
+	row := rows[0]
+	method, ok := methods[atoi(row[1])]
+	if !ok {
+		return nil, fmt.Errorf("conversion %d: unsupported method %s", code, row[1])
+	}
+
+	var values [7]float64
+	for i := 2; i+2 < len(row); i += 3 {
+		if row[i] == "" {
+			continue
+		}
+		param, ok := parameters[atoi(row[i])]
+		if !ok {
+			return nil, fmt.Errorf("conversion %d: unsupported parameter %s", code, row[i])
+		}
+		v, err := convert(row[i+1], atoi(row[i+2]))
+		if err != nil {
+			return nil, fmt.Errorf("conversion %d: %w", code, err)
+		}
+		values[param] = v
+	}
+	fields := []string{strconv.Itoa(code), row[0], method}
+	for _, v := range values {
+		fields = append(fields, number(v))
+	}
+	return fields, nil
+}
+
+// convert returns a parameter value in degrees, metres or unity
+func convert(value string, unit int) (float64, error) {
+	if unit == 9110 {
+		return sexagesimalDMS(value)
+	}
+	v := atof(value)
+	switch unitType(unit) {
+	case "angle":
+		return v * unitFactor(unit) * 180 / math.Pi, nil
+	case "length", "scale":
+		return v * unitFactor(unit), nil
+	}
+	return 0, fmt.Errorf("unsupported unit %d", unit)
+}
+
+// sexagesimalDMS converts an EPSG sexagesimal DMS value (DDD.MMSSsss) to
+// degrees
+func sexagesimalDMS(value string) (float64, error) {
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	whole, fraction, _ := strings.Cut(value, ".")
+	fraction += "0000"
+	degrees, err := strconv.Atoi(whole)
+	if err != nil {
+		return 0, err
+	}
+	minutes, _ := strconv.Atoi(fraction[:2])
+	seconds, _ := strconv.ParseFloat(fraction[2:4]+"."+fraction[4:], 64)
+	v := float64(degrees) + float64(minutes)/60 + seconds/3600
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// area returns the name and bounding box, rounded to 0.01°, of the first
+// area of use of an object
+func area(table string, code int) []string {
+	rows := query("SELECT e.name, e.west_lon, e.south_lat, e.east_lon, e.north_lat FROM usage u JOIN extent e ON e.auth_name = u.extent_auth_name AND e.code = u.extent_code WHERE u.object_table_name = '"+table+"' AND u.object_auth_name = 'EPSG' AND u.object_code = %d ORDER BY u.code LIMIT 1", code)
+	if len(rows) != 1 {
+		return []string{"World", "-180", "-90", "180", "90"}
+	}
+	fields := []string{rows[0][0]}
+	for _, v := range rows[0][1:] {
+		fields = append(fields, number(math.Round(atof(v)*100)/100))
+	}
+	return fields
+}
+
+func unitFactor(unit int) float64 {
+	if unit == 9201 {
+		return 1
+	}
+	return atof(query1(fmt.Sprintf("SELECT conv_factor FROM unit_of_measure WHERE auth_name = 'EPSG' AND code = %d", unit)))
+}
+
+func unitType(unit int) string {
+	return query1(fmt.Sprintf("SELECT type FROM unit_of_measure WHERE auth_name = 'EPSG' AND code = %d", unit))
+}
+
+// query runs a query with an integer argument and returns its rows
+func query(q string, code int) [][]string {
+	return run(fmt.Sprintf(q, code))
+}
+
+// query1 returns the first column of the first row of a query
+func query1(q string) string {
+	rows := run(q)
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return ""
+	}
+	return rows[0][0]
+}
+
+func run(q string) [][]string {
+	out, err := exec.Command("sqlite3", "-csv", *db, q).Output()
+	if err != nil {
+		log.Fatalf("sqlite3 %q: %v", q, err)
+	}
+	r := csv.NewReader(bytes.NewReader(out))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		log.Fatalf("sqlite3 %q: %v", q, err)
+	}
+	return rows
+}
+
+func atoi(s string) int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		log.Fatalf("invalid integer %q", s)
+	}
+	return v
+}
+
+func atof(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		log.Fatalf("invalid number %q", s)
+	}
+	return v
+}
+
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
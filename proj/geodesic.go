@@ -0,0 +1,57 @@
+package proj
+
+import (
+	"math"
+)
+
+// Distance returns the geodesic distance in metres between two points
+// (longitude and latitude in degrees) on the ellipsoid, using Vincenty's
+// inverse formula. Nearly antipodal points, for which the iteration does not
+// converge, fall back to the great-circle distance on the mean sphere.
+func (e Ellipsoid) Distance(lon1, lat1, lon2, lat2 float64) float64 {
+	a := e.SemiMajor
+	f := e.Flattening()
+	b := e.SemiMinor()
+
+	u1 := math.Atan((1 - f) * math.Tan(radians(lat1)))
+	u2 := math.Atan((1 - f) * math.Tan(radians(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+	l := normalizeLon(radians(lon2 - lon1))
+
+	lambda := l
+	for i := 0; i < 200; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0 // Coincident points
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0 // Both points on the equator
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		previous := lambda
+		lambda = l + (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) > 1e-12 {
+			continue
+		}
+
+		u2 := cos2Alpha * (a*a - b*b) / (b * b)
+		k1 := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+		k2 := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+		deltaSigma := k1 * sinSigma * (cos2SigmaM + k1/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			k1/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return b * k2 * (sigma - deltaSigma)
+	}
+
+	// Great-circle distance on a sphere of the mean radius
+	radius := (2*a + b) / 3
+	phi1, phi2 := radians(lat1), radians(lat2)
+	h := math.Pow(math.Sin((phi2-phi1)/2), 2) + math.Cos(phi1)*math.Cos(phi2)*math.Pow(math.Sin(l/2), 2)
+	return 2 * radius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
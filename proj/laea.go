@@ -0,0 +1,89 @@
+package proj
+
+import (
+	"math"
+)
+
+// lambertAzimuthalEqualArea implements the ellipsoidal Lambert Azimuthal
+// Equal Area projection (EPSG method 9820), including the polar aspects
+type lambertAzimuthalEqualArea struct {
+	a, e         float64
+	qP           float64 // q at the pole
+	rq, d        float64
+	sinB0, cosB0 float64
+	lon0         float64
+	fe, fn       float64
+	pole         int // 1 north polar, -1 south polar, 0 oblique
+}
+
+// NewLambertAzimuthalEqualArea returns a Lambert Azimuthal Equal Area
+// projection centred on the given origin (degrees, metres)
+func NewLambertAzimuthalEqualArea(e Ellipsoid, latOrigin, lonOrigin, falseEasting, falseNorthing float64) Projection {
+	ecc := e.Eccentricity()
+	phi0 := radians(latOrigin)
+	laea := &lambertAzimuthalEqualArea{
+		a:    e.SemiMajor,
+		e:    ecc,
+		qP:   qsfn(math.Pi/2, ecc),
+		lon0: radians(lonOrigin),
+		fe:   falseEasting,
+		fn:   falseNorthing,
+	}
+
+	switch {
+	case math.Abs(latOrigin-90) < 1e-10:
+		laea.pole = 1
+	case math.Abs(latOrigin+90) < 1e-10:
+		laea.pole = -1
+	default:
+		beta0 := math.Asin(qsfn(phi0, ecc) / laea.qP)
+		laea.sinB0, laea.cosB0 = math.Sincos(beta0)
+		laea.rq = e.SemiMajor * math.Sqrt(laea.qP/2)
+		laea.d = e.SemiMajor * msfn(phi0, ecc) / (laea.rq * laea.cosB0)
+	}
+	return laea
+}
+
+// Forward implements Projection
+func (laea *lambertAzimuthalEqualArea) Forward(lon, lat float64) (float64, float64) {
+	q := qsfn(radians(lat), laea.e)
+	dLon := normalizeLon(radians(lon) - laea.lon0)
+	sinL, cosL := math.Sincos(dLon)
+
+	if laea.pole != 0 {
+		rho := laea.a * math.Sqrt(math.Max(0, laea.qP-float64(laea.pole)*q))
+		return laea.fe + rho*sinL, laea.fn - float64(laea.pole)*rho*cosL
+	}
+
+	beta := math.Asin(math.Max(-1, math.Min(1, q/laea.qP)))
+	sinB, cosB := math.Sincos(beta)
+	denom := 1 + laea.sinB0*sinB + laea.cosB0*cosB*cosL
+	if denom <= 0 {
+		return math.NaN(), math.NaN() // Antipode of the origin
+	}
+	b := laea.rq * math.Sqrt(2/denom)
+	return laea.fe + b*laea.d*cosB*sinL,
+		laea.fn + b/laea.d*(laea.cosB0*sinB-laea.sinB0*cosB*cosL)
+}
+
+// Inverse implements Projection
+func (laea *lambertAzimuthalEqualArea) Inverse(x, y float64) (float64, float64) {
+	dx, dy := x-laea.fe, y-laea.fn
+
+	if laea.pole != 0 {
+		rho := math.Hypot(dx, dy)
+		q := float64(laea.pole) * (laea.qP - rho*rho/(laea.a*laea.a))
+		lon := laea.lon0 + math.Atan2(dx, -float64(laea.pole)*dy)
+		return degrees(normalizeLon(lon)), degrees(authalicLat(q, laea.e))
+	}
+
+	rho := math.Hypot(dx/laea.d, laea.d*dy)
+	if rho == 0 {
+		return degrees(laea.lon0), degrees(authalicLat(laea.sinB0*laea.qP, laea.e))
+	}
+	c := 2 * math.Asin(math.Min(1, rho/(2*laea.rq)))
+	sinC, cosC := math.Sincos(c)
+	beta := math.Asin(cosC*laea.sinB0 + laea.d*dy*sinC*laea.cosB0/rho)
+	lon := laea.lon0 + math.Atan2(dx*sinC, laea.d*rho*laea.cosB0*cosC-laea.d*laea.d*dy*laea.sinB0*sinC)
+	return degrees(normalizeLon(lon)), degrees(authalicLat(laea.qP*math.Sin(beta), laea.e))
+}
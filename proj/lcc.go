@@ -0,0 +1,91 @@
+package proj
+
+import (
+	"math"
+)
+
+// lambertConformalConic implements the ellipsoidal Lambert Conformal Conic
+// projection (EPSG methods 9801 and 9802)
+type lambertConformalConic struct {
+	a, e   float64
+	n, f   float64 // cone constant and a*F*k0
+	rF     float64 // radius at the latitude of the false origin
+	lonF   float64
+	fe, fn float64
+}
+
+// NewLambertConformalConic2SP returns a Lambert Conformal Conic projection
+// with two standard parallels and a false origin (degrees, metres)
+func NewLambertConformalConic2SP(e Ellipsoid, stdParallel1, stdParallel2, latOrigin, lonOrigin, falseEasting, falseNorthing float64) Projection {
+	ecc := e.Eccentricity()
+	phi1, phi2 := radians(stdParallel1), radians(stdParallel2)
+
+	m1, m2 := msfn(phi1, ecc), msfn(phi2, ecc)
+	t1, t2 := tsfn(phi1, ecc), tsfn(phi2, ecc)
+
+	n := math.Sin(phi1)
+	if math.Abs(phi1-phi2) > 1e-10 {
+		n = (math.Log(m1) - math.Log(m2)) / (math.Log(t1) - math.Log(t2))
+	}
+	return newLambertConformalConic(e, n, m1/(n*math.Pow(t1, n)), latOrigin, lonOrigin, falseEasting, falseNorthing)
+}
+
+// NewLambertConformalConic1SP returns a Lambert Conformal Conic projection
+// with one standard parallel (the latitude of natural origin) and a scale
+// factor at the origin
+func NewLambertConformalConic1SP(e Ellipsoid, latOrigin, lonOrigin, scale, falseEasting, falseNorthing float64) Projection {
+	ecc := e.Eccentricity()
+	phi0 := radians(latOrigin)
+	n := math.Sin(phi0)
+	f := msfn(phi0, ecc) / (n * math.Pow(tsfn(phi0, ecc), n))
+	return newLambertConformalConic(e, n, f*scale, latOrigin, lonOrigin, falseEasting, falseNorthing)
+}
+
+func newLambertConformalConic(e Ellipsoid, n, f, latOrigin, lonOrigin, falseEasting, falseNorthing float64) *lambertConformalConic {
+	lcc := &lambertConformalConic{
+		a:    e.SemiMajor,
+		e:    e.Eccentricity(),
+		n:    n,
+		f:    e.SemiMajor * f,
+		lonF: radians(lonOrigin),
+		fe:   falseEasting,
+		fn:   falseNorthing,
+	}
+	lcc.rF = lcc.radius(radians(latOrigin))
+	return lcc
+}
+
+// radius returns the distance from the cone apex at latitude phi
+func (lcc *lambertConformalConic) radius(phi float64) float64 {
+	if math.Abs(math.Abs(phi)-math.Pi/2) < 1e-12 {
+		if phi*lcc.n > 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return lcc.f * math.Pow(tsfn(phi, lcc.e), lcc.n)
+}
+
+// Forward implements Projection
+func (lcc *lambertConformalConic) Forward(lon, lat float64) (float64, float64) {
+	r := lcc.radius(radians(lat))
+	theta := lcc.n * normalizeLon(radians(lon)-lcc.lonF)
+	return lcc.fe + r*math.Sin(theta), lcc.fn + lcc.rF - r*math.Cos(theta)
+}
+
+// Inverse implements Projection
+func (lcc *lambertConformalConic) Inverse(x, y float64) (float64, float64) {
+	dx := x - lcc.fe
+	dy := lcc.rF - (y - lcc.fn)
+	r := math.Hypot(dx, dy)
+	if lcc.n < 0 {
+		r, dx, dy = -r, -dx, -dy
+	}
+	if r == 0 {
+		return degrees(lcc.lonF), math.Copysign(90, lcc.n)
+	}
+
+	t := math.Pow(r/lcc.f, 1/lcc.n)
+	theta := math.Atan2(dx, dy)
+	return degrees(normalizeLon(theta/lcc.n + lcc.lonF)), degrees(phi2(t, lcc.e))
+}
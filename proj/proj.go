@@ -0,0 +1,200 @@
+// Package proj implements map projections in pure Go.
+//
+// Projections convert between geographic coordinates (longitude and latitude
+// in degrees) and projected coordinates (easting and northing, in metres
+// unless wrapped with WithUnits). Supported methods are Transverse Mercator
+// (including UTM), Polar Stereographic, Lambert Azimuthal Equal Area,
+// Lambert Conformal Conic, Albers Equal Area and Web Mercator.
+//
+// Datum shifts are not applied: geographic coordinates are interpreted on
+// the ellipsoid of each projection, which is accurate to a few metres for
+// modern datums such as WGS84, ETRS89 and NAD83.
+package proj
+
+import (
+	"math"
+)
+
+// Projection converts between geographic and projected coordinates.
+// Coordinates that cannot be converted yield NaN.
+type Projection interface {
+	// Forward converts longitude and latitude (degrees) to projected coordinates
+	Forward(lon, lat float64) (x, y float64)
+	// Inverse converts projected coordinates to longitude and latitude (degrees)
+	Inverse(x, y float64) (lon, lat float64)
+}
+
+// Transform converts projected coordinates from one projection to another
+func Transform(from, to Projection, x, y float64) (float64, float64) {
+	lon, lat := from.Inverse(x, y)
+	return to.Forward(lon, lat)
+}
+
+// Ellipsoid describes the figure of the earth
+type Ellipsoid struct {
+	SemiMajor     float64 // Semi-major axis in metres
+	InvFlattening float64 // Inverse flattening, 0 for a sphere
+}
+
+// Common ellipsoids
+var (
+	WGS84      = Ellipsoid{SemiMajor: 6378137, InvFlattening: 298.257223563}
+	GRS80      = Ellipsoid{SemiMajor: 6378137, InvFlattening: 298.257222101}
+	Clarke1866 = Ellipsoid{SemiMajor: 6378206.4, InvFlattening: 294.978698214}
+	Airy1830   = Ellipsoid{SemiMajor: 6377563.396, InvFlattening: 299.3249646}
+	Bessel1841 = Ellipsoid{SemiMajor: 6377397.155, InvFlattening: 299.1528128}
+	Intl1924   = Ellipsoid{SemiMajor: 6378388, InvFlattening: 297}
+	Krassowsky = Ellipsoid{SemiMajor: 6378245, InvFlattening: 298.3}
+	Sphere     = Ellipsoid{SemiMajor: 6371000}
+)
+
+// Flattening returns the flattening f = 1/InvFlattening
+func (e Ellipsoid) Flattening() float64 {
+	if e.InvFlattening == 0 {
+		return 0
+	}
+	return 1 / e.InvFlattening
+}
+
+// SemiMinor returns the semi-minor axis in metres
+func (e Ellipsoid) SemiMinor() float64 {
+	return e.SemiMajor * (1 - e.Flattening())
+}
+
+// Eccentricity returns the first eccentricity
+func (e Ellipsoid) Eccentricity() float64 {
+	f := e.Flattening()
+	return math.Sqrt(f * (2 - f))
+}
+
+// Geographic is the identity projection for geographic coordinate systems
+type Geographic struct{}
+
+// Forward implements Projection
+func (Geographic) Forward(lon, lat float64) (float64, float64) { return lon, lat }
+
+// Inverse implements Projection
+func (Geographic) Inverse(x, y float64) (float64, float64) { return x, y }
+
+// WebMercator is the spherical Mercator projection used by web maps (EPSG:3857)
+var WebMercator Projection = webMercator{}
+
+type webMercator struct{}
+
+const webMercatorRadius = 6378137
+
+// maxWebMercatorLat is the latitude at which Web Mercator becomes square
+const maxWebMercatorLat = 85.05112877980659
+
+// Forward implements Projection. Latitudes are clamped to ±85.0511°.
+func (webMercator) Forward(lon, lat float64) (float64, float64) {
+	lat = math.Max(-maxWebMercatorLat, math.Min(maxWebMercatorLat, lat))
+	return webMercatorRadius * radians(lon),
+		webMercatorRadius * math.Log(math.Tan(math.Pi/4+radians(lat)/2))
+}
+
+// Inverse implements Projection
+func (webMercator) Inverse(x, y float64) (float64, float64) {
+	return degrees(x / webMercatorRadius),
+		degrees(2*math.Atan(math.Exp(y/webMercatorRadius)) - math.Pi/2)
+}
+
+// scaledProjection expresses projected coordinates in units other than metres
+type scaledProjection struct {
+	Projection
+	metresPerUnit float64
+}
+
+// WithUnits returns a projection whose projected coordinates are expressed
+// in units of metresPerUnit metres (e.g. 0.3048 for international feet)
+func WithUnits(p Projection, metresPerUnit float64) Projection {
+	if metresPerUnit == 1 || metresPerUnit == 0 {
+		return p
+	}
+	return scaledProjection{Projection: p, metresPerUnit: metresPerUnit}
+}
+
+// Forward implements Projection
+func (p scaledProjection) Forward(lon, lat float64) (float64, float64) {
+	x, y := p.Projection.Forward(lon, lat)
+	return x / p.metresPerUnit, y / p.metresPerUnit
+}
+
+// Inverse implements Projection
+func (p scaledProjection) Inverse(x, y float64) (float64, float64) {
+	return p.Projection.Inverse(x*p.metresPerUnit, y*p.metresPerUnit)
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// normalizeLon wraps a longitude in radians into [-π, π]
+func normalizeLon(lon float64) float64 {
+	for lon > math.Pi {
+		lon -= 2 * math.Pi
+	}
+	for lon < -math.Pi {
+		lon += 2 * math.Pi
+	}
+	return lon
+}
+
+// tsfn computes the function t(φ) used by conformal projections
+func tsfn(phi, e float64) float64 {
+	sinPhi := e * math.Sin(phi)
+	return math.Tan(math.Pi/4-phi/2) / math.Pow((1-sinPhi)/(1+sinPhi), e/2)
+}
+
+// msfn computes m(φ) = cos φ / sqrt(1 - e² sin² φ)
+func msfn(phi, e float64) float64 {
+	sinPhi := math.Sin(phi)
+	return math.Cos(phi) / math.Sqrt(1-e*e*sinPhi*sinPhi)
+}
+
+// phi2 inverts tsfn by fixed-point iteration
+func phi2(ts, e float64) float64 {
+	phi := math.Pi/2 - 2*math.Atan(ts)
+	for i := 0; i < 15; i++ {
+		sinPhi := e * math.Sin(phi)
+		next := math.Pi/2 - 2*math.Atan(ts*math.Pow((1-sinPhi)/(1+sinPhi), e/2))
+		if math.Abs(next-phi) < 1e-14 {
+			return next
+		}
+		phi = next
+	}
+	return phi
+}
+
+// qsfn computes the authalic function q(φ) used by equal-area projections
+func qsfn(phi, e float64) float64 {
+	sinPhi := math.Sin(phi)
+	if e < 1e-10 {
+		return 2 * sinPhi
+	}
+	esin := e * sinPhi
+	return (1 - e*e) * (sinPhi/(1-esin*esin) - 1/(2*e)*math.Log((1-esin)/(1+esin)))
+}
+
+// authalicLat inverts qsfn by Newton iteration
+func authalicLat(q, e float64) float64 {
+	phi := math.Asin(math.Max(-1, math.Min(1, q/2)))
+	if e < 1e-10 {
+		return phi
+	}
+	for i := 0; i < 15; i++ {
+		sinPhi := math.Sin(phi)
+		cosPhi := math.Cos(phi)
+		if math.Abs(cosPhi) < 1e-12 {
+			return phi
+		}
+		esin := e * sinPhi
+		one := 1 - esin*esin
+		delta := one * one / (2 * cosPhi) * (q/(1-e*e) - sinPhi/one + 1/(2*e)*math.Log((1-esin)/(1+esin)))
+		phi += delta
+		if math.Abs(delta) < 1e-14 {
+			break
+		}
+	}
+	return phi
+}
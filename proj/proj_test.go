@@ -0,0 +1,197 @@
+package proj
+
+import (
+	"math"
+	"testing"
+)
+
+// Worked examples from EPSG Guidance Note 7-2 and Snyder (1987)
+func TestProjectionExamples(t *testing.T) {
+	usFoot := 1200.0 / 3937
+
+	tests := []struct {
+		name     string
+		proj     Projection
+		lon, lat float64
+		x, y     float64
+		tol      float64
+	}{
+		{
+			name: "Transverse Mercator (British National Grid)",
+			proj: NewTransverseMercator(Airy1830, -2, 49, 0.9996012717, 400000, -100000),
+			lon:  0.5, lat: 50.5,
+			x: 577274.99, y: 69740.50, tol: 0.01,
+		},
+		{
+			name: "Lambert Conic Conformal 2SP (Texas South Central)",
+			proj: WithUnits(NewLambertConformalConic2SP(Clarke1866, 28+23.0/60, 30+17.0/60, 27+50.0/60, -99, 2000000*usFoot, 0), usFoot),
+			lon:  -96, lat: 28.5,
+			x: 2963503.91, y: 254759.80, tol: 0.01,
+		},
+		{
+			name: "Lambert Conic Conformal 1SP (Jamaica)",
+			proj: NewLambertConformalConic1SP(Clarke1866, 18, -77, 1, 250000, 150000),
+			lon:  -(76 + 56.0/60 + 37.26/3600), lat: 17 + 55.0/60 + 55.80/3600,
+			x: 255966.58, y: 142493.51, tol: 0.01,
+		},
+		{
+			name: "Lambert Azimuthal Equal Area (ETRS89-LAEA)",
+			proj: NewLambertAzimuthalEqualArea(GRS80, 52, 10, 4321000, 3210000),
+			lon:  5, lat: 50,
+			x: 3962799.45, y: 2999718.85, tol: 0.01,
+		},
+		{
+			name: "Polar Stereographic A (UPS North)",
+			proj: NewPolarStereographicA(WGS84, 90, 0, 0.994, 2000000, 2000000),
+			lon:  44, lat: 73,
+			x: 3320416.75, y: 632668.43, tol: 0.01,
+		},
+		{
+			name: "Polar Stereographic B (Australian Antarctic)",
+			proj: NewPolarStereographicB(WGS84, -71, 70, 6000000, 6000000),
+			lon:  120, lat: -75,
+			x: 7255380.79, y: 7053389.56, tol: 0.01,
+		},
+		{
+			name: "Albers Equal Area (Snyder)",
+			proj: NewAlbersEqualArea(Clarke1866, 29.5, 45.5, 23, -96, 0, 0),
+			lon:  -75, lat: 35,
+			x: 1885472.7, y: 1535925.0, tol: 0.1,
+		},
+	}
+
+	for _, tt := range tests {
+		x, y := tt.proj.Forward(tt.lon, tt.lat)
+		if math.Abs(x-tt.x) > tt.tol || math.Abs(y-tt.y) > tt.tol {
+			t.Errorf("%s: Forward = (%.3f, %.3f), want (%.3f, %.3f)", tt.name, x, y, tt.x, tt.y)
+		}
+		lon, lat := tt.proj.Inverse(tt.x, tt.y)
+		if math.Abs(lon-tt.lon) > tt.tol*1e-5 || math.Abs(lat-tt.lat) > tt.tol*1e-5 { // ~1m per 1e-5 degrees
+			t.Errorf("%s: Inverse = (%.9f, %.9f), want (%.9f, %.9f)", tt.name, lon, lat, tt.lon, tt.lat)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	codes := []int{32633, 32756, 25832, 26915, 3857, 3031, 3413, 32661, 32761, 3035, 6931, 3575, 2154, 3034, 5070, 3577, 27700, 4326}
+	for _, code := range codes {
+		p, err := FromEPSG(code)
+		if err != nil {
+			t.Fatalf("FromEPSG(%d) failed: %v", code, err)
+		}
+
+		// Sample points around a representative location for each CRS
+		lon0, lat0 := 10.0, 50.0
+		switch code {
+		case 32756, 3577:
+			lon0, lat0 = 150, -30
+		case 26915, 5070, 3413:
+			lon0, lat0 = -93, 60
+		case 3031:
+			lon0, lat0 = 60, -75
+		case 32761:
+			lon0, lat0 = -30, -85
+		case 32661, 6931, 3575:
+			lon0, lat0 = 30, 80
+		case 2154, 27700:
+			lon0, lat0 = 1, 47
+		}
+
+		for dx := -2.0; dx <= 2; dx++ {
+			for dy := -2.0; dy <= 2; dy++ {
+				lon, lat := lon0+dx, lat0+dy
+				x, y := p.Forward(lon, lat)
+				gotLon, gotLat := p.Inverse(x, y)
+				if math.Abs(gotLon-lon) > 1e-8 || math.Abs(gotLat-lat) > 1e-8 {
+					t.Errorf("EPSG:%d round trip (%v, %v) -> (%v, %v) -> (%v, %v)", code, lon, lat, x, y, gotLon, gotLat)
+				}
+			}
+		}
+	}
+
+	if _, err := FromEPSG(1234); err == nil {
+		t.Error("expected error for unsupported code")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	utm, ok := LookupCRS(32618)
+	if !ok || utm.Name != "WGS 84 / UTM zone 18N" || utm.Geographic || utm.BaseCode != 4326 {
+		t.Fatalf("unexpected EPSG:32618 %+v", utm)
+	}
+	if utm.Datum.Code != 6326 || utm.Datum.Ellipsoid.Code != 7030 || utm.Params.LonOrigin != -75 {
+		t.Errorf("unexpected EPSG:32618 datum or parameters %+v", utm)
+	}
+	if a := utm.AreaOfUse; a.West != -78 || a.East != -72 || a.South != 0 || a.North != 84 {
+		t.Errorf("unexpected EPSG:32618 area of use %+v", a)
+	}
+
+	wgs84, ok := LookupCRS(4326)
+	if !ok || !wgs84.Geographic || wgs84.AxisOrder != AxisNorthEast || !IsGeographic(4326) {
+		t.Errorf("expected EPSG:4326 to be geographic with latitude first, got %+v", wgs84)
+	}
+	if bng, _ := LookupCRS(27700); bng.Params.Ellipsoid != Airy1830 || bng.AxisOrder != AxisEastNorth {
+		t.Errorf("expected the British National Grid on Airy 1830, got %+v", bng)
+	}
+
+	// Projected coordinates in US survey feet
+	p, err := FromEPSG(2263)
+	if err != nil {
+		t.Fatalf("FromEPSG(2263) failed: %v", err)
+	}
+	if x, _ := p.Forward(-74, 40.5); math.Abs(x-984250) > 0.01 {
+		t.Errorf("expected a false easting of 984250 ftUS on the central meridian, got %v", x)
+	}
+
+	for code, crs := range registry.crs {
+		if crs.Geographic {
+			continue
+		}
+		if _, err := New(crs.Params); err != nil {
+			t.Errorf("EPSG:%d: %v", code, err)
+		}
+	}
+	if _, ok := LookupCRS(1234); ok {
+		t.Error("expected no entry for an unregistered code")
+	}
+}
+
+func TestUTM(t *testing.T) {
+	p, err := UTM(33, true)
+	if err != nil {
+		t.Fatalf("UTM failed: %v", err)
+	}
+	// On the central meridian at the equator
+	if x, y := p.Forward(15, 0); math.Abs(x-500000) > 1e-6 || math.Abs(y) > 1e-6 {
+		t.Errorf("expected (500000, 0), got (%v, %v)", x, y)
+	}
+	// One degree of latitude along the central meridian, scaled by k0
+	if _, y := p.Forward(15, 1); math.Abs(y-110574.389*0.9996) > 0.01 {
+		t.Errorf("unexpected meridian distance %v", y)
+	}
+	if _, err := UTM(61, true); err == nil {
+		t.Error("expected error for invalid zone")
+	}
+}
+
+func TestEllipsoidDistance(t *testing.T) {
+	dms := func(d, m, s float64) float64 { return d + m/60 + s/3600 }
+
+	// Flinders Peak to Buninyong, the worked example of Vincenty's formulae
+	got := GRS80.Distance(dms(144, 25, 29.52440), -dms(37, 57, 3.72030), dms(143, 55, 35.38390), -dms(37, 39, 10.15610))
+	if math.Abs(got-54972.271) > 0.001 {
+		t.Errorf("expected 54972.271 m, got %v", got)
+	}
+
+	// One degree of latitude from the equator
+	if got := WGS84.Distance(15, 0, 15, 1); math.Abs(got-110574.389) > 0.001 {
+		t.Errorf("expected 110574.389 m, got %v", got)
+	}
+	if got := WGS84.Distance(10, 50, 10, 50); got != 0 {
+		t.Errorf("expected 0 for coincident points, got %v", got)
+	}
+	// Antipodal points fall back to the great circle
+	if got := WGS84.Distance(0, 0, 180, 0); math.Abs(got-20003931)/20003931 > 1e-3 {
+		t.Errorf("expected about 20003931 m between antipodes, got %v", got)
+	}
+}
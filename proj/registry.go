@@ -0,0 +1,255 @@
+package proj
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:generate go run gen_epsg.go -db /usr/share/proj/proj.db
+
+//go:embed epsg.csv
+var epsgCSV string
+
+// AxisOrder is the order of the coordinate axes of a CRS
+type AxisOrder int
+
+const (
+	AxisEastNorth AxisOrder = iota // Longitude or easting first
+	AxisNorthEast                  // Latitude or northing first
+)
+
+// AreaOfUse is the region in which a CRS is valid. West is greater than
+// East for areas crossing the antimeridian.
+type AreaOfUse struct {
+	Name  string
+	West  float64 // Degrees
+	South float64
+	East  float64
+	North float64
+}
+
+// EllipsoidInfo is a registered EPSG ellipsoid
+type EllipsoidInfo struct {
+	Code int
+	Name string
+	Ellipsoid
+}
+
+// DatumInfo is a registered EPSG geodetic datum
+type DatumInfo struct {
+	Code      int
+	Name      string
+	Ellipsoid EllipsoidInfo
+}
+
+// CRSInfo is a registered EPSG geographic or projected CRS
+type CRSInfo struct {
+	Code       int
+	Name       string
+	Geographic bool
+	BaseCode   int // Base geographic CRS of a projected CRS
+	Datum      DatumInfo
+
+	// Conversion of a projected CRS. Params.Ellipsoid is the datum's.
+	ConversionCode int
+	ConversionName string
+	Params         Params
+
+	UnitCode      int     // EPSG unit of measure of the coordinates
+	MetresPerUnit float64 // Size of the unit of projected coordinates
+	AxisOrder     AxisOrder
+	AreaOfUse     AreaOfUse
+}
+
+// metresPerUnit lists the supported linear units of projected CRSs
+var metresPerUnit = map[int]float64{
+	9001: 1,
+	9002: 0.3048,
+	9003: 1200.0 / 3937,
+}
+
+// registry is the parsed EPSG subset of epsg.csv
+var registry struct {
+	once       sync.Once
+	ellipsoids map[int]EllipsoidInfo
+	datums     map[int]DatumInfo
+	crs        map[int]CRSInfo
+}
+
+// LookupCRS returns a registered EPSG CRS
+func LookupCRS(code int) (CRSInfo, bool) {
+	loadRegistry()
+	c, ok := registry.crs[code]
+	return c, ok
+}
+
+// LookupDatum returns a registered EPSG geodetic datum
+func LookupDatum(code int) (DatumInfo, bool) {
+	loadRegistry()
+	d, ok := registry.datums[code]
+	return d, ok
+}
+
+// LookupEllipsoid returns a registered EPSG ellipsoid
+func LookupEllipsoid(code int) (EllipsoidInfo, bool) {
+	loadRegistry()
+	e, ok := registry.ellipsoids[code]
+	return e, ok
+}
+
+func loadRegistry() {
+	registry.once.Do(func() {
+		if err := parseRegistry(epsgCSV); err != nil {
+			panic(fmt.Sprintf("proj: invalid embedded EPSG registry: %v", err))
+		}
+	})
+}
+
+// parseRegistry parses the EPSG subset. Records reference only ellipsoids,
+// datums and base CRSs defined before them.
+func parseRegistry(data string) error {
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	registry.ellipsoids = make(map[int]EllipsoidInfo)
+	registry.datums = make(map[int]DatumInfo)
+	registry.crs = make(map[int]CRSInfo, len(records))
+	for _, rec := range records {
+		f := fields{record: rec}
+		switch kind := f.string(); kind {
+		case "ellipsoid":
+			e := EllipsoidInfo{Code: f.int(), Name: f.string()}
+			e.SemiMajor, e.InvFlattening = f.float(), f.float()
+			registry.ellipsoids[e.Code] = e
+		case "datum":
+			d := DatumInfo{Code: f.int(), Name: f.string()}
+			d.Ellipsoid = registry.ellipsoids[f.int()]
+			if d.Ellipsoid.Code == 0 {
+				return fmt.Errorf("datum %d: unknown ellipsoid", d.Code)
+			}
+			registry.datums[d.Code] = d
+		case "geographic":
+			c := CRSInfo{Code: f.int(), Name: f.string(), Geographic: true, UnitCode: 9122}
+			c.Datum = registry.datums[f.int()]
+			c.AxisOrder = f.axisOrder()
+			c.AreaOfUse = f.area()
+			if c.Datum.Code == 0 {
+				return fmt.Errorf("CRS %d: unknown datum", c.Code)
+			}
+			c.BaseCode = c.Code
+			registry.crs[c.Code] = c
+		case "projected":
+			c := CRSInfo{Code: f.int(), Name: f.string(), BaseCode: f.int(), UnitCode: f.int()}
+			base, ok := registry.crs[c.BaseCode]
+			if !ok || !base.Geographic {
+				return fmt.Errorf("CRS %d: unknown base CRS %d", c.Code, c.BaseCode)
+			}
+			if c.MetresPerUnit, ok = metresPerUnit[c.UnitCode]; !ok {
+				return fmt.Errorf("CRS %d: unsupported unit %d", c.Code, c.UnitCode)
+			}
+			c.Datum = base.Datum
+			c.AxisOrder = f.axisOrder()
+			c.ConversionCode, c.ConversionName = f.int(), f.string()
+			c.Params = Params{
+				Method:        f.method(),
+				Ellipsoid:     c.Datum.Ellipsoid.Ellipsoid,
+				LatOrigin:     f.float(),
+				LonOrigin:     f.float(),
+				StdParallel1:  f.float(),
+				StdParallel2:  f.float(),
+				Scale:         f.float(),
+				FalseEasting:  f.float(),
+				FalseNorthing: f.float(),
+			}
+			c.AreaOfUse = f.area()
+			registry.crs[c.Code] = c
+		default:
+			return fmt.Errorf("unknown record type %q", kind)
+		}
+		if f.err != nil {
+			return fmt.Errorf("record %v: %w", rec[:2], f.err)
+		}
+	}
+	return nil
+}
+
+// fields reads the fields of a registry record in order, keeping the first
+// error
+type fields struct {
+	record []string
+	next   int
+	err    error
+}
+
+func (f *fields) string() string {
+	if f.next >= len(f.record) {
+		if f.err == nil {
+			f.err = fmt.Errorf("too few fields")
+		}
+		return ""
+	}
+	f.next++
+	return f.record[f.next-1]
+}
+
+func (f *fields) int() int {
+	v, err := strconv.Atoi(f.string())
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+	return v
+}
+
+func (f *fields) float() float64 {
+	v, err := strconv.ParseFloat(f.string(), 64)
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+	return v
+}
+
+func (f *fields) axisOrder() AxisOrder {
+	switch s := f.string(); s {
+	case "east-north":
+		return AxisEastNorth
+	case "north-east":
+		return AxisNorthEast
+	default:
+		if f.err == nil {
+			f.err = fmt.Errorf("unknown axis order %q", s)
+		}
+		return AxisEastNorth
+	}
+}
+
+func (f *fields) method() Method {
+	methods := map[string]Method{
+		"tmerc":   MethodTransverseMercator,
+		"lcc1":    MethodLambertConformalConic1SP,
+		"lcc2":    MethodLambertConformalConic2SP,
+		"aea":     MethodAlbersEqualArea,
+		"laea":    MethodLambertAzimuthalEqualArea,
+		"stere_a": MethodPolarStereographicA,
+		"stere_b": MethodPolarStereographicB,
+		"webmerc": MethodWebMercator,
+	}
+	s := f.string()
+	m, ok := methods[s]
+	if !ok && f.err == nil {
+		f.err = fmt.Errorf("unknown method %q", s)
+	}
+	return m
+}
+
+func (f *fields) area() AreaOfUse {
+	return AreaOfUse{Name: f.string(), West: f.float(), South: f.float(), East: f.float(), North: f.float()}
+}
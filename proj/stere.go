@@ -0,0 +1,75 @@
+package proj
+
+import (
+	"math"
+)
+
+// polarStereographic implements the ellipsoidal Polar Stereographic
+// projection (EPSG methods 9810 and 9829)
+type polarStereographic struct {
+	e      float64
+	c      float64 // rho = c * t
+	lon0   float64
+	fe, fn float64
+	north  bool
+}
+
+// NewPolarStereographicA returns a Polar Stereographic projection defined by
+// the scale factor at the pole (variant A, e.g. UPS). latOrigin must be 90 or -90.
+func NewPolarStereographicA(e Ellipsoid, latOrigin, lonOrigin, scale, falseEasting, falseNorthing float64) Projection {
+	ecc := e.Eccentricity()
+	return &polarStereographic{
+		e:     ecc,
+		c:     2 * e.SemiMajor * scale / math.Sqrt(math.Pow(1+ecc, 1+ecc)*math.Pow(1-ecc, 1-ecc)),
+		lon0:  radians(lonOrigin),
+		fe:    falseEasting,
+		fn:    falseNorthing,
+		north: latOrigin > 0,
+	}
+}
+
+// NewPolarStereographicB returns a Polar Stereographic projection defined by
+// the latitude of true scale (variant B, e.g. NSIDC and Antarctic grids).
+// The hemisphere is taken from the sign of latTrueScale.
+func NewPolarStereographicB(e Ellipsoid, latTrueScale, lonOrigin, falseEasting, falseNorthing float64) Projection {
+	ecc := e.Eccentricity()
+	phiF := math.Abs(radians(latTrueScale))
+
+	ps := &polarStereographic{
+		e:     ecc,
+		lon0:  radians(lonOrigin),
+		fe:    falseEasting,
+		fn:    falseNorthing,
+		north: latTrueScale >= 0,
+	}
+	if math.Abs(phiF-math.Pi/2) < 1e-12 {
+		ps.c = 2 * e.SemiMajor / math.Sqrt(math.Pow(1+ecc, 1+ecc)*math.Pow(1-ecc, 1-ecc))
+	} else {
+		ps.c = e.SemiMajor * msfn(phiF, ecc) / tsfn(phiF, ecc)
+	}
+	return ps
+}
+
+// Forward implements Projection
+func (ps *polarStereographic) Forward(lon, lat float64) (float64, float64) {
+	phi := radians(lat)
+	if !ps.north {
+		phi = -phi
+	}
+	rho := ps.c * tsfn(phi, ps.e)
+	sinL, cosL := math.Sincos(normalizeLon(radians(lon) - ps.lon0))
+	if ps.north {
+		return ps.fe + rho*sinL, ps.fn - rho*cosL
+	}
+	return ps.fe + rho*sinL, ps.fn + rho*cosL
+}
+
+// Inverse implements Projection
+func (ps *polarStereographic) Inverse(x, y float64) (float64, float64) {
+	dx, dy := x-ps.fe, y-ps.fn
+	phi := phi2(math.Hypot(dx, dy)/ps.c, ps.e)
+	if ps.north {
+		return degrees(normalizeLon(ps.lon0 + math.Atan2(dx, -dy))), degrees(phi)
+	}
+	return degrees(normalizeLon(ps.lon0 + math.Atan2(dx, dy))), -degrees(phi)
+}
@@ -0,0 +1,140 @@
+package proj
+
+import (
+	"fmt"
+	"math"
+)
+
+// transverseMercator implements the ellipsoidal Transverse Mercator projection
+// using Krüger's series to sixth order in n (Karney 2011), which is accurate
+// to well below a millimetre within several thousand kilometres of the
+// central meridian.
+type transverseMercator struct {
+	lon0, fe, fn float64
+	k0A          float64 // scale factor times rectifying radius
+	e            float64
+	y0           float64 // northing of the latitude of origin on the central meridian
+	alpha, beta  [6]float64
+	delta        [6]float64
+}
+
+// NewTransverseMercator returns a Transverse Mercator projection with the
+// given natural origin (degrees), scale factor and false easting/northing
+// (metres).
+func NewTransverseMercator(e Ellipsoid, lonOrigin, latOrigin, scale, falseEasting, falseNorthing float64) Projection {
+	f := e.Flattening()
+	n := f / (2 - f)
+	n2 := n * n
+	n3 := n2 * n
+	n4 := n3 * n
+	n5 := n4 * n
+	n6 := n5 * n
+
+	tm := &transverseMercator{
+		lon0: radians(lonOrigin),
+		fe:   falseEasting,
+		fn:   falseNorthing,
+		k0A:  scale * e.SemiMajor / (1 + n) * (1 + n2/4 + n4/64 + n6/256),
+		e:    e.Eccentricity(),
+		alpha: [6]float64{
+			n/2 - 2*n2/3 + 5*n3/16 + 41*n4/180 - 127*n5/288 + 7891*n6/37800,
+			13*n2/48 - 3*n3/5 + 557*n4/1440 + 281*n5/630 - 1983433*n6/1935360,
+			61*n3/240 - 103*n4/140 + 15061*n5/26880 + 167603*n6/181440,
+			49561*n4/161280 - 179*n5/168 + 6601661*n6/7257600,
+			34729*n5/80640 - 3418889*n6/1995840,
+			212378941 * n6 / 319334400,
+		},
+		beta: [6]float64{
+			n/2 - 2*n2/3 + 37*n3/96 - n4/360 - 81*n5/512 + 96199*n6/604800,
+			n2/48 + n3/15 - 437*n4/1440 + 46*n5/105 - 1118711*n6/3870720,
+			17*n3/480 - 37*n4/840 - 209*n5/4480 + 5569*n6/90720,
+			4397*n4/161280 - 11*n5/504 - 830251*n6/7257600,
+			4583*n5/161280 - 108847*n6/3991680,
+			20648693 * n6 / 638668800,
+		},
+		delta: [6]float64{
+			2*n - 2*n2/3 - 2*n3 + 116*n4/45 + 26*n5/45 - 2854*n6/675,
+			7*n2/3 - 8*n3/5 - 227*n4/45 + 2704*n5/315 + 2323*n6/945,
+			56*n3/15 - 136*n4/35 - 1262*n5/105 + 73814*n6/2835,
+			4279*n4/630 - 332*n5/35 - 399572*n6/14175,
+			4174*n5/315 - 144838*n6/6237,
+			601676 * n6 / 22275,
+		},
+	}
+
+	// Northing of the latitude of origin, subtracted so it maps to falseNorthing
+	if latOrigin != 0 {
+		xi := math.Atan(tm.conformalTan(radians(latOrigin)))
+		tm.y0 = xi
+		for j := 1; j <= 6; j++ {
+			tm.y0 += tm.alpha[j-1] * math.Sin(2*float64(j)*xi)
+		}
+		tm.y0 *= tm.k0A
+	}
+
+	return tm
+}
+
+// UTM returns the Universal Transverse Mercator projection on WGS84 for the
+// given zone (1-60) and hemisphere
+func UTM(zone int, north bool) (Projection, error) {
+	if zone < 1 || zone > 60 {
+		return nil, fmt.Errorf("invalid UTM zone %d", zone)
+	}
+	falseNorthing := 0.0
+	if !north {
+		falseNorthing = 10000000
+	}
+	return NewTransverseMercator(WGS84, float64(zone)*6-183, 0, 0.9996, 500000, falseNorthing), nil
+}
+
+// conformalTan returns the tangent of the conformal latitude
+func (tm *transverseMercator) conformalTan(phi float64) float64 {
+	sinPhi := math.Sin(phi)
+	return math.Sinh(math.Atanh(sinPhi) - tm.e*math.Atanh(tm.e*sinPhi))
+}
+
+// Forward implements Projection
+func (tm *transverseMercator) Forward(lon, lat float64) (float64, float64) {
+	if math.Abs(lat) > 90 {
+		return math.NaN(), math.NaN()
+	}
+	dLon := normalizeLon(radians(lon) - tm.lon0)
+	t := tm.conformalTan(radians(lat))
+
+	xi := math.Atan2(t, math.Cos(dLon))
+	eta := math.Atanh(math.Sin(dLon) / math.Sqrt(1+t*t))
+
+	x, y := eta, xi
+	for j := 1; j <= 6; j++ {
+		a := tm.alpha[j-1]
+		k := 2 * float64(j)
+		x += a * math.Cos(k*xi) * math.Sinh(k*eta)
+		y += a * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+
+	return tm.fe + tm.k0A*x, tm.fn + tm.k0A*y - tm.y0
+}
+
+// Inverse implements Projection
+func (tm *transverseMercator) Inverse(x, y float64) (float64, float64) {
+	xi := (y - tm.fn + tm.y0) / tm.k0A
+	eta := (x - tm.fe) / tm.k0A
+
+	xiP, etaP := xi, eta
+	for j := 1; j <= 6; j++ {
+		b := tm.beta[j-1]
+		k := 2 * float64(j)
+		xiP -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaP -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	chi := math.Asin(math.Sin(xiP) / math.Cosh(etaP)) // Conformal latitude
+	phi := chi
+	for j := 1; j <= 6; j++ {
+		phi += tm.delta[j-1] * math.Sin(2*float64(j)*chi)
+	}
+	lon := tm.lon0 + math.Atan2(math.Sinh(etaP), math.Cos(xiP))
+
+	return degrees(normalizeLon(lon)), degrees(phi)
+}
@@ -0,0 +1,92 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// Projection returns the map projection of the image's CRS, for converting
+// between its coordinates and longitude/latitude. Known EPSG codes are
+// resolved directly; other (user-defined) projected CRS are built from the
+// projection parameters in the GeoKeys.
+func (c *COG) Projection() (proj.Projection, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image metadata")
+	}
+	return projectionFromMetadata(c.metadata[0])
+}
+
+// LonLatBounds returns the bounding box of the main image in longitude and
+// latitude (degrees)
+func (c *COG) LonLatBounds() (orb.Bound, error) {
+	p, err := c.Projection()
+	if err != nil {
+		return orb.Bound{}, err
+	}
+	return unprojectBound(c.Bounds(), p), nil
+}
+
+// projectionFromMetadata resolves the projection of an image
+func projectionFromMetadata(meta *GeoTIFFMetadata) (proj.Projection, error) {
+	if code, err := ParseEPSGCode(meta.CRS); err == nil {
+		if p, err := proj.FromEPSG(code); err == nil {
+			return p, nil
+		}
+	}
+
+	crs, err := crsFromGeoKeys(crsGeoKeys(meta))
+	if err != nil {
+		if meta.CRS != "" {
+			return nil, fmt.Errorf("unsupported CRS %s: %w", meta.CRS, err)
+		}
+		return nil, err
+	}
+	return crs.Projection()
+}
+
+// projectBound projects a longitude/latitude bound and returns the envelope
+// of the result. Edges are densified so curved projected edges are enclosed.
+func projectBound(bound orb.Bound, p proj.Projection) orb.Bound {
+	return transformBound(bound, p.Forward)
+}
+
+// unprojectBound converts a projected bound to a longitude/latitude envelope
+func unprojectBound(bound orb.Bound, p proj.Projection) orb.Bound {
+	return transformBound(bound, p.Inverse)
+}
+
+// transformBound transforms points along the edges of bound and returns
+// their envelope, ignoring points that cannot be transformed
+func transformBound(bound orb.Bound, transform func(x, y float64) (float64, float64)) orb.Bound {
+	const steps = 16
+
+	result := orb.Bound{Min: orb.Point{math.Inf(1), math.Inf(1)}, Max: orb.Point{math.Inf(-1), math.Inf(-1)}}
+	add := func(x, y float64) {
+		tx, ty := transform(x, y)
+		if math.IsNaN(tx) || math.IsNaN(ty) || math.IsInf(tx, 0) || math.IsInf(ty, 0) {
+			return
+		}
+		result.Min[0] = math.Min(result.Min[0], tx)
+		result.Min[1] = math.Min(result.Min[1], ty)
+		result.Max[0] = math.Max(result.Max[0], tx)
+		result.Max[1] = math.Max(result.Max[1], ty)
+	}
+
+	width := bound.Max[0] - bound.Min[0]
+	height := bound.Max[1] - bound.Min[1]
+	for i := 0; i <= steps; i++ {
+		f := float64(i) / steps
+		add(bound.Min[0]+f*width, bound.Min[1])
+		add(bound.Min[0]+f*width, bound.Max[1])
+		add(bound.Min[0], bound.Min[1]+f*height)
+		add(bound.Max[0], bound.Min[1]+f*height)
+	}
+
+	if math.IsInf(result.Min[0], 0) {
+		return orb.Bound{}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog/proj"
+)
+
+func TestProjectionFromGeoKeys(t *testing.T) {
+	// User-defined Transverse Mercator in feet, parameters in GeoDoubleParams
+	tr := testRaster{
+		Width: 4, Height: 4, Origin: [2]float64{1000, 2000},
+		GeoKeys: []uint16{
+			GTModelTypeGeoKey, 0, 1, GTModelTypeProjected,
+			ProjectedCSTypeGeoKey, 0, 1, 32767,
+			ProjCoordTransGeoKey, 0, 1, CTTransverseMercator,
+			ProjLinearUnitsGeoKey, 0, 1, uint16(unitFoot.Code),
+			ProjNatOriginLongGeoKey, TagGeoDoubleParams, 1, 0,
+			ProjNatOriginLatGeoKey, TagGeoDoubleParams, 1, 1,
+			ProjScaleAtNatOriginGeoKey, TagGeoDoubleParams, 1, 2,
+			ProjFalseEastingGeoKey, TagGeoDoubleParams, 1, 3,
+		},
+		ExtraTags: []testTag{doublesTag(TagGeoDoubleParams, 15, 0, 0.9996, 500000/0.3048)},
+	}
+	c := tr.cog(t)
+
+	p, err := c.Projection()
+	if err != nil {
+		t.Fatalf("Projection failed: %v", err)
+	}
+	utm, _ := proj.UTM(33, true)
+	wantX, wantY := utm.Forward(16, 45)
+	x, y := p.Forward(16, 45)
+	if math.Abs(x*0.3048-wantX) > 1e-6 || math.Abs(y*0.3048-wantY) > 1e-6 {
+		t.Errorf("expected UTM 33N in feet, got (%v, %v) for (%v, %v) m", x, y, wantX, wantY)
+	}
+
+	unsupported := testRaster{Width: 4, Height: 4, EPSG: 2000}
+	if _, err := unsupported.cog(t).Projection(); err == nil {
+		t.Error("expected error for unsupported EPSG code")
+	}
+}
+
+func TestReadTileUTM(t *testing.T) {
+	// 10 km square of 100 m pixels in UTM zone 33N, values encode the column
+	tr := testRaster{
+		Width: 100, Height: 100, DataType: DTSShort, TileSize: 32, EPSG: 32633,
+		Origin: [2]float64{495000, 5005000}, PixelSize: [2]float64{100, 100},
+		Value: func(band, x, y int) float64 { return float64(x) },
+	}
+	c := tr.cog(t)
+
+	lonLat, err := c.LonLatBounds()
+	if err != nil {
+		t.Fatalf("LonLatBounds failed: %v", err)
+	}
+	if !lonLat.Contains(orb.Point{15, 45.15}) {
+		t.Errorf("expected lon/lat bounds %v to contain (15, 45.15)", lonLat)
+	}
+
+	// Zoom 12 tile containing 15°E 45.15°N
+	tile := maptile.At(orb.Point{15, 45.15}, 12)
+	data, err := c.ReadTile(tile, 64)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if data.Width != 64 || data.Height != 64 {
+		t.Fatalf("expected 64x64 tile, got %dx%d", data.Width, data.Height)
+	}
+	utm, _ := proj.UTM(33, true)
+	if x, y := utm.Forward(15, 45.15); !data.Bounds.Contains(orb.Point{x, y}) {
+		t.Errorf("expected tile bounds in UTM coordinates, got %v", data.Bounds)
+	}
+	if data.IsNoData(0, 32, 32) {
+		t.Error("expected the tile centre to be inside the image")
+	}
+	// Columns increase from west to east
+	if data.At(0, 0, 32) >= data.At(0, 63, 32) {
+		t.Errorf("expected values to increase eastwards, got %d and %d", data.At(0, 0, 32), data.At(0, 63, 32))
+	}
+}
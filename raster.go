@@ -0,0 +1,306 @@
+package gocog
+
+import (
+	"fmt"
+
+	"github.com/paulmach/orb"
+)
+
+// Whole-raster operations on RasterData. All operations return new
+// RasterData and leave the receiver unchanged. Bounds are kept consistent
+// assuming north-up data, where each pixel covers an equal share of Bounds.
+
+// Clone returns a deep copy of the raster
+func (r *RasterData) Clone() *RasterData {
+	clone := *r
+	clone.Data = append([]uint64(nil), r.Data...)
+	if r.Mask != nil {
+		clone.Mask = append([]uint8(nil), r.Mask...)
+	}
+	return &clone
+}
+
+// withData returns a copy of the raster's metadata with new dimensions and
+// data. The mask is not carried over.
+func (r *RasterData) withData(data []uint64, width, height, bands int, bounds orb.Bound) *RasterData {
+	return &RasterData{
+		Data:      data,
+		Width:     width,
+		Height:    height,
+		Bands:     bands,
+		Bounds:    bounds,
+		DataType:  r.DataType,
+		NoData:    r.NoData,
+		HasNoData: r.HasNoData,
+		Overview:  r.Overview,
+	}
+}
+
+// pixelSize returns the size of one pixel in Bounds units
+func (r *RasterData) pixelSize() (float64, float64) {
+	if r.Width == 0 || r.Height == 0 {
+		return 0, 0
+	}
+	return (r.Bounds.Max[0] - r.Bounds.Min[0]) / float64(r.Width),
+		(r.Bounds.Max[1] - r.Bounds.Min[1]) / float64(r.Height)
+}
+
+// windowBounds returns the bounds of a pixel window (which may extend
+// beyond the raster) relative to the raster's bounds
+func (r *RasterData) windowBounds(x, y, width, height int) orb.Bound {
+	px, py := r.pixelSize()
+	return orb.Bound{
+		Min: orb.Point{r.Bounds.Min[0] + float64(x)*px, r.Bounds.Max[1] - float64(y+height)*py},
+		Max: orb.Point{r.Bounds.Min[0] + float64(x+width)*px, r.Bounds.Max[1] - float64(y)*py},
+	}
+}
+
+// Crop returns the pixels inside rect. The rectangle must lie within the raster.
+func (r *RasterData) Crop(rect Rectangle) (*RasterData, error) {
+	if rect.Width <= 0 || rect.Height <= 0 {
+		return nil, fmt.Errorf("rectangle dimensions must be positive")
+	}
+	if rect.X < 0 || rect.Y < 0 || rect.X+rect.Width > r.Width || rect.Y+rect.Height > r.Height {
+		return nil, fmt.Errorf("rectangle extends beyond raster (%dx%d)", r.Width, r.Height)
+	}
+
+	data := make([]uint64, rect.Width*rect.Height*r.Bands)
+	rowLen := rect.Width * r.Bands
+	for row := 0; row < rect.Height; row++ {
+		src := r.Index(0, rect.X, rect.Y+row)
+		copy(data[row*rowLen:(row+1)*rowLen], r.Data[src:src+rowLen])
+	}
+
+	result := r.withData(data, rect.Width, rect.Height, r.Bands, r.windowBounds(rect.X, rect.Y, rect.Width, rect.Height))
+	if r.Mask != nil {
+		result.Mask = make([]uint8, rect.Width*rect.Height)
+		for row := 0; row < rect.Height; row++ {
+			src := (rect.Y+row)*r.Width + rect.X
+			copy(result.Mask[row*rect.Width:(row+1)*rect.Width], r.Mask[src:src+rect.Width])
+		}
+	}
+	return result, nil
+}
+
+// ToBSQ returns the samples in band-sequential (BSQ) layout:
+// index = band * Width * Height + y * Width + x
+func (r *RasterData) ToBSQ() []uint64 {
+	planeSize := r.Width * r.Height
+	result := make([]uint64, planeSize*r.Bands)
+	for i := 0; i < planeSize; i++ {
+		base := i * r.Bands
+		for b := 0; b < r.Bands; b++ {
+			result[b*planeSize+i] = r.Data[base+b]
+		}
+	}
+	return result
+}
+
+// NewRasterDataFromBSQ creates raster data from samples in band-sequential
+// (BSQ) layout, converting them to the band-interleaved-by-pixel layout
+// used by RasterData.
+func NewRasterDataFromBSQ(data []uint64, width, height, bands int, dataType DataType) (*RasterData, error) {
+	planeSize := width * height
+	if width <= 0 || height <= 0 || bands <= 0 {
+		return nil, fmt.Errorf("raster dimensions must be positive")
+	}
+	if len(data) != planeSize*bands {
+		return nil, fmt.Errorf("expected %d samples, got %d", planeSize*bands, len(data))
+	}
+
+	result := make([]uint64, len(data))
+	for b := 0; b < bands; b++ {
+		plane := data[b*planeSize : (b+1)*planeSize]
+		for i, v := range plane {
+			result[i*bands+b] = v
+		}
+	}
+
+	return &RasterData{Data: result, Width: width, Height: height, Bands: bands, DataType: dataType}, nil
+}
+
+// SelectBands returns a raster containing only the given bands (0-based), in order.
+func (r *RasterData) SelectBands(bands ...int) (*RasterData, error) {
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("no bands selected")
+	}
+	for _, b := range bands {
+		if b < 0 || b >= r.Bands {
+			return nil, fmt.Errorf("band %d out of range (raster has %d bands)", b, r.Bands)
+		}
+	}
+
+	pixels := r.Width * r.Height
+	data := make([]uint64, pixels*len(bands))
+	for i := 0; i < pixels; i++ {
+		for j, b := range bands {
+			data[i*len(bands)+j] = r.Data[i*r.Bands+b]
+		}
+	}
+
+	result := r.withData(data, r.Width, r.Height, len(bands), r.Bounds)
+	result.Mask = r.Mask
+	return result, nil
+}
+
+// StackBands combines the bands of several rasters into one raster, in order.
+// All rasters must have the same dimensions and data type; the bounds and
+// NoData value are taken from the first raster. A pixel masked in any
+// raster is masked in the result.
+func StackBands(rasters ...*RasterData) (*RasterData, error) {
+	if len(rasters) == 0 {
+		return nil, fmt.Errorf("no rasters to stack")
+	}
+
+	first := rasters[0]
+	totalBands := 0
+	for i, r := range rasters {
+		if r.Width != first.Width || r.Height != first.Height {
+			return nil, fmt.Errorf("raster %d: size %dx%d does not match %dx%d", i, r.Width, r.Height, first.Width, first.Height)
+		}
+		if r.DataType != first.DataType {
+			return nil, fmt.Errorf("raster %d: data type %d does not match %d", i, r.DataType, first.DataType)
+		}
+		totalBands += r.Bands
+	}
+
+	pixels := first.Width * first.Height
+	data := make([]uint64, pixels*totalBands)
+	offset := 0
+	for _, r := range rasters {
+		for i := 0; i < pixels; i++ {
+			copy(data[i*totalBands+offset:i*totalBands+offset+r.Bands], r.Data[i*r.Bands:(i+1)*r.Bands])
+		}
+		offset += r.Bands
+	}
+
+	result := first.withData(data, first.Width, first.Height, totalBands, first.Bounds)
+	for _, r := range rasters {
+		if r.Mask == nil {
+			continue
+		}
+		if result.Mask == nil {
+			result.Mask = append([]uint8(nil), r.Mask...)
+			continue
+		}
+		for i, m := range r.Mask {
+			if m == 0 {
+				result.Mask[i] = 0
+			}
+		}
+	}
+	return result, nil
+}
+
+// Pad adds the given number of pixels on each side, filled with the raw
+// sample value fill (e.g. Float64ToSample(noData, r.DataType)). Bounds are
+// extended accordingly. If the raster has a mask, the padding is masked.
+func (r *RasterData) Pad(left, top, right, bottom int, fill uint64) (*RasterData, error) {
+	if left < 0 || top < 0 || right < 0 || bottom < 0 {
+		return nil, fmt.Errorf("padding must be non-negative")
+	}
+
+	width := r.Width + left + right
+	height := r.Height + top + bottom
+	data := make([]uint64, width*height*r.Bands)
+	if fill != 0 {
+		for i := range data {
+			data[i] = fill
+		}
+	}
+
+	rowLen := r.Width * r.Bands
+	for row := 0; row < r.Height; row++ {
+		dst := ((row+top)*width + left) * r.Bands
+		copy(data[dst:dst+rowLen], r.Data[row*rowLen:(row+1)*rowLen])
+	}
+
+	result := r.withData(data, width, height, r.Bands, r.windowBounds(-left, -top, width, height))
+	if r.Mask != nil {
+		result.Mask = make([]uint8, width*height)
+		for row := 0; row < r.Height; row++ {
+			dst := (row+top)*width + left
+			copy(result.Mask[dst:dst+r.Width], r.Mask[row*r.Width:(row+1)*r.Width])
+		}
+	}
+	return result, nil
+}
+
+// PadTo pads the right and bottom edges so the raster is width x height,
+// e.g. to complete a partial edge tile.
+func (r *RasterData) PadTo(width, height int, fill uint64) (*RasterData, error) {
+	if width < r.Width || height < r.Height {
+		return nil, fmt.Errorf("target size %dx%d is smaller than raster (%dx%d)", width, height, r.Width, r.Height)
+	}
+	return r.Pad(0, 0, width-r.Width, height-r.Height, fill)
+}
+
+// transform builds a new raster by mapping each destination pixel to a source pixel
+func (r *RasterData) transform(width, height int, source func(x, y int) (int, int)) *RasterData {
+	data := make([]uint64, width*height*r.Bands)
+	var mask []uint8
+	if r.Mask != nil {
+		mask = make([]uint8, width*height)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := source(x, y)
+			src := r.Index(0, sx, sy)
+			dst := (y*width + x) * r.Bands
+			copy(data[dst:dst+r.Bands], r.Data[src:src+r.Bands])
+			if mask != nil {
+				mask[y*width+x] = r.Mask[sy*r.Width+sx]
+			}
+		}
+	}
+	result := r.withData(data, width, height, r.Bands, r.Bounds)
+	result.Mask = mask
+	return result
+}
+
+// FlipHorizontal mirrors the raster left to right. Bounds are unchanged.
+func (r *RasterData) FlipHorizontal() *RasterData {
+	return r.transform(r.Width, r.Height, func(x, y int) (int, int) {
+		return r.Width - 1 - x, y
+	})
+}
+
+// FlipVertical mirrors the raster top to bottom. Bounds are unchanged.
+func (r *RasterData) FlipVertical() *RasterData {
+	result := r.withData(make([]uint64, len(r.Data)), r.Width, r.Height, r.Bands, r.Bounds)
+	rowLen := r.Width * r.Bands
+	for row := 0; row < r.Height; row++ {
+		src := (r.Height - 1 - row) * rowLen
+		copy(result.Data[row*rowLen:(row+1)*rowLen], r.Data[src:src+rowLen])
+		if r.Mask != nil {
+			if result.Mask == nil {
+				result.Mask = make([]uint8, len(r.Mask))
+			}
+			srcRow := (r.Height - 1 - row) * r.Width
+			copy(result.Mask[row*r.Width:(row+1)*r.Width], r.Mask[srcRow:srcRow+r.Width])
+		}
+	}
+	return result
+}
+
+// Rotate90 rotates the raster 90 degrees clockwise. Bounds are unchanged:
+// the data still covers the same area, but is no longer north-up.
+func (r *RasterData) Rotate90() *RasterData {
+	return r.transform(r.Height, r.Width, func(x, y int) (int, int) {
+		return y, r.Height - 1 - x
+	})
+}
+
+// Rotate180 rotates the raster by 180 degrees. Bounds are unchanged.
+func (r *RasterData) Rotate180() *RasterData {
+	return r.transform(r.Width, r.Height, func(x, y int) (int, int) {
+		return r.Width - 1 - x, r.Height - 1 - y
+	})
+}
+
+// Rotate270 rotates the raster 90 degrees counter-clockwise. Bounds are unchanged.
+func (r *RasterData) Rotate270() *RasterData {
+	return r.transform(r.Height, r.Width, func(x, y int) (int, int) {
+		return r.Width - 1 - y, x
+	})
+}
@@ -0,0 +1,148 @@
+package gocog
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+// sequenceRaster returns a width x height raster whose samples count up from 0
+func sequenceRaster(width, height, bands int) *RasterData {
+	data := make([]uint64, width*height*bands)
+	for i := range data {
+		data[i] = uint64(i)
+	}
+	return &RasterData{
+		Data: data, Width: width, Height: height, Bands: bands, DataType: DTSShort,
+		Bounds: orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{float64(width) * 10, float64(height) * 10}},
+	}
+}
+
+func TestRasterDataCrop(t *testing.T) {
+	r := sequenceRaster(4, 3, 2)
+
+	crop, err := r.Crop(Rectangle{X: 1, Y: 1, Width: 2, Height: 2})
+	if err != nil {
+		t.Fatalf("Crop failed: %v", err)
+	}
+	if crop.Width != 2 || crop.Height != 2 || crop.Bands != 2 {
+		t.Fatalf("unexpected crop size %dx%dx%d", crop.Width, crop.Height, crop.Bands)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if crop.At(1, x, y) != r.At(1, x+1, y+1) {
+				t.Errorf("crop pixel (%d,%d) mismatch", x, y)
+			}
+		}
+	}
+	want := orb.Bound{Min: orb.Point{10, 0}, Max: orb.Point{30, 20}}
+	if crop.Bounds != want {
+		t.Errorf("expected bounds %v, got %v", want, crop.Bounds)
+	}
+
+	if _, err := r.Crop(Rectangle{X: 3, Y: 0, Width: 2, Height: 1}); err == nil {
+		t.Error("expected error for rectangle beyond raster")
+	}
+}
+
+func TestRasterDataBSQ(t *testing.T) {
+	r := sequenceRaster(3, 2, 3)
+
+	bsq := r.ToBSQ()
+	if bsq[0] != 0 || bsq[1] != 3 || bsq[6] != 1 || bsq[12] != 2 {
+		t.Errorf("unexpected BSQ layout %v", bsq)
+	}
+
+	back, err := NewRasterDataFromBSQ(bsq, 3, 2, 3, DTSShort)
+	if err != nil {
+		t.Fatalf("NewRasterDataFromBSQ failed: %v", err)
+	}
+	if !reflect.DeepEqual(back.Data, r.Data) {
+		t.Error("BSQ round trip changed data")
+	}
+
+	if _, err := NewRasterDataFromBSQ(bsq[:5], 3, 2, 3, DTSShort); err == nil {
+		t.Error("expected error for short data")
+	}
+}
+
+func TestStackAndSelectBands(t *testing.T) {
+	a := sequenceRaster(2, 2, 1)
+	b := sequenceRaster(2, 2, 2)
+
+	stacked, err := StackBands(a, b)
+	if err != nil {
+		t.Fatalf("StackBands failed: %v", err)
+	}
+	if stacked.Bands != 3 {
+		t.Fatalf("expected 3 bands, got %d", stacked.Bands)
+	}
+	if stacked.At(0, 1, 1) != a.At(0, 1, 1) || stacked.At(2, 1, 1) != b.At(1, 1, 1) {
+		t.Error("stacked band values mismatch")
+	}
+
+	selected, err := stacked.SelectBands(2, 0)
+	if err != nil {
+		t.Fatalf("SelectBands failed: %v", err)
+	}
+	if selected.At(0, 1, 0) != b.At(1, 1, 0) || selected.At(1, 1, 0) != a.At(0, 1, 0) {
+		t.Error("selected band values mismatch")
+	}
+
+	if _, err := StackBands(a, sequenceRaster(3, 2, 1)); err == nil {
+		t.Error("expected error for mismatched sizes")
+	}
+}
+
+func TestRasterDataPad(t *testing.T) {
+	r := sequenceRaster(2, 2, 1)
+
+	padded, err := r.Pad(1, 2, 0, 1, 99)
+	if err != nil {
+		t.Fatalf("Pad failed: %v", err)
+	}
+	if padded.Width != 3 || padded.Height != 5 {
+		t.Fatalf("unexpected padded size %dx%d", padded.Width, padded.Height)
+	}
+	if padded.At(0, 0, 0) != 99 || padded.At(0, 1, 2) != r.At(0, 0, 0) || padded.At(0, 2, 3) != r.At(0, 1, 1) {
+		t.Error("padded values mismatch")
+	}
+	want := orb.Bound{Min: orb.Point{-10, -10}, Max: orb.Point{20, 40}}
+	if padded.Bounds != want {
+		t.Errorf("expected bounds %v, got %v", want, padded.Bounds)
+	}
+
+	padded, err = r.PadTo(4, 4, 0)
+	if err != nil || padded.Width != 4 || padded.At(0, 1, 1) != r.At(0, 1, 1) {
+		t.Errorf("PadTo failed: %v", err)
+	}
+	if _, err := r.PadTo(1, 1, 0); err == nil {
+		t.Error("expected error when padding to a smaller size")
+	}
+}
+
+func TestRasterDataFlipRotate(t *testing.T) {
+	// 0 1 2
+	// 3 4 5
+	r := sequenceRaster(3, 2, 1)
+
+	check := func(name string, got *RasterData, width int, want []uint64) {
+		t.Helper()
+		if got.Width != width || !reflect.DeepEqual(got.Data, want) {
+			t.Errorf("%s: got %dx%d %v, want width %d %v", name, got.Width, got.Height, got.Data, width, want)
+		}
+	}
+
+	check("FlipHorizontal", r.FlipHorizontal(), 3, []uint64{2, 1, 0, 5, 4, 3})
+	check("FlipVertical", r.FlipVertical(), 3, []uint64{3, 4, 5, 0, 1, 2})
+	check("Rotate90", r.Rotate90(), 2, []uint64{3, 0, 4, 1, 5, 2})
+	check("Rotate180", r.Rotate180(), 3, []uint64{5, 4, 3, 2, 1, 0})
+	check("Rotate270", r.Rotate270(), 2, []uint64{2, 5, 1, 4, 0, 3})
+
+	clone := r.Clone()
+	clone.Data[0] = 42
+	if r.Data[0] != 0 {
+		t.Error("Clone shares data with original")
+	}
+}
@@ -0,0 +1,125 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+)
+
+// Colormap is a 256-entry lookup table applied to stretched single-band values.
+type Colormap [256]color.NRGBA
+
+// At returns the color for a stretched value in 0..1
+func (cm *Colormap) At(v float64) color.NRGBA {
+	if v <= 0 {
+		return cm[0]
+	}
+	if v >= 1 {
+		return cm[255]
+	}
+	return cm[int(v*255+0.5)]
+}
+
+// Reversed returns the colormap with its entries in reverse order
+func (cm *Colormap) Reversed() *Colormap {
+	var reversed Colormap
+	for i := range cm {
+		reversed[i] = cm[255-i]
+	}
+	return &reversed
+}
+
+// colorStop is a control point of a colormap
+type colorStop struct {
+	pos   float64
+	color uint32 // 0xRRGGBB
+}
+
+// evenStops spreads colors evenly over 0..1
+func evenStops(colors ...uint32) []colorStop {
+	stops := make([]colorStop, len(colors))
+	for i, c := range colors {
+		stops[i] = colorStop{pos: float64(i) / float64(len(colors)-1), color: c}
+	}
+	return stops
+}
+
+// namedColormaps holds the control points of the built-in colormaps
+var namedColormaps = map[string][]colorStop{
+	"gray":     evenStops(0x000000, 0xffffff),
+	"viridis":  evenStops(0x440154, 0x472d7b, 0x3b528b, 0x2c728e, 0x21918c, 0x28ae80, 0x5ec962, 0xaddc30, 0xfde725),
+	"magma":    evenStops(0x000004, 0x1c1044, 0x4f127b, 0x812581, 0xb5367a, 0xe55964, 0xfb8761, 0xfec287, 0xfcfdbf),
+	"inferno":  evenStops(0x000004, 0x1f0c48, 0x550f6d, 0x88226a, 0xba3655, 0xe35933, 0xf98e09, 0xf9cb35, 0xfcffa4),
+	"plasma":   evenStops(0x0d0887, 0x41049d, 0x6a00a8, 0x8f0da4, 0xb12a90, 0xcc4778, 0xe16462, 0xf2844b, 0xfca636, 0xfcce25, 0xf0f921),
+	"cividis":  evenStops(0x00224e, 0x123570, 0x3b496c, 0x575d6d, 0x707173, 0x8a8779, 0xa69d75, 0xc4b56c, 0xe4cf5b, 0xfee838),
+	"rdylgn":   evenStops(0xa50026, 0xd73027, 0xf46d43, 0xfdae61, 0xfee08b, 0xffffbf, 0xd9ef8b, 0xa6d96a, 0x66bd63, 0x1a9850, 0x006837),
+	"spectral": evenStops(0x9e0142, 0xd53e4f, 0xf46d43, 0xfdae61, 0xfee08b, 0xffffbf, 0xe6f598, 0xabdda4, 0x66c2a5, 0x3288bd, 0x5e4fa2),
+	"blues":    evenStops(0xf7fbff, 0xdeebf7, 0xc6dbef, 0x9ecae1, 0x6baed6, 0x4292c6, 0x2171b5, 0x08519c, 0x08306b),
+	"terrain": {
+		{0.00, 0x333399}, {0.15, 0x0099ff}, {0.25, 0x00cc66},
+		{0.50, 0xffff99}, {0.75, 0x805c54}, {1.00, 0xffffff},
+	},
+	"jet": {
+		{0.000, 0x00007f}, {0.125, 0x0000ff}, {0.375, 0x00ffff},
+		{0.625, 0xffff00}, {0.875, 0xff0000}, {1.000, 0x7f0000},
+	},
+}
+
+// ColormapNames returns the names of the built-in colormaps
+func ColormapNames() []string {
+	names := make([]string, 0, len(namedColormaps))
+	for name := range namedColormaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ColormapByName returns a built-in colormap such as "viridis" or "terrain".
+// Appending "_r" to the name returns the reversed colormap.
+func ColormapByName(name string) (*Colormap, error) {
+	name = strings.ToLower(name)
+	reversed := strings.HasSuffix(name, "_r")
+	stops, ok := namedColormaps[strings.TrimSuffix(name, "_r")]
+	if !ok {
+		return nil, fmt.Errorf("unknown colormap: %s", name)
+	}
+
+	cm := colormapFromStops(stops)
+	if reversed {
+		return cm.Reversed(), nil
+	}
+	return cm, nil
+}
+
+// colormapFromStops interpolates control points into a lookup table
+func colormapFromStops(stops []colorStop) *Colormap {
+	var cm Colormap
+	j := 0
+	for i := range cm {
+		pos := float64(i) / 255
+		for j < len(stops)-2 && pos > stops[j+1].pos {
+			j++
+		}
+		a, b := stops[j], stops[j+1]
+		t := (pos - a.pos) / (b.pos - a.pos)
+		cm[i] = color.NRGBA{
+			R: lerpChannel(a.color>>16, b.color>>16, t),
+			G: lerpChannel(a.color>>8, b.color>>8, t),
+			B: lerpChannel(a.color, b.color, t),
+			A: 255,
+		}
+	}
+	return &cm
+}
+
+// lerpChannel interpolates one 8-bit channel of two packed colors
+func lerpChannel(a, b uint32, t float64) uint8 {
+	fa, fb := float64(a&0xff), float64(b&0xff)
+	return uint8(fa + (fb-fa)*t + 0.5)
+}
+
+// ColorTable assigns fixed colors to discrete values, e.g. land cover classes.
+// Values not present in the table are rendered transparent.
+type ColorTable map[int64]color.NRGBA
@@ -0,0 +1,270 @@
+// Package render turns raster data read from a COG into displayable images.
+//
+// The pipeline is read → stretch → gamma → colourise → encode. Single-band
+// data can be coloured with a named Colormap or, for categorical data, a
+// ColorTable; three bands are rendered as RGB. NoData pixels are transparent.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog"
+)
+
+// Format is an encoded image format
+type Format int
+
+const (
+	PNG Format = iota
+	JPEG
+)
+
+// String returns the MIME type of the format
+func (f Format) String() string {
+	switch f {
+	case JPEG:
+		return "image/jpeg"
+	default:
+		return "image/png"
+	}
+}
+
+// Options controls how raster data is rendered
+type Options struct {
+	// Bands selects the (0-based) bands to render: one band for grayscale,
+	// colormap or color table rendering, three bands for RGB. If empty,
+	// the first three bands are used when available, otherwise the first.
+	Bands []int
+
+	// Stretch maps band values to the display range. If nil, 8-bit data is
+	// shown as-is and other data types use a MinMaxStretch.
+	Stretch Stretch
+
+	// BandStretches optionally overrides Stretch per entry of Bands.
+	BandStretches []Stretch
+
+	// Gamma is applied after stretching as v^(1/Gamma); values > 1 brighten.
+	// Zero means no gamma correction.
+	Gamma float64
+
+	// Colormap colours single-band output. Ignored for RGB output.
+	Colormap *Colormap
+
+	// ColorTable colours single-band categorical data. The raw values are
+	// looked up directly; Stretch, Gamma and Colormap are not applied.
+	ColorTable ColorTable
+
+	// Format and Quality control encoding. Quality applies to JPEG only
+	// (1-100, 0 selects the encoder default).
+	Format  Format
+	Quality int
+
+	// TileSize is the output size for Tile (defaults to 256).
+	TileSize int
+}
+
+// Image renders raster data into an NRGBA image according to opts.
+func Image(data *gocog.RasterData, opts Options) (*image.NRGBA, error) {
+	if data == nil || data.Width <= 0 || data.Height <= 0 {
+		return nil, fmt.Errorf("empty raster data")
+	}
+
+	bands := opts.Bands
+	if len(bands) == 0 {
+		if data.Bands >= 3 {
+			bands = []int{0, 1, 2}
+		} else {
+			bands = []int{0}
+		}
+	}
+	if len(bands) != 1 && len(bands) != 3 {
+		return nil, fmt.Errorf("cannot render %d bands (need 1 or 3)", len(bands))
+	}
+	for _, band := range bands {
+		if band < 0 || band >= data.Bands {
+			return nil, fmt.Errorf("band %d out of range (raster has %d bands)", band, data.Bands)
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, data.Width, data.Height))
+
+	if opts.ColorTable != nil {
+		if len(bands) != 1 {
+			return nil, fmt.Errorf("color tables require a single band")
+		}
+		renderColorTable(img, data, bands[0], opts.ColorTable)
+		return img, nil
+	}
+
+	// Build the per-band value → 0..1 mappings
+	mappings := make([]func(float64) float64, len(bands))
+	for i, band := range bands {
+		stretch := opts.Stretch
+		if i < len(opts.BandStretches) && opts.BandStretches[i] != nil {
+			stretch = opts.BandStretches[i]
+		}
+		if stretch == nil {
+			stretch = defaultStretch(data.DataType)
+		}
+		mappings[i] = withGamma(stretch.Fit(data, band), opts.Gamma)
+	}
+
+	colormap := opts.Colormap
+	if colormap == nil && len(bands) == 1 {
+		colormap, _ = ColormapByName("gray")
+	}
+
+	for y := 0; y < data.Height; y++ {
+		for x := 0; x < data.Width; x++ {
+			offset := img.PixOffset(x, y)
+
+			if len(bands) == 1 {
+				if data.IsNoData(bands[0], x, y) {
+					continue
+				}
+				c := colormap.At(mappings[0](data.Float(bands[0], x, y)))
+				img.Pix[offset] = c.R
+				img.Pix[offset+1] = c.G
+				img.Pix[offset+2] = c.B
+				img.Pix[offset+3] = c.A
+				continue
+			}
+
+			// RGB: a pixel is transparent if any of its bands is NoData
+			valid := true
+			for _, band := range bands {
+				if data.IsNoData(band, x, y) {
+					valid = false
+					break
+				}
+			}
+			if !valid {
+				continue
+			}
+			for i, band := range bands {
+				img.Pix[offset+i] = toByte(mappings[i](data.Float(band, x, y)))
+			}
+			img.Pix[offset+3] = 255
+		}
+	}
+
+	return img, nil
+}
+
+// renderColorTable looks raw values up in a color table
+func renderColorTable(img *image.NRGBA, data *gocog.RasterData, band int, table ColorTable) {
+	for y := 0; y < data.Height; y++ {
+		for x := 0; x < data.Width; x++ {
+			if data.IsNoData(band, x, y) {
+				continue
+			}
+			c, ok := table[int64(math.Round(data.Float(band, x, y)))]
+			if !ok {
+				continue
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+// defaultStretch returns the stretch used when none is configured
+func defaultStretch(dataType gocog.DataType) Stretch {
+	if dataType == gocog.DTByte {
+		return LinearStretch{Min: 0, Max: 255}
+	}
+	return MinMaxStretch{}
+}
+
+// withGamma applies gamma correction to a clamped mapping
+func withGamma(mapping func(float64) float64, gamma float64) func(float64) float64 {
+	if gamma <= 0 || gamma == 1 {
+		return mapping
+	}
+	exponent := 1 / gamma
+	return func(v float64) float64 {
+		return math.Pow(clamp01(mapping(v)), exponent)
+	}
+}
+
+// clamp01 clamps v to [0, 1]
+func clamp01(v float64) float64 {
+	if v < 0 || math.IsNaN(v) {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// toByte converts a 0..1 value to an 8-bit channel
+func toByte(v float64) uint8 {
+	return uint8(clamp01(v)*255 + 0.5)
+}
+
+// Encode encodes an image in the given format. JPEG has no alpha channel,
+// so transparent pixels are encoded black.
+func Encode(img image.Image, format Format, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case PNG:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+	case JPEG:
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		if err := jpeg.Encode(&buf, opaque(img), &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %d", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// opaque drops the alpha channel of NRGBA images so that JPEG encoding
+// does not premultiply colors
+func opaque(img image.Image) image.Image {
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Stride != 4*nrgba.Rect.Dx() {
+		return img
+	}
+	out := image.NewRGBA(nrgba.Rect)
+	for i := 0; i < len(nrgba.Pix); i += 4 {
+		if nrgba.Pix[i+3] != 0 {
+			copy(out.Pix[i:i+3], nrgba.Pix[i:i+3])
+		}
+		out.Pix[i+3] = 255
+	}
+	return out
+}
+
+// Render renders raster data and encodes it according to opts.
+func Render(data *gocog.RasterData, opts Options) ([]byte, error) {
+	img, err := Image(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(img, opts.Format, opts.Quality)
+}
+
+// Tile reads a map tile from the COG and returns it rendered and encoded.
+func Tile(c *gocog.COG, tile maptile.Tile, opts Options) ([]byte, error) {
+	size := opts.TileSize
+	if size <= 0 {
+		size = 256
+	}
+	data, err := c.ReadTile(tile, size)
+	if err != nil {
+		return nil, err
+	}
+	return Render(data, opts)
+}
@@ -0,0 +1,196 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/tingold/gocog"
+)
+
+// newRaster creates single-band raster data from row-major values
+func newRaster(width, height int, dataType gocog.DataType, values []float64) *gocog.RasterData {
+	data := &gocog.RasterData{
+		Data:     make([]uint64, len(values)),
+		Width:    width,
+		Height:   height,
+		Bands:    1,
+		DataType: dataType,
+	}
+	for i, v := range values {
+		data.Data[i] = gocog.Float64ToSample(v, dataType)
+	}
+	return data
+}
+
+func TestImageGrayMinMax(t *testing.T) {
+	data := newRaster(3, 1, gocog.DTSShortS, []float64{-100, 50, 200})
+
+	img, err := Image(data, Options{})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{0, 0, 0, 255}) {
+		t.Errorf("expected black at minimum, got %v", got)
+	}
+	if got := img.NRGBAAt(1, 0); got.R != 128 {
+		t.Errorf("expected mid gray, got %v", got)
+	}
+	if got := img.NRGBAAt(2, 0); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("expected white at maximum, got %v", got)
+	}
+}
+
+func TestImageNoDataTransparent(t *testing.T) {
+	data := newRaster(2, 1, gocog.DTFloat, []float64{-9999, 1})
+	data.NoData = -9999
+	data.HasNoData = true
+
+	img, err := Image(data, Options{Stretch: LinearStretch{Min: 0, Max: 1}})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if img.NRGBAAt(0, 0).A != 0 {
+		t.Error("expected NoData pixel to be transparent")
+	}
+	if img.NRGBAAt(1, 0).A != 255 {
+		t.Error("expected valid pixel to be opaque")
+	}
+}
+
+func TestImageColormapAndGamma(t *testing.T) {
+	viridis, err := ColormapByName("viridis")
+	if err != nil {
+		t.Fatalf("ColormapByName failed: %v", err)
+	}
+	data := newRaster(2, 1, gocog.DTFloat, []float64{0, 1})
+
+	img, err := Image(data, Options{Stretch: LinearStretch{Min: 0, Max: 1}, Colormap: viridis})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{0x44, 0x01, 0x54, 255}) {
+		t.Errorf("unexpected low colour %v", got)
+	}
+	if got := img.NRGBAAt(1, 0); got != (color.NRGBA{0xfd, 0xe7, 0x25, 255}) {
+		t.Errorf("unexpected high colour %v", got)
+	}
+
+	data = newRaster(1, 1, gocog.DTFloat, []float64{0.25})
+	img, err = Image(data, Options{Stretch: LinearStretch{Min: 0, Max: 1}, Gamma: 2})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if got := img.NRGBAAt(0, 0).R; got != 128 {
+		t.Errorf("expected gamma 2 to map 0.25 to 128, got %d", got)
+	}
+
+	if _, err := ColormapByName("nope"); err == nil {
+		t.Error("expected error for unknown colormap")
+	}
+	reversed, _ := ColormapByName("viridis_r")
+	if reversed[0] != viridis[255] {
+		t.Error("expected reversed colormap")
+	}
+}
+
+func TestImagePercentileStretch(t *testing.T) {
+	values := make([]float64, 101)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	values[100] = 10000 // outlier
+
+	data := newRaster(101, 1, gocog.DTDouble, values)
+	img, err := Image(data, Options{Stretch: PercentileStretch{Low: 2, High: 98}})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if got := img.NRGBAAt(50, 0).R; got < 120 || got > 135 {
+		t.Errorf("expected median near mid gray, got %d", got)
+	}
+}
+
+func TestImageEqualizeStretch(t *testing.T) {
+	// Heavily skewed data: equalisation spreads the clustered low values
+	values := []float64{0, 1, 2, 3, 4, 5, 6, 1000}
+	data := newRaster(8, 1, gocog.DTSShort, values)
+
+	img, err := Image(data, Options{Stretch: EqualizeStretch{}})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if got := img.NRGBAAt(6, 0).R; got < 150 {
+		t.Errorf("expected clustered high value to be bright, got %d", got)
+	}
+	if got := img.NRGBAAt(7, 0).R; got != 255 {
+		t.Errorf("expected maximum to be white, got %d", got)
+	}
+}
+
+func TestImageColorTable(t *testing.T) {
+	data := newRaster(3, 1, gocog.DTByte, []float64{1, 2, 3})
+	table := ColorTable{
+		1: {255, 0, 0, 255},
+		2: {0, 255, 0, 255},
+	}
+
+	img, err := Image(data, Options{ColorTable: table})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if img.NRGBAAt(0, 0) != table[1] || img.NRGBAAt(1, 0) != table[2] {
+		t.Error("expected color table colors")
+	}
+	if img.NRGBAAt(2, 0).A != 0 {
+		t.Error("expected unmapped value to be transparent")
+	}
+}
+
+func TestImageRGB(t *testing.T) {
+	data := &gocog.RasterData{
+		Data:     []uint64{10, 20, 30, 0, 0, 0},
+		Width:    2,
+		Height:   1,
+		Bands:    3,
+		DataType: gocog.DTByte,
+		NoData:   0, HasNoData: true,
+	}
+	img, err := Image(data, Options{})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{10, 20, 30, 255}) {
+		t.Errorf("unexpected RGB pixel %v", got)
+	}
+	if img.NRGBAAt(1, 0).A != 0 {
+		t.Error("expected NoData pixel to be transparent")
+	}
+
+	if _, err := Image(data, Options{Bands: []int{0, 1}}); err == nil {
+		t.Error("expected error for two-band rendering")
+	}
+}
+
+func TestRenderEncode(t *testing.T) {
+	data := newRaster(4, 4, gocog.DTByte, make([]float64, 16))
+
+	encoded, err := Render(data, Options{Format: PNG})
+	if err != nil {
+		t.Fatalf("Render PNG failed: %v", err)
+	}
+	if img, err := png.Decode(bytes.NewReader(encoded)); err != nil || img.Bounds().Dx() != 4 {
+		t.Errorf("failed to decode PNG output: %v", err)
+	}
+
+	encoded, err = Render(data, Options{Format: JPEG, Quality: 90})
+	if err != nil {
+		t.Fatalf("Render JPEG failed: %v", err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(encoded)); err != nil {
+		t.Errorf("failed to decode JPEG output: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package render
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tingold/gocog"
+)
+
+// Stretch maps the values of a band onto the 0..1 display range.
+type Stretch interface {
+	// Fit returns the mapping for the given band of data. The mapping
+	// is only called for valid (non-NoData) values; results outside
+	// 0..1 are clamped.
+	Fit(data *gocog.RasterData, band int) func(float64) float64
+}
+
+// LinearStretch maps the fixed range [Min, Max] linearly onto 0..1.
+// Use it for consistent results across tiles of the same dataset.
+type LinearStretch struct {
+	Min, Max float64
+}
+
+// Fit implements Stretch
+func (s LinearStretch) Fit(data *gocog.RasterData, band int) func(float64) float64 {
+	return linear(s.Min, s.Max)
+}
+
+// MinMaxStretch maps the minimum and maximum valid value of the band onto 0..1.
+type MinMaxStretch struct{}
+
+// Fit implements Stretch
+func (MinMaxStretch) Fit(data *gocog.RasterData, band int) func(float64) float64 {
+	values := validValues(data, band)
+	if len(values) == 0 {
+		return linear(0, 1)
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return linear(lo, hi)
+}
+
+// PercentileStretch maps the Low and High percentiles (0-100) of the valid
+// values of the band onto 0..1, e.g. {2, 98}. Percentiles are computed per
+// tile; use DatasetPercentileStretch to avoid seams between tiles.
+type PercentileStretch struct {
+	Low, High float64
+}
+
+// Fit implements Stretch
+func (s PercentileStretch) Fit(data *gocog.RasterData, band int) func(float64) float64 {
+	p, err := data.Percentiles(band, s.Low, s.High)
+	if err != nil || math.IsNaN(p[0]) {
+		return linear(0, 1)
+	}
+	return linear(p[0], p[1])
+}
+
+// EqualizeStretch applies histogram equalisation. If Equalization is nil it
+// is computed per tile; use DatasetEqualizeStretch to avoid seams between tiles.
+type EqualizeStretch struct {
+	Equalization *gocog.Equalization
+}
+
+// Fit implements Stretch
+func (s EqualizeStretch) Fit(data *gocog.RasterData, band int) func(float64) float64 {
+	eq := s.Equalization
+	if eq == nil {
+		var err error
+		if eq, err = data.Equalization(band, 0); err != nil {
+			return linear(0, 1)
+		}
+	}
+	return eq.Apply
+}
+
+// DatasetPercentileStretch returns a LinearStretch between the Low and High
+// percentiles (0-100) of a band (0-based) of the whole COG, computed from its
+// lowest-resolution overview, so that all tiles share the same stretch.
+func DatasetPercentileStretch(c *gocog.COG, band int, low, high float64) (LinearStretch, error) {
+	p, err := c.Percentiles(band, low, high)
+	if err != nil {
+		return LinearStretch{}, err
+	}
+	if math.IsNaN(p[0]) {
+		return LinearStretch{}, fmt.Errorf("band %d has no valid values", band)
+	}
+
+	// Stretches operate on raw sample values, percentiles are in physical units
+	scale, offset := c.ScaleOffset(band)
+	lo, hi := (p[0]-offset)/scale, (p[1]-offset)/scale
+	if scale < 0 {
+		lo, hi = hi, lo
+	}
+	return LinearStretch{Min: lo, Max: hi}, nil
+}
+
+// DatasetEqualizeStretch returns an EqualizeStretch for a band (0-based) of
+// the whole COG, computed from its lowest-resolution overview.
+func DatasetEqualizeStretch(c *gocog.COG, band int) (EqualizeStretch, error) {
+	eq, err := c.Equalization(band, 0)
+	if err != nil {
+		return EqualizeStretch{}, err
+	}
+
+	// Convert the histogram from physical units back to raw sample values
+	scale, offset := c.ScaleOffset(band)
+	if scale == 1 && offset == 0 {
+		return EqualizeStretch{Equalization: eq}, nil
+	}
+	h := eq.Histogram
+	raw := &gocog.Histogram{
+		Min:    (h.Min - offset) / scale,
+		Max:    (h.Max - offset) / scale,
+		Counts: append([]int64(nil), h.Counts...),
+	}
+	if scale < 0 {
+		raw.Min, raw.Max = raw.Max, raw.Min
+		for i, j := 0, len(raw.Counts)-1; i < j; i, j = i+1, j-1 {
+			raw.Counts[i], raw.Counts[j] = raw.Counts[j], raw.Counts[i]
+		}
+	}
+	return EqualizeStretch{Equalization: gocog.NewEqualization(raw)}, nil
+}
+
+// linear returns a linear mapping of [lo, hi] onto [0, 1]
+func linear(lo, hi float64) func(float64) float64 {
+	if hi <= lo {
+		return func(v float64) float64 {
+			if v < lo {
+				return 0
+			}
+			return 1
+		}
+	}
+	scale := 1 / (hi - lo)
+	return func(v float64) float64 {
+		return (v - lo) * scale
+	}
+}
+
+// validValues collects the numeric values of a band, skipping NoData
+func validValues(data *gocog.RasterData, band int) []float64 {
+	values := make([]float64, 0, data.Width*data.Height)
+	for y := 0; y < data.Height; y++ {
+		for x := 0; x < data.Width; x++ {
+			if data.IsNoData(band, x, y) {
+				continue
+			}
+			values = append(values, data.Float(band, x, y))
+		}
+	}
+	return values
+}
@@ -0,0 +1,285 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Resampling selects how output pixels are computed from source pixels when
+// an image is warped or resized
+type Resampling int
+
+const (
+	// ResampleNearest takes the nearest source pixel (default)
+	ResampleNearest Resampling = iota
+	// ResampleBilinear interpolates linearly between the 2x2 nearest pixels
+	ResampleBilinear
+	// ResampleCubic uses the Keys cubic convolution kernel (a = -0.5) over 4x4 pixels
+	ResampleCubic
+	// ResampleLanczos uses a 3-lobed Lanczos windowed sinc over 6x6 pixels
+	ResampleLanczos
+	// ResampleAverage averages the source pixels covered by each output pixel
+	ResampleAverage
+	// ResampleMode takes the most common value of the source pixels covered
+	// by each output pixel, for categorical data
+	ResampleMode
+)
+
+var resamplingNames = []string{"nearest", "bilinear", "cubic", "lanczos", "average", "mode"}
+
+// String returns the name of the resampling method, as accepted by ParseResampling
+func (r Resampling) String() string {
+	if r < 0 || int(r) >= len(resamplingNames) {
+		return fmt.Sprintf("Resampling(%d)", int(r))
+	}
+	return resamplingNames[r]
+}
+
+// ParseResampling returns the resampling method with the given name
+// (nearest, bilinear, cubic, lanczos, average or mode)
+func ParseResampling(name string) (Resampling, error) {
+	for i, n := range resamplingNames {
+		if strings.EqualFold(name, n) {
+			return Resampling(i), nil
+		}
+	}
+	return ResampleNearest, fmt.Errorf("unknown resampling method %q", name)
+}
+
+// SetResampling sets the resampling method used by ReadTile and ReadWindowSized
+func (c *COG) SetResampling(r Resampling) {
+	c.resampling = r
+}
+
+// Resampling returns the method set by SetResampling
+func (c *COG) Resampling() Resampling {
+	return c.resampling
+}
+
+// kernel returns the interpolation kernel and its radius in source pixels,
+// or a nil kernel for methods that do not interpolate
+func (r Resampling) kernel() (func(t float64) float64, float64) {
+	switch r {
+	case ResampleBilinear:
+		return func(t float64) float64 {
+			return math.Max(0, 1-math.Abs(t))
+		}, 1
+	case ResampleCubic:
+		return cubicKernel, 2
+	case ResampleLanczos:
+		return lanczosKernel, 3
+	default:
+		return nil, 0
+	}
+}
+
+// cubicKernel is the Keys cubic convolution kernel with a = -0.5
+func cubicKernel(t float64) float64 {
+	const a = -0.5
+	t = math.Abs(t)
+	switch {
+	case t < 1:
+		return ((a+2)*t-(a+3))*t*t + 1
+	case t < 2:
+		return ((a*t-5*a)*t+8*a)*t - 4*a
+	default:
+		return 0
+	}
+}
+
+// lanczosKernel is the Lanczos kernel with 3 lobes
+func lanczosKernel(t float64) float64 {
+	const a = 3
+	t = math.Abs(t)
+	if t == 0 {
+		return 1
+	}
+	if t >= a {
+		return 0
+	}
+	pt := math.Pi * t
+	return a * math.Sin(pt) * math.Sin(pt/a) / (pt * pt)
+}
+
+// support returns how far (in source pixels) from an output pixel's centre
+// the method reads, given the output pixel's size in source pixels
+func (r Resampling) support(scale float64) float64 {
+	switch r {
+	case ResampleAverage, ResampleMode:
+		return math.Max(scale, 1) / 2
+	default:
+		_, radius := r.kernel()
+		return radius * math.Max(scale, 1)
+	}
+}
+
+// resampler computes output samples from a window of source pixels.
+// Coordinates are fractional source pixel positions with pixel centres at
+// half-integers, relative to the whole image (the window starts at x0, y0).
+type resampler struct {
+	method Resampling
+	src    *RasterData
+	x0, y0 int
+
+	weightsX, weightsY []float64
+	counts             map[float64]int
+}
+
+// sample writes the resampled value of every band at (sx, sy) into dst,
+// where the output pixel spans scaleX by scaleY source pixels. A band whose
+// nearest source pixel is NoData stays NoData, so NoData neither shrinks
+// nor bleeds into valid pixels; other NoData pixels are left out.
+func (r *resampler) sample(dst []uint64, sx, sy, scaleX, scaleY float64) {
+	src := r.src
+	nx := int(math.Floor(sx)) - r.x0
+	ny := int(math.Floor(sy)) - r.y0
+	nearest := src.Index(0, nx, ny)
+	copy(dst, src.Data[nearest:nearest+src.Bands])
+	if r.method == ResampleNearest {
+		return
+	}
+
+	// Source columns and rows that contribute, clamped to the window
+	var minX, maxX, minY, maxY int
+	kernel, _ := r.method.kernel()
+	if kernel != nil {
+		// Widen the kernel when downsampling to avoid aliasing
+		kx, ky := math.Max(scaleX, 1), math.Max(scaleY, 1)
+		rx, ry := r.method.support(scaleX), r.method.support(scaleY)
+		minX, maxX = r.span(sx, rx, r.x0, src.Width)
+		minY, maxY = r.span(sy, ry, r.y0, src.Height)
+		r.weightsX = kernelWeights(r.weightsX[:0], kernel, sx, kx, minX+r.x0, maxX+r.x0)
+		r.weightsY = kernelWeights(r.weightsY[:0], kernel, sy, ky, minY+r.y0, maxY+r.y0)
+	} else {
+		// Pixels whose centres lie inside the output pixel, or the nearest one
+		minX, maxX = r.covered(sx, scaleX, r.x0, src.Width)
+		minY, maxY = r.covered(sy, scaleY, r.y0, src.Height)
+		if minX > maxX {
+			minX, maxX = nx, nx
+		}
+		if minY > maxY {
+			minY, maxY = ny, ny
+		}
+	}
+
+	for b := 0; b < src.Bands; b++ {
+		if src.isNoDataValue(SampleToFloat64(src.Data[nearest+b], src.DataType)) {
+			continue
+		}
+
+		var value float64
+		valid := false
+		switch r.method {
+		case ResampleAverage:
+			value, valid = r.average(b, minX, maxX, minY, maxY)
+		case ResampleMode:
+			value, valid = r.mode(b, minX, maxX, minY, maxY)
+		default:
+			value, valid = r.convolve(b, minX, minY)
+		}
+		if valid {
+			dst[b] = Float64ToSample(value, src.DataType)
+		}
+	}
+}
+
+// span returns the window columns (or rows) within radius of position pos
+func (r *resampler) span(pos, radius float64, origin, size int) (int, int) {
+	lo := int(math.Ceil(pos-0.5-radius)) - origin
+	hi := int(math.Floor(pos-0.5+radius)) - origin
+	return max(lo, 0), min(hi, size-1)
+}
+
+// covered returns the window columns (or rows) whose centres lie within an
+// output pixel of the given size centred on pos
+func (r *resampler) covered(pos, scale float64, origin, size int) (int, int) {
+	half := scale / 2
+	lo := int(math.Ceil(pos-half-0.5)) - origin
+	hi := int(math.Ceil(pos+half-0.5)) - 1 - origin
+	return max(lo, 0), min(hi, size-1)
+}
+
+// kernelWeights appends the kernel weight of each pixel from lo to hi
+func kernelWeights(weights []float64, kernel func(float64) float64, pos, scale float64, lo, hi int) []float64 {
+	for i := lo; i <= hi; i++ {
+		weights = append(weights, kernel((float64(i)+0.5-pos)/scale))
+	}
+	return weights
+}
+
+// convolve applies the kernel weights to the valid pixels of a band,
+// renormalising over the weights of the pixels used
+func (r *resampler) convolve(band, minX, minY int) (float64, bool) {
+	src := r.src
+	var sum, weightSum float64
+	for j, wy := range r.weightsY {
+		if wy == 0 {
+			continue
+		}
+		for i, wx := range r.weightsX {
+			w := wx * wy
+			if w == 0 {
+				continue
+			}
+			v := SampleToFloat64(src.Data[src.Index(band, minX+i, minY+j)], src.DataType)
+			if src.isNoDataValue(v) {
+				continue
+			}
+			sum += w * v
+			weightSum += w
+		}
+	}
+	if math.Abs(weightSum) < 1e-9 {
+		return 0, false
+	}
+	return sum / weightSum, true
+}
+
+// average returns the mean of the valid pixels of a band in the window
+func (r *resampler) average(band, minX, maxX, minY, maxY int) (float64, bool) {
+	src := r.src
+	var sum float64
+	count := 0
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			v := SampleToFloat64(src.Data[src.Index(band, x, y)], src.DataType)
+			if src.isNoDataValue(v) {
+				continue
+			}
+			sum += v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// mode returns the most common valid value of a band in the window; ties
+// go to the smallest value
+func (r *resampler) mode(band, minX, maxX, minY, maxY int) (float64, bool) {
+	src := r.src
+	if r.counts == nil {
+		r.counts = make(map[float64]int)
+	}
+	clear(r.counts)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			v := SampleToFloat64(src.Data[src.Index(band, x, y)], src.DataType)
+			if src.isNoDataValue(v) {
+				continue
+			}
+			r.counts[v]++
+		}
+	}
+
+	best, bestCount := 0.0, 0
+	for v, n := range r.counts {
+		if n > bestCount || (n == bestCount && v < best) {
+			best, bestCount = v, n
+		}
+	}
+	return best, bestCount > 0
+}
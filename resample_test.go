@@ -0,0 +1,158 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb/maptile"
+)
+
+func TestResamplingNames(t *testing.T) {
+	for r := ResampleNearest; r <= ResampleMode; r++ {
+		parsed, err := ParseResampling(r.String())
+		if err != nil || parsed != r {
+			t.Errorf("ParseResampling(%q) = %v, %v", r.String(), parsed, err)
+		}
+	}
+	if r, err := ParseResampling("Bilinear"); err != nil || r != ResampleBilinear {
+		t.Errorf("expected case-insensitive parsing, got %v, %v", r, err)
+	}
+	if _, err := ParseResampling("gauss"); err == nil {
+		t.Error("expected error for unknown method")
+	}
+}
+
+func TestKernels(t *testing.T) {
+	for _, r := range []Resampling{ResampleBilinear, ResampleCubic, ResampleLanczos} {
+		kernel, radius := r.kernel()
+		if kernel(0) != 1 {
+			t.Errorf("%v: expected weight 1 at 0, got %v", r, kernel(0))
+		}
+		if w := kernel(radius); math.Abs(w) > 1e-12 {
+			t.Errorf("%v: expected weight 0 at the radius, got %v", r, w)
+		}
+		if w := kernel(1); math.Abs(w) > 1e-12 {
+			t.Errorf("%v: expected weight 0 at 1, got %v", r, w)
+		}
+	}
+
+	// Bilinear and cubic weights sum to one at any offset
+	for _, r := range []Resampling{ResampleBilinear, ResampleCubic} {
+		kernel, _ := r.kernel()
+		for _, offset := range []float64{0.1, 0.25, 0.5, 0.9} {
+			sum := 0.0
+			for i := -3; i <= 3; i++ {
+				sum += kernel(float64(i) + offset)
+			}
+			if math.Abs(sum-1) > 1e-12 {
+				t.Errorf("%v: weights at offset %v sum to %v", r, offset, sum)
+			}
+		}
+	}
+}
+
+// readResampledTile reads tile from c with the given method at size pixels
+func readResampledTile(t *testing.T, c *COG, tile maptile.Tile, method Resampling, size int) *RasterData {
+	t.Helper()
+	c.SetResampling(method)
+	data, err := c.ReadTile(tile, size)
+	if err != nil {
+		t.Fatalf("ReadTile with %v failed: %v", method, err)
+	}
+	return data
+}
+
+func TestReadTileInterpolation(t *testing.T) {
+	tile := maptile.New(8, 5, 4)
+	tr := mercatorTileRaster(tile, 64, 0)
+	tr.DataType = DTFloat
+	c := tr.cog(t)
+
+	// Upsampling 4x: output pixel i is centred on source position (i+0.5)/4,
+	// where a linear ramp with value x at pixel centre x+0.5 is (i+0.5)/4-0.5
+	for _, method := range []Resampling{ResampleBilinear, ResampleCubic, ResampleLanczos} {
+		data := readResampledTile(t, c, tile, method, 256)
+		tolerance := 1e-4
+		if method == ResampleLanczos {
+			tolerance = 0.02 // Lanczos only approximately reproduces linear ramps
+		}
+		for _, x := range []int{20, 41, 102, 200} {
+			want := (float64(x)+0.5)/4 - 0.5
+			if got := data.Float(0, x, 100); math.Abs(got-want) > tolerance {
+				t.Errorf("%v: pixel %d expected %v, got %v", method, x, want, got)
+			}
+		}
+	}
+}
+
+func TestReadTileInterpolationSigned(t *testing.T) {
+	tile := maptile.New(8, 5, 4)
+	tr := mercatorTileRaster(tile, 64, 0)
+	tr.DataType = DTSShortS
+	tr.Value = func(band, x, y int) float64 {
+		if x < 32 {
+			return -1000
+		}
+		return 1000
+	}
+	c := tr.cog(t)
+
+	// Output pixel 127 is centred at source x = 31.875: 0.625 of pixel 31
+	// and 0.375 of pixel 32
+	data := readResampledTile(t, c, tile, ResampleBilinear, 256)
+	if got := data.Float(0, 127, 100); got != -250 {
+		t.Errorf("expected -250, got %v", got)
+	}
+}
+
+func TestReadTileInterpolationNoData(t *testing.T) {
+	tile := maptile.New(8, 5, 4)
+	tr := mercatorTileRaster(tile, 64, 0)
+	tr.DataType = DTFloat
+	tr.NoData = "-9999"
+	tr.Value = func(band, x, y int) float64 {
+		if x < 32 {
+			return -9999
+		}
+		return 100
+	}
+	c := tr.cog(t)
+
+	for _, method := range []Resampling{ResampleBilinear, ResampleCubic, ResampleLanczos, ResampleAverage} {
+		data := readResampledTile(t, c, tile, method, 256)
+		// Nearest source pixel is NoData
+		if !data.IsNoData(0, 127, 100) {
+			t.Errorf("%v: expected NoData next to the edge, got %v", method, data.Float(0, 127, 100))
+		}
+		// Nearest source pixel is valid: NoData must not bleed in
+		for _, x := range []int{128, 129, 130} {
+			if got := data.Float(0, x, 100); got != 100 {
+				t.Errorf("%v: pixel %d expected 100, got %v", method, x, got)
+			}
+		}
+	}
+}
+
+func TestReadTileAverageAndMode(t *testing.T) {
+	tile := maptile.New(8, 5, 4)
+	tr := mercatorTileRaster(tile, 512, 0)
+	// Every 2x2 block holds 5, 5, 5 and 7
+	tr.Value = func(band, x, y int) float64 {
+		if x%2 == 1 && y%2 == 1 {
+			return 7
+		}
+		return 5
+	}
+	c := tr.cog(t)
+
+	average := readResampledTile(t, c, tile, ResampleAverage, 256)
+	mode := readResampledTile(t, c, tile, ResampleMode, 256)
+	for _, x := range []int{0, 50, 255} {
+		if got := average.At(0, x, 10); got != 6 { // 5.5 rounded
+			t.Errorf("average: pixel %d expected 6, got %d", x, got)
+		}
+		if got := mode.At(0, x, 10); got != 5 {
+			t.Errorf("mode: pixel %d expected 5, got %d", x, got)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/tingold/gocog/proj"
+)
+
+// TagRPCCoefficients is the RPCCoefficientTag holding the rational polynomial
+// coefficients of raw (Level-1) satellite imagery
+const TagRPCCoefficients = 50844
+
+// RPC is a rational polynomial camera model (RPC00B), locating pixels by
+// WGS 84 longitude, latitude and height above the ellipsoid. Line and sample
+// are ratios of cubic polynomials in normalised longitude, latitude and
+// height; the coefficients follow the RPC00B term order.
+type RPC struct {
+	ErrBias, ErrRand float64 // Expected errors in metres, 0 if unknown
+
+	LineOffset, SampleOffset, LatOffset, LonOffset, HeightOffset float64
+	LineScale, SampleScale, LatScale, LonScale, HeightScale      float64
+
+	LineNum, LineDen     [20]float64
+	SampleNum, SampleDen [20]float64
+}
+
+// parseRPC parses the 92 values of the RPCCoefficientTag
+func parseRPC(values []float64) (*RPC, error) {
+	if len(values) < 92 {
+		return nil, fmt.Errorf("expected 92 RPC coefficients, got %d", len(values))
+	}
+	r := &RPC{
+		ErrBias: values[0], ErrRand: values[1],
+		LineOffset: values[2], SampleOffset: values[3], LatOffset: values[4], LonOffset: values[5], HeightOffset: values[6],
+		LineScale: values[7], SampleScale: values[8], LatScale: values[9], LonScale: values[10], HeightScale: values[11],
+	}
+	copy(r.LineNum[:], values[12:32])
+	copy(r.LineDen[:], values[32:52])
+	copy(r.SampleNum[:], values[52:72])
+	copy(r.SampleDen[:], values[72:92])
+	if r.LineScale == 0 || r.SampleScale == 0 || r.LatScale == 0 || r.LonScale == 0 || r.HeightScale == 0 {
+		return nil, fmt.Errorf("RPC scales must be non-zero")
+	}
+	return r, nil
+}
+
+// rpcTerms returns the RPC00B monomials of normalised longitude l, latitude
+// p and height h
+func rpcTerms(l, p, h float64) [20]float64 {
+	return [20]float64{
+		1, l, p, h,
+		l * p, l * h, p * h, l * l, p * p, h * h,
+		p * l * h, l * l * l, l * p * p, l * h * h, l * l * p,
+		p * p * p, p * h * h, l * l * h, p * p * h, h * h * h,
+	}
+}
+
+// rpcRatio evaluates the ratio of two RPC polynomials
+func rpcRatio(num, den *[20]float64, terms *[20]float64) float64 {
+	var n, d float64
+	for i, t := range terms {
+		n += num[i] * t
+		d += den[i] * t
+	}
+	return n / d
+}
+
+// GroundToImage projects a longitude and latitude (degrees) at a height
+// above the ellipsoid (metres) to pixel coordinates of the full-resolution
+// image. RPC line and sample refer to pixel centres, so they are shifted by
+// half a pixel to the corner-based convention used elsewhere, as GDAL does.
+func (r *RPC) GroundToImage(lon, lat, height float64) (float64, float64) {
+	terms := rpcTerms(
+		(lon-r.LonOffset)/r.LonScale,
+		(lat-r.LatOffset)/r.LatScale,
+		(height-r.HeightOffset)/r.HeightScale,
+	)
+	line := rpcRatio(&r.LineNum, &r.LineDen, &terms)*r.LineScale + r.LineOffset
+	sample := rpcRatio(&r.SampleNum, &r.SampleDen, &terms)*r.SampleScale + r.SampleOffset
+	return sample + 0.5, line + 0.5
+}
+
+const (
+	rpcMaxIterations = 20
+	rpcTolerance     = 1e-6 // Pixels
+)
+
+// ImageToGround locates pixel coordinates of the full-resolution image at
+// a height above the ellipsoid (metres), returning longitude and latitude.
+// The RPC is inverted by Newton iteration; it fails if that does not
+// converge, e.g. far outside the image.
+func (r *RPC) ImageToGround(pixelX, pixelY, height float64) (float64, float64, error) {
+	lon, lat := r.LonOffset, r.LatOffset
+	dLon, dLat := r.LonScale*1e-6, r.LatScale*1e-6
+	for i := 0; i < rpcMaxIterations; i++ {
+		x, y := r.GroundToImage(lon, lat, height)
+		dx, dy := pixelX-x, pixelY-y
+		if math.Abs(dx) < rpcTolerance && math.Abs(dy) < rpcTolerance {
+			return lon, lat, nil
+		}
+
+		// Jacobian by forward differences
+		xLon, yLon := r.GroundToImage(lon+dLon, lat, height)
+		xLat, yLat := r.GroundToImage(lon, lat+dLat, height)
+		a, b := (xLon-x)/dLon, (xLat-x)/dLat
+		c, d := (yLon-y)/dLon, (yLat-y)/dLat
+		det := a*d - b*c
+		if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+			break
+		}
+		lon += (d*dx - b*dy) / det
+		lat += (a*dy - c*dx) / det
+	}
+	return 0, 0, fmt.Errorf("RPC inverse did not converge at pixel (%g, %g)", pixelX, pixelY)
+}
+
+// rpcModel georeferences an image by its RPCs, with heights from a DEM
+// where it has data and a constant height elsewhere. It is shared by every
+// level of the image, and the heights may be changed while it is in use.
+type rpcModel struct {
+	rpc           *RPC
+	width, height int
+
+	mu       sync.RWMutex // Guards the fields below
+	constant float64
+	dem      *demHeights
+	affine   GeoTransform
+}
+
+// newRPCModel returns the model of a width x height image at the RPC's
+// height offset
+func newRPCModel(rpc *RPC, width, height int) *rpcModel {
+	m := &rpcModel{rpc: rpc, width: width, height: height}
+	m.setConstantHeight(rpc.HeightOffset)
+	return m
+}
+
+// setConstantHeight sets the constant height and refits the affine
+// approximation from a grid of pixels located at that height
+func (m *rpcModel) setConstantHeight(height float64) {
+	const steps = 4
+	var pixels, geos [][2]float64
+	for j := 0; j <= steps; j++ {
+		for i := 0; i <= steps; i++ {
+			x, y := float64(m.width*i)/steps, float64(m.height*j)/steps
+			lon, lat, err := m.rpc.ImageToGround(x, y, height)
+			if err != nil {
+				continue
+			}
+			pixels = append(pixels, [2]float64{x, y})
+			geos = append(geos, [2]float64{lon, lat})
+		}
+	}
+	var affine GeoTransform
+	if p, err := fitPolynomial(pixels, geos, 1); err == nil {
+		affine = p.geoTransform()
+	}
+
+	m.mu.Lock()
+	m.constant, m.affine = height, affine
+	m.mu.Unlock()
+}
+
+// setDEM sets the DEM heights, nil for none
+func (m *rpcModel) setDEM(dem *demHeights) {
+	m.mu.Lock()
+	m.dem = dem
+	m.mu.Unlock()
+}
+
+// heights returns the constant height and the DEM, if any
+func (m *rpcModel) heights() (float64, *demHeights) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.constant, m.dem
+}
+
+// heightAt returns the DEM height at a point, or the constant height
+func heightAt(constant float64, dem *demHeights, lon, lat float64) float64 {
+	if dem != nil {
+		if h, ok := dem.height(lon, lat); ok {
+			return h
+		}
+	}
+	return constant
+}
+
+// rpcDEMIterations and rpcDEMTolerance (metres) bound the search for the
+// ground point of a pixel on a DEM
+const (
+	rpcDEMIterations = 10
+	rpcDEMTolerance  = 0.01
+)
+
+// PixelToGeo locates a pixel on the ground. With a DEM, the height is
+// refined until the located point lies on the DEM surface. Pixels that
+// cannot be located give NaN.
+func (m *rpcModel) PixelToGeo(pixelX, pixelY float64) (float64, float64) {
+	constant, dem := m.heights()
+	height := constant
+	lon, lat, err := m.rpc.ImageToGround(pixelX, pixelY, height)
+	for i := 0; err == nil && dem != nil && i < rpcDEMIterations; i++ {
+		h := heightAt(constant, dem, lon, lat)
+		if math.Abs(h-height) < rpcDEMTolerance {
+			break
+		}
+		height = h
+		lon, lat, err = m.rpc.ImageToGround(pixelX, pixelY, height)
+	}
+	if err != nil {
+		return math.NaN(), math.NaN()
+	}
+	return lon, lat
+}
+
+// GeoToPixel projects a ground point at its DEM (or constant) height
+func (m *rpcModel) GeoToPixel(lon, lat float64) (float64, float64) {
+	constant, dem := m.heights()
+	return m.rpc.GroundToImage(lon, lat, heightAt(constant, dem, lon, lat))
+}
+
+// Affine returns the affine approximation at the constant height
+func (m *rpcModel) Affine() GeoTransform {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.affine
+}
+
+// demBlockLimit is the number of DEM blocks kept in memory for RPC heights
+const demBlockLimit = 64
+
+// demHeights looks up heights in a DEM COG, caching its decoded blocks. It
+// is safe for concurrent use.
+type demHeights struct {
+	dem           *COG
+	projection    proj.Projection
+	scale, offset float64
+
+	mu    sync.Mutex
+	cache *blockCache
+}
+
+func newDEMHeights(dem *COG) (*demHeights, error) {
+	if len(dem.geoTIFFs) == 0 || !dem.geoTIFFs[0].georeferenced() {
+		return nil, fmt.Errorf("DEM is not georeferenced")
+	}
+	projection, err := dem.Projection()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported DEM CRS: %w", err)
+	}
+	scale, offset := dem.ScaleOffset(0)
+	return &demHeights{dem: dem, projection: projection, scale: scale, offset: offset, cache: newBlockCache(dem, 0)}, nil
+}
+
+// height returns the bilinearly interpolated height of the DEM's first band
+// at a point, and false outside the DEM or where it is NoData
+func (d *demHeights) height(lon, lat float64) (float64, bool) {
+	x, y := d.projection.Forward(lon, lat)
+	px, py := d.dem.geoTIFFs[0].geoToPixel(x, y)
+	meta := d.dem.metadata[0]
+	if !(px >= 0 && py >= 0 && px <= float64(meta.Width) && py <= float64(meta.Height)) {
+		return 0, false
+	}
+
+	// Interpolate between the four pixel centres around the point
+	px, py = px-0.5, py-0.5
+	x0, y0 := int(math.Floor(px)), int(math.Floor(py))
+	fx, fy := px-float64(x0), py-float64(y0)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.cache.blocks) > demBlockLimit {
+		d.cache.blocks = make(map[[2]int]*RasterData)
+	}
+	var sum, weight float64
+	for j := 0; j <= 1; j++ {
+		for i := 0; i <= 1; i++ {
+			w := math.Abs(1-float64(i)-fx) * math.Abs(1-float64(j)-fy)
+			if w == 0 {
+				continue
+			}
+			values, err := d.cache.pixel(min(max(x0+i, 0), meta.Width-1), min(max(y0+j, 0), meta.Height-1))
+			if err != nil {
+				return 0, false
+			}
+			v := SampleToFloat64(values[0], meta.DataType)
+			if math.IsNaN(v) || (meta.HasNoData && v == meta.NoData) {
+				continue
+			}
+			sum += w * v
+			weight += w
+		}
+	}
+	if weight == 0 {
+		return 0, false
+	}
+	return sum/weight*d.scale + d.offset, true
+}
+
+// rpcGeoreferenced reports whether an image is located by its RPCs alone,
+// without a geotransform or ground control points
+func rpcGeoreferenced(meta *GeoTIFFMetadata) bool {
+	if meta.RPC == nil || len(meta.TiePoints) >= 3 {
+		return false
+	}
+	_, ok := geoTransformFromMetadata(meta)
+	return !ok
+}
+
+// crsGeoKeys returns the GeoKeys describing an image's horizontal CRS. RPCs
+// locate pixels by WGS 84 longitude and latitude, whatever the GeoKeys say,
+// so images located by them alone are resolved as geographic EPSG:4326; the
+// GeoKeys as read are left unchanged.
+func crsGeoKeys(meta *GeoTIFFMetadata) map[uint16]interface{} {
+	if !rpcGeoreferenced(meta) {
+		return meta.GeoKeys
+	}
+	keys := make(map[uint16]interface{}, len(meta.GeoKeys)+2)
+	for id, v := range meta.GeoKeys {
+		keys[id] = v
+	}
+	keys[GTModelTypeGeoKey] = uint16(GTModelTypeGeographic)
+	keys[GeographicTypeGeoKey] = uint16(4326)
+	delete(keys, ProjectedCSTypeGeoKey)
+	return keys
+}
+
+// RPC returns the rational polynomial coefficients of the image
+// (RPCCoefficientTag), and false if it has none
+func (c *COG) RPC() (*RPC, bool) {
+	if len(c.metadata) == 0 || c.metadata[0].RPC == nil {
+		return nil, false
+	}
+	return c.metadata[0].RPC, true
+}
+
+// rpcModel returns the RPC model georeferencing the image, if any
+func (c *COG) rpcModel() (*rpcModel, error) {
+	if len(c.geoTIFFs) > 0 {
+		if m, ok := c.geoTIFFs[0].model.(*rpcModel); ok {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("image is not georeferenced by RPCs")
+}
+
+// SetRPCHeight sets the height above the ellipsoid (metres) at which an
+// image georeferenced by RPCs is located where no DEM height is available.
+// It defaults to the RPC's height offset. Like SetRPCDEM, it may be called
+// while other goroutines read the image.
+func (c *COG) SetRPCHeight(height float64) error {
+	m, err := c.rpcModel()
+	if err != nil {
+		return err
+	}
+	m.setConstantHeight(height)
+	return nil
+}
+
+// SetRPCDEM orthorectifies an image georeferenced by RPCs with heights from
+// the first band of a DEM COG, so pixel conversions, bounds, samples and
+// warped reads (ReadTile, ReadTMSTile) account for the terrain. Heights
+// should be in metres above the ellipsoid (see SetElevationInMetres);
+// outside the DEM and on NoData the constant height is used. nil removes
+// the DEM.
+func (c *COG) SetRPCDEM(dem *COG) error {
+	m, err := c.rpcModel()
+	if err != nil {
+		return err
+	}
+	if dem == nil {
+		m.setDEM(nil)
+		return nil
+	}
+	heights, err := newDEMHeights(dem)
+	if err != nil {
+		return fmt.Errorf("failed to use DEM: %w", err)
+	}
+	m.setDEM(heights)
+	return nil
+}
@@ -0,0 +1,153 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog/proj"
+)
+
+// rpcTag returns an RPC over 10°E–10.2°E, 44.8°N–45°N for a 200 x 200
+// image, with 5 pixels of eastward parallax per 500 m of height
+func rpcTag() testTag {
+	values := make([]float64, 92)
+	copy(values, []float64{
+		1, 0.5,
+		100, 100, 44.9, 10.1, 500,
+		100, 100, 0.1, 0.1, 500,
+	})
+	// Line: -P + 0.01 L P
+	values[12+2], values[12+4] = -1, 0.01
+	values[32] = 1
+	// Sample: (L + 0.05 H) / (1 + 0.02 P)
+	values[52+1], values[52+3] = 1, 0.05
+	values[72], values[72+2] = 1, 0.02
+	return doublesTag(TagRPCCoefficients, values...)
+}
+
+// rpcRaster is an image located by rpcTag whose values are the column
+var rpcRaster = testRaster{
+	Width: 200, Height: 200, DataType: DTSShort, TileSize: 64, Overviews: 1,
+	ExtraTags: []testTag{rpcTag()},
+	Value:     func(band, x, y int) float64 { return float64(x) },
+}
+
+func TestRPC(t *testing.T) {
+	c := rpcRaster.cog(t)
+	rpc, ok := c.RPC()
+	if !ok || rpc.ErrBias != 1 || rpc.LonOffset != 10.1 || rpc.SampleDen[2] != 0.02 {
+		t.Fatalf("unexpected RPC %+v", rpc)
+	}
+	if c.CRS() != "EPSG:4326" {
+		t.Errorf("expected RPC images to be in EPSG:4326, got %q", c.CRS())
+	}
+	if _, ok := c.GeoTransform(0); ok {
+		t.Error("expected no geotransform for an RPC image")
+	}
+
+	// The GeoKeys are kept as read, and only the horizontal CRS is WGS 84
+	tr := rpcRaster
+	tr.ExtraTags = append([]testTag{shortsTag(TagGeoKeyDirectory, 1, 1, 0, 1, VerticalCSTypeGeoKey, 0, 1, 6360)}, tr.ExtraTags...)
+	keyed := tr.cog(t)
+	if _, ok := keyed.metadata[0].GeoKeys[GTModelTypeGeoKey]; ok {
+		t.Error("expected the GeoKeys to be left as read")
+	}
+	if crs, err := keyed.CoordinateReferenceSystem(); err != nil || !crs.IsGeographic() || crs.Code != 4326 {
+		t.Errorf("expected WGS 84, got %+v (%v)", crs, err)
+	}
+	if v, ok := keyed.VerticalCRS(); !ok || v.Code != 6360 {
+		t.Errorf("expected the vertical CRS of the GeoKeys, got %+v", v)
+	}
+
+	// The image centre at the height offset is the centre pixel
+	if x, y := rpc.GroundToImage(10.1, 44.9, 500); x != 100.5 || y != 100.5 {
+		t.Errorf("expected pixel (100.5, 100.5), got (%v, %v)", x, y)
+	}
+	for _, height := range []float64{0, 500, 2000} {
+		x, y := rpc.GroundToImage(10.05, 44.97, height)
+		lon, lat, err := rpc.ImageToGround(x, y, height)
+		if err != nil {
+			t.Fatalf("ImageToGround failed: %v", err)
+		}
+		if math.Abs(lon-10.05) > 1e-8 || math.Abs(lat-44.97) > 1e-8 {
+			t.Errorf("height %v: expected (10.05, 44.97), got (%v, %v)", height, lon, lat)
+		}
+	}
+	low, _ := rpc.GroundToImage(10.1, 44.9, 0)
+	high, _ := rpc.GroundToImage(10.1, 44.9, 1000)
+	if math.Abs(high-low-10) > 1e-9 {
+		t.Errorf("expected 10 pixels of parallax over 1000 m, got %v", high-low)
+	}
+
+	// Point conversion uses the height offset until another height is set
+	if x, y := c.PixelFromPoint(orb.Point{10.1, 44.9}, 0); x != 100 || y != 100 {
+		t.Errorf("expected pixel (100, 100), got (%d, %d)", x, y)
+	}
+	if err := c.SetRPCHeight(0); err != nil {
+		t.Fatalf("SetRPCHeight failed: %v", err)
+	}
+	if x, _ := c.PixelFromPoint(orb.Point{10.1, 44.9}, 0); x != 95 {
+		t.Errorf("expected column 95 at 0 m, got %d", x)
+	}
+	p := c.PointFromPixel(50, 20, 1)
+	if x, y := rpc.GroundToImage(p[0], p[1], 0); math.Abs(x-100) > 1e-6 || math.Abs(y-40) > 1e-6 {
+		t.Errorf("expected the overview pixel to map to (100, 40), got (%v, %v)", x, y)
+	}
+	if b := c.Bounds(); math.Abs(b.Min[0]-10.005) > 0.01 || math.Abs(b.Max[1]-45) > 0.01 {
+		t.Errorf("unexpected bounds %v", b)
+	}
+
+	if _, err := parseRPC(make([]float64, 80)); err == nil {
+		t.Error("expected error for a truncated RPC tag")
+	}
+	if err := sampleRaster.cog(t).SetRPCHeight(0); err == nil {
+		t.Error("expected error for an image without RPCs")
+	}
+}
+
+func TestRPCOrthorectification(t *testing.T) {
+	c := rpcRaster.cog(t)
+	rpc, _ := c.RPC()
+	dem := testRaster{
+		Width: 40, Height: 40, DataType: DTFloat, TileSize: 16, EPSG: 4326,
+		Origin: [2]float64{9.9, 45.1}, PixelSize: [2]float64{0.01, 0.01},
+		Value: func(band, x, y int) float64 { return 1000 },
+	}.cog(t)
+	if err := c.SetRPCDEM(dem); err != nil {
+		t.Fatalf("SetRPCDEM failed: %v", err)
+	}
+
+	point := orb.Point{10.08, 44.93}
+	wantX, wantY := rpc.GroundToImage(point[0], point[1], 1000)
+	if x, y := c.PixelFromPoint(point, 0); x != int(wantX) || y != int(wantY) {
+		t.Errorf("expected pixel (%d, %d) on the DEM, got (%d, %d)", int(wantX), int(wantY), x, y)
+	}
+	if p := c.PointFromPixel(int(wantX), int(wantY), 0); math.Abs(p[0]-point[0]) > 1e-3 || math.Abs(p[1]-point[1]) > 1e-3 {
+		t.Errorf("expected about %v, got %v", point, p)
+	}
+
+	// The tile's centre pixel shows the column seen at the DEM height
+	tile := maptile.At(orb.Point{10.1, 44.9}, 12)
+	data, err := c.ReadTile(tile)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	bounds := projectBound(tile.Bound(), proj.WebMercator)
+	lon, lat := proj.WebMercator.Inverse(
+		bounds.Min[0]+128.5*(bounds.Max[0]-bounds.Min[0])/256,
+		bounds.Max[1]-128.5*(bounds.Max[1]-bounds.Min[1])/256,
+	)
+	x, _ := rpc.GroundToImage(lon, lat, 1000)
+	if got := data.Float(0, 128, 128); got != math.Floor(x) {
+		t.Errorf("expected column %v, got %v", math.Floor(x), got)
+	}
+
+	if err := c.SetRPCDEM(nil); err != nil {
+		t.Fatalf("SetRPCDEM failed: %v", err)
+	}
+	if x, _ := c.PixelFromPoint(point, 0); x == int(wantX) {
+		t.Error("expected removing the DEM to restore the constant height")
+	}
+}
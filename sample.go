@@ -0,0 +1,201 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+)
+
+// PointSample holds the band values of an image at a point
+type PointSample struct {
+	Point  orb.Point // The sampled point (longitude/latitude)
+	Values []float64 // Value of each band, NaN where NoData
+	NoData []bool    // Whether each band is NoData at the point
+	Inside bool      // Whether the point falls inside the image; if not, every band is NoData
+}
+
+// Sample returns the value of every band of the main image at a longitude/
+// latitude point, taken from the nearest pixel
+func (c *COG) Sample(point orb.Point) (PointSample, error) {
+	samples, err := c.SampleMany([]orb.Point{point}, ResampleNearest)
+	if err != nil {
+		return PointSample{}, err
+	}
+	return samples[0], nil
+}
+
+// SampleMany samples the main image at many longitude/latitude points. The
+// points are reprojected into the image's CRS (longitudes are matched modulo
+// 360° for geographic images, such as 0–360 ones) and grouped by tile (or
+// strip), so each tile is fetched and decoded once however many points fall
+// in it. resampling is ResampleNearest or an interpolating kernel
+// (ResampleBilinear, ResampleCubic or ResampleLanczos); interpolation skips
+// NoData pixels, and a band whose nearest pixel is NoData is reported as
+// NoData. Results are in the order of points. Values are always raw
+// samples; ScaleOffset converts them to physical units (in metres with
+// SetElevationInMetres).
+func (c *COG) SampleMany(points []orb.Point, resampling Resampling) ([]PointSample, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image data available")
+	}
+	if resampling == ResampleAverage || resampling == ResampleMode {
+		return nil, fmt.Errorf("%v resampling is not supported for point sampling", resampling)
+	}
+	projection, err := c.Projection()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported CRS: %w", err)
+	}
+	gtr := c.geoTIFFs[0]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+	frameWest, _, wrap := c.longitudeFrame()
+	return c.samplePoints(points, func(p orb.Point) (float64, float64) {
+		x, y := projection.Forward(p[0], p[1])
+		if wrap {
+			x = wrapLongitude(x, frameWest)
+		}
+		return gtr.geoToPixel(x, y)
+	}, resampling)
+}
+
+// samplePoints samples the main image at points located in pixel space by
+// toPixel, fetching each block once
+func (c *COG) samplePoints(points []orb.Point, toPixel func(p orb.Point) (float64, float64), resampling Resampling) ([]PointSample, error) {
+	meta := c.metadata[0]
+	cache := newBlockCache(c, 0)
+
+	// Locate every point in pixel space and group the points by block
+	type pending struct {
+		index  int
+		sx, sy float64
+	}
+	groups := make(map[[2]int][]pending)
+	results := make([]PointSample, len(points))
+	for i, p := range points {
+		result := PointSample{Point: p, Values: make([]float64, meta.BandCount), NoData: make([]bool, meta.BandCount)}
+		for b := range result.Values {
+			result.Values[b] = math.NaN()
+			result.NoData[b] = true
+		}
+		results[i] = result
+
+		sx, sy := toPixel(p)
+		if !(sx >= 0 && sy >= 0 && sx < float64(meta.Width) && sy < float64(meta.Height)) {
+			continue
+		}
+		results[i].Inside = true
+		key := [2]int{int(sx) / cache.blockWidth, int(sy) / cache.blockHeight}
+		groups[key] = append(groups[key], pending{index: i, sx: sx, sy: sy})
+	}
+
+	// Visit blocks row by row, keeping only the block rows still reachable
+	// by the kernel in the cache
+	keys := make([][2]int, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][1] != keys[j][1] {
+			return keys[i][1] < keys[j][1]
+		}
+		return keys[i][0] < keys[j][0]
+	})
+
+	radius := resampling.support(1)
+	reach := int(math.Ceil(radius))
+	window := c.newRasterData(make([]uint64, (2*reach+2)*(2*reach+2)*meta.BandCount), 0, 0, meta.BandCount, orb.Bound{})
+	r := &resampler{method: resampling, src: window}
+	dst := make([]uint64, meta.BandCount)
+
+	for _, key := range keys {
+		cache.evictRowsBefore(key[1]*cache.blockHeight - reach)
+
+		for _, pt := range groups[key] {
+			// Gather the pixels around the point that the kernel can reach
+			minX := max(0, int(math.Floor(pt.sx-radius)))
+			minY := max(0, int(math.Floor(pt.sy-radius)))
+			maxX := min(meta.Width-1, int(math.Floor(pt.sx+radius)))
+			maxY := min(meta.Height-1, int(math.Floor(pt.sy+radius)))
+			window.Width, window.Height = maxX-minX+1, maxY-minY+1
+			window.Data = window.Data[:window.Width*window.Height*meta.BandCount]
+			for y := minY; y <= maxY; y++ {
+				for x := minX; x <= maxX; x++ {
+					values, err := cache.pixel(x, y)
+					if err != nil {
+						return nil, err
+					}
+					copy(window.Data[window.Index(0, x-minX, y-minY):], values)
+				}
+			}
+
+			r.x0, r.y0 = minX, minY
+			r.sample(dst, pt.sx, pt.sy, 1, 1)
+
+			result := &results[pt.index]
+			for b, raw := range dst {
+				v := SampleToFloat64(raw, window.DataType)
+				if window.isNoDataValue(v) {
+					continue
+				}
+				result.Values[b] = v
+				result.NoData[b] = false
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// blockCache holds the decoded tiles (or strips) of one IFD
+type blockCache struct {
+	c                       *COG
+	ifdIndex                int
+	blockWidth, blockHeight int
+	blocks                  map[[2]int]*RasterData
+}
+
+func newBlockCache(c *COG, ifdIndex int) *blockCache {
+	meta := c.metadata[ifdIndex]
+	blockWidth, blockHeight := c.blockSize(ifdIndex)
+	if blockWidth <= 0 || blockHeight <= 0 {
+		blockWidth, blockHeight = meta.Width, meta.Height
+	}
+	return &blockCache{
+		c:           c,
+		ifdIndex:    ifdIndex,
+		blockWidth:  blockWidth,
+		blockHeight: blockHeight,
+		blocks:      make(map[[2]int]*RasterData),
+	}
+}
+
+// pixel returns the samples of every band of pixel (x, y), reading its block
+// if it is not cached
+func (bc *blockCache) pixel(x, y int) ([]uint64, error) {
+	key := [2]int{x / bc.blockWidth, y / bc.blockHeight}
+	block, ok := bc.blocks[key]
+	if !ok {
+		meta := bc.c.metadata[bc.ifdIndex]
+		x0, y0 := key[0]*bc.blockWidth, key[1]*bc.blockHeight
+		var err error
+		block, err = bc.c.readRaster(bc.ifdIndex, x0, y0, min(bc.blockWidth, meta.Width-x0), min(bc.blockHeight, meta.Height-y0))
+		if err != nil {
+			return nil, err
+		}
+		bc.blocks[key] = block
+	}
+	i := block.Index(0, x-key[0]*bc.blockWidth, y-key[1]*bc.blockHeight)
+	return block.Data[i : i+block.Bands], nil
+}
+
+// evictRowsBefore drops cached blocks lying entirely above pixel row y
+func (bc *blockCache) evictRowsBefore(y int) {
+	for key := range bc.blocks {
+		if (key[1]+1)*bc.blockHeight <= y {
+			delete(bc.blocks, key)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package gocog
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// sampleRaster is 64x64 pixels of 0.01° from 10°E 51°N; values encode the
+// pixel position and column 40 is NoData
+var sampleRaster = testRaster{
+	Width: 64, Height: 64, DataType: DTFloat, TileSize: 16, EPSG: 4326,
+	Origin: [2]float64{10, 51}, PixelSize: [2]float64{0.01, 0.01}, NoData: "-9999",
+	Value: func(band, x, y int) float64 {
+		if x == 40 {
+			return -9999
+		}
+		return float64(x + 1000*y)
+	},
+}
+
+// pixelCentre returns the longitude and latitude of a pixel centre of sampleRaster
+func pixelCentre(x, y float64) orb.Point {
+	return orb.Point{10 + (x+0.5)*0.01, 51 - (y+0.5)*0.01}
+}
+
+func TestSample(t *testing.T) {
+	c := sampleRaster.cog(t)
+
+	s, err := c.Sample(orb.Point{10.055, 50.985})
+	if err != nil {
+		t.Fatalf("Sample failed: %v", err)
+	}
+	if !s.Inside || s.NoData[0] || s.Values[0] != 1005 {
+		t.Errorf("expected 1005 inside the image, got %+v", s)
+	}
+
+	s, _ = c.Sample(pixelCentre(40, 3))
+	if !s.NoData[0] || !math.IsNaN(s.Values[0]) {
+		t.Errorf("expected NoData in column 40, got %+v", s)
+	}
+
+	s, _ = c.Sample(orb.Point{9.9, 50.5})
+	if s.Inside || !s.NoData[0] {
+		t.Errorf("expected a point west of the image to be outside, got %+v", s)
+	}
+}
+
+func TestSampleManyNearest(t *testing.T) {
+	c := sampleRaster.cog(t)
+	window, err := c.ReadWindow(Rectangle{Width: 64, Height: 64})
+	if err != nil {
+		t.Fatalf("ReadWindow failed: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	points := make([]orb.Point, 500)
+	for i := range points {
+		points[i] = orb.Point{10 + rng.Float64()*0.64, 51 - rng.Float64()*0.64}
+	}
+	samples, err := c.SampleMany(points, ResampleNearest)
+	if err != nil {
+		t.Fatalf("SampleMany failed: %v", err)
+	}
+	for i, s := range samples {
+		x := int((points[i][0] - 10) / 0.01)
+		y := int((51 - points[i][1]) / 0.01)
+		if s.Point != points[i] {
+			t.Fatalf("sample %d is out of order", i)
+		}
+		if window.IsNoData(0, x, y) != s.NoData[0] {
+			t.Fatalf("point %d: NoData mismatch at pixel (%d, %d)", i, x, y)
+		}
+		if !s.NoData[0] && s.Values[0] != window.Float(0, x, y) {
+			t.Fatalf("point %d: expected %v, got %v", i, window.Float(0, x, y), s.Values[0])
+		}
+	}
+}
+
+func TestSampleManyBilinear(t *testing.T) {
+	c := sampleRaster.cog(t)
+
+	points := []orb.Point{
+		pixelCentre(5, 1),       // Exactly on a pixel centre
+		pixelCentre(5.5, 1),     // Half way between two columns
+		pixelCentre(15.5, 15.5), // Across four tiles
+		pixelCentre(39.2, 8),    // Next to the NoData column
+		pixelCentre(40, 8),      // On the NoData column
+		pixelCentre(0, 0),       // Image corner
+	}
+	samples, err := c.SampleMany(points, ResampleBilinear)
+	if err != nil {
+		t.Fatalf("SampleMany failed: %v", err)
+	}
+
+	want := []float64{1005, 1005.5, 15515.5, 8039, math.NaN(), 0}
+	for i, s := range samples {
+		if math.IsNaN(want[i]) {
+			if !s.NoData[0] {
+				t.Errorf("point %d: expected NoData, got %v", i, s.Values[0])
+			}
+			continue
+		}
+		if s.NoData[0] || math.Abs(s.Values[0]-want[i]) > 1e-3 {
+			t.Errorf("point %d: expected %v, got %v (NoData %v)", i, want[i], s.Values[0], s.NoData[0])
+		}
+	}
+
+	if _, err := c.SampleMany(points, ResampleMode); err == nil {
+		t.Error("expected error for mode resampling")
+	}
+}
+
+func TestSampleManyReprojects(t *testing.T) {
+	tr := testRaster{
+		Width: 100, Height: 100, DataType: DTSShort, TileSize: 32, EPSG: 32633,
+		Origin: [2]float64{495000, 5005000}, PixelSize: [2]float64{100, 100},
+		Value: func(band, x, y int) float64 { return float64(x + 100*y) },
+	}
+	c := tr.cog(t)
+
+	utm, _ := proj.UTM(33, true)
+	point := orb.Point{15.01, 45.13}
+	x, y := utm.Forward(point[0], point[1])
+	want := math.Floor((x-495000)/100) + 100*math.Floor((5005000-y)/100)
+
+	s, err := c.Sample(point)
+	if err != nil {
+		t.Fatalf("Sample failed: %v", err)
+	}
+	if s.Values[0] != want {
+		t.Errorf("expected %v, got %v", want, s.Values[0])
+	}
+}
+
+func TestBlockCacheEviction(t *testing.T) {
+	c := sampleRaster.cog(t)
+	cache := newBlockCache(c, 0)
+	if cache.blockWidth != 16 || cache.blockHeight != 16 {
+		t.Fatalf("expected 16x16 blocks, got %dx%d", cache.blockWidth, cache.blockHeight)
+	}
+
+	for _, p := range [][2]int{{0, 0}, {20, 0}, {0, 20}, {5, 40}} {
+		if _, err := cache.pixel(p[0], p[1]); err != nil {
+			t.Fatalf("pixel failed: %v", err)
+		}
+	}
+	if len(cache.blocks) != 4 {
+		t.Fatalf("expected 4 cached blocks, got %d", len(cache.blocks))
+	}
+	cache.evictRowsBefore(31)
+	if len(cache.blocks) != 2 {
+		t.Errorf("expected the first block row to be evicted, %d blocks left", len(cache.blocks))
+	}
+}
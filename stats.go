@@ -0,0 +1,316 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultHistogramBuckets is the number of histogram buckets used when
+// StatisticsOptions.HistogramBuckets is not set
+const DefaultHistogramBuckets = 256
+
+// DefaultApproxSize is the minimum overview size (largest dimension, in pixels)
+// used for approximate statistics when StatisticsOptions.ApproxSize is not set
+const DefaultApproxSize = 1024
+
+// Histogram holds counts of values in equal-width buckets between Min and Max
+type Histogram struct {
+	Min    float64
+	Max    float64
+	Counts []int64
+}
+
+// BucketWidth returns the width of each histogram bucket
+func (h *Histogram) BucketWidth() float64 {
+	if len(h.Counts) == 0 {
+		return 0
+	}
+	return (h.Max - h.Min) / float64(len(h.Counts))
+}
+
+// Bucket returns the index of the bucket containing v, or -1 if v lies outside the histogram
+func (h *Histogram) Bucket(v float64) int {
+	if len(h.Counts) == 0 || v < h.Min || v > h.Max || math.IsNaN(v) {
+		return -1
+	}
+	if h.Max == h.Min {
+		return 0
+	}
+	i := int((v - h.Min) / (h.Max - h.Min) * float64(len(h.Counts)))
+	if i >= len(h.Counts) {
+		i = len(h.Counts) - 1
+	}
+	return i
+}
+
+// Total returns the number of values counted in the histogram
+func (h *Histogram) Total() int64 {
+	var total int64
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+// BandStatistics contains summary statistics for a single band.
+// Values are in physical units when the source defines a scale and offset.
+type BandStatistics struct {
+	Band        int // 0-based band index
+	Min         float64
+	Max         float64
+	Mean        float64
+	StdDev      float64 // Population standard deviation
+	ValidCount  int64   // Number of pixels that are not NoData
+	NoDataCount int64   // Number of NoData (or NaN) pixels
+	Histogram   *Histogram
+	Approximate bool // Computed from an overview rather than the full-resolution image
+}
+
+// StatisticsOptions controls how statistics are computed
+type StatisticsOptions struct {
+	// Approximate computes statistics from the smallest overview whose
+	// largest dimension is at least ApproxSize pixels, instead of
+	// streaming the full-resolution image.
+	Approximate bool
+	ApproxSize  int // Default DefaultApproxSize
+
+	// Bands limits the computation to the given 0-based bands (default all)
+	Bands []int
+
+	// HistogramBuckets is the number of histogram buckets (default
+	// DefaultHistogramBuckets). The histogram covers HistogramMin to
+	// HistogramMax if HistogramMin < HistogramMax; otherwise it covers the
+	// band's min to max, which needs a second pass over the data.
+	HistogramBuckets int
+	HistogramMin     float64
+	HistogramMax     float64
+}
+
+// statsAccumulator accumulates statistics for one band using Welford's algorithm
+type statsAccumulator struct {
+	count     int64
+	noData    int64
+	min       float64
+	max       float64
+	mean      float64
+	m2        float64
+	histogram *Histogram
+}
+
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (a *statsAccumulator) add(v float64) {
+	a.count++
+	if v < a.min {
+		a.min = v
+	}
+	if v > a.max {
+		a.max = v
+	}
+	delta := v - a.mean
+	a.mean += delta / float64(a.count)
+	a.m2 += delta * (v - a.mean)
+}
+
+func (a *statsAccumulator) result(band int) BandStatistics {
+	stats := BandStatistics{Band: band, ValidCount: a.count, NoDataCount: a.noData, Histogram: a.histogram}
+	if a.count > 0 {
+		stats.Min = a.min
+		stats.Max = a.max
+		stats.Mean = a.mean
+		stats.StdDev = math.Sqrt(a.m2 / float64(a.count))
+	}
+	return stats
+}
+
+// statsBand describes how to read one band's values during a statistics pass
+type statsBand struct {
+	band   int
+	scale  float64
+	offset float64
+}
+
+// computeStatistics runs one or two passes over the data produced by scan,
+// which calls visit for each chunk of the raster in turn
+func computeStatistics(bands []statsBand, opts StatisticsOptions, scan func(visit func(data *RasterData)) error) ([]BandStatistics, error) {
+	buckets := opts.HistogramBuckets
+	if buckets <= 0 {
+		buckets = DefaultHistogramBuckets
+	}
+	fixedRange := opts.HistogramMin < opts.HistogramMax
+
+	accs := make([]*statsAccumulator, len(bands))
+	for i := range accs {
+		accs[i] = newStatsAccumulator()
+		if fixedRange {
+			accs[i].histogram = &Histogram{Min: opts.HistogramMin, Max: opts.HistogramMax, Counts: make([]int64, buckets)}
+		}
+	}
+
+	// visitValues calls fn with every valid value of each band, in physical units
+	visitValues := func(data *RasterData, fn func(i int, v float64)) {
+		pixels := data.Width * data.Height
+		for i, b := range bands {
+			for p := 0; p < pixels; p++ {
+				v := SampleToFloat64(data.Data[p*data.Bands+b.band], data.DataType)
+				if data.masked(p) || data.isNoDataValue(v) {
+					accs[i].noData++
+					continue
+				}
+				fn(i, v*b.scale+b.offset)
+			}
+		}
+	}
+
+	err := scan(func(data *RasterData) {
+		visitValues(data, func(i int, v float64) {
+			accs[i].add(v)
+			if h := accs[i].histogram; h != nil {
+				if bucket := h.Bucket(v); bucket >= 0 {
+					h.Counts[bucket]++
+				}
+			}
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !fixedRange {
+		// Second pass: histogram over each band's actual range
+		noData := make([]int64, len(accs))
+		for i, acc := range accs {
+			noData[i] = acc.noData
+			if acc.count > 0 {
+				acc.histogram = &Histogram{Min: acc.min, Max: acc.max, Counts: make([]int64, buckets)}
+			}
+		}
+		err := scan(func(data *RasterData) {
+			visitValues(data, func(i int, v float64) {
+				if h := accs[i].histogram; h != nil {
+					if bucket := h.Bucket(v); bucket >= 0 {
+						h.Counts[bucket]++
+					}
+				}
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, acc := range accs {
+			acc.noData = noData[i]
+		}
+	}
+
+	results := make([]BandStatistics, len(bands))
+	for i, acc := range accs {
+		results[i] = acc.result(bands[i].band)
+	}
+	return results, nil
+}
+
+// selectStatisticsBands returns the bands to compute statistics for
+func selectStatisticsBands(bands []int, bandCount int, scaleOffset func(band int) (float64, float64)) ([]statsBand, error) {
+	if len(bands) == 0 {
+		bands = make([]int, bandCount)
+		for i := range bands {
+			bands[i] = i
+		}
+	}
+
+	result := make([]statsBand, len(bands))
+	for i, b := range bands {
+		if b < 0 || b >= bandCount {
+			return nil, fmt.Errorf("band %d out of range (raster has %d bands)", b, bandCount)
+		}
+		result[i] = statsBand{band: b, scale: 1}
+		if scaleOffset != nil {
+			result[i].scale, result[i].offset = scaleOffset(b)
+		}
+	}
+	return result, nil
+}
+
+// Statistics computes per-band statistics and histograms of the raster.
+// NoData and NaN values are excluded. The Approximate and ApproxSize
+// options are ignored.
+func (r *RasterData) Statistics(opts StatisticsOptions) ([]BandStatistics, error) {
+	bands, err := selectStatisticsBands(opts.Bands, r.Bands, nil)
+	if err != nil {
+		return nil, err
+	}
+	return computeStatistics(bands, opts, func(visit func(data *RasterData)) error {
+		visit(r)
+		return nil
+	})
+}
+
+// Statistics computes per-band statistics and histograms, excluding NoData
+// values and converting to physical units using the band scale and offset.
+//
+// In exact mode the full-resolution image is streamed one row of tiles at a
+// time, so memory use stays bounded regardless of image size. In approximate
+// mode the smallest adequate overview is read in one go.
+func (c *COG) Statistics(opts StatisticsOptions) ([]BandStatistics, error) {
+	if len(c.metadata) == 0 {
+		return nil, fmt.Errorf("no image metadata")
+	}
+
+	bands, err := selectStatisticsBands(opts.Bands, c.BandCount(), c.ScaleOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []BandStatistics
+	if opts.Approximate {
+		level := c.statisticsLevel(opts.ApproxSize)
+		meta := c.metadata[level]
+		data, err := c.readRaster(level, 0, 0, meta.Width, meta.Height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read overview %d: %w", level, err)
+		}
+		results, err = computeStatistics(bands, opts, func(visit func(data *RasterData)) error {
+			visit(data)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range results {
+			results[i].Approximate = level > 0
+		}
+	} else {
+		results, err = computeStatistics(bands, opts, func(visit func(data *RasterData)) error {
+			return c.forEachBlockRow(0, func(data *RasterData, y int) error {
+				visit(data)
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute statistics: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
+// statisticsLevel returns the smallest IFD whose largest dimension is at
+// least minSize pixels, falling back to the full-resolution image
+func (c *COG) statisticsLevel(minSize int) int {
+	if minSize <= 0 {
+		minSize = DefaultApproxSize
+	}
+
+	best := 0
+	bestSize := c.metadata[0].Width * c.metadata[0].Height
+	for i := 1; i < len(c.metadata); i++ {
+		meta := c.metadata[i]
+		size := meta.Width * meta.Height
+		if (meta.Width >= minSize || meta.Height >= minSize) && size < bestSize {
+			best, bestSize = i, size
+		}
+	}
+	return best
+}
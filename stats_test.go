@@ -0,0 +1,97 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRasterDataStatistics(t *testing.T) {
+	data := &RasterData{Width: 5, Height: 1, Bands: 1, DataType: DTFloat, NoData: -1, HasNoData: true}
+	for _, v := range []float64{2, 4, -1, 4, math.NaN()} {
+		data.Data = append(data.Data, Float64ToSample(v, DTFloat))
+	}
+
+	stats, err := data.Statistics(StatisticsOptions{HistogramBuckets: 2})
+	if err != nil {
+		t.Fatalf("Statistics failed: %v", err)
+	}
+	s := stats[0]
+	if s.ValidCount != 3 || s.NoDataCount != 2 {
+		t.Errorf("expected 3 valid and 2 nodata, got %d and %d", s.ValidCount, s.NoDataCount)
+	}
+	if s.Min != 2 || s.Max != 4 || math.Abs(s.Mean-10.0/3.0) > 1e-12 {
+		t.Errorf("unexpected min/max/mean %v/%v/%v", s.Min, s.Max, s.Mean)
+	}
+	if want := math.Sqrt(8.0 / 9.0); math.Abs(s.StdDev-want) > 1e-12 {
+		t.Errorf("expected stddev %v, got %v", want, s.StdDev)
+	}
+	if s.Histogram == nil || s.Histogram.Counts[0] != 1 || s.Histogram.Counts[1] != 2 {
+		t.Errorf("unexpected histogram %+v", s.Histogram)
+	}
+
+	if _, err := data.Statistics(StatisticsOptions{Bands: []int{1}}); err == nil {
+		t.Error("expected error for band out of range")
+	}
+}
+
+func TestCOGStatistics(t *testing.T) {
+	tr := testRaster{
+		Width: 64, Height: 48, Bands: 2, DataType: DTSShort, TileSize: 16, Overviews: 2,
+		EPSG: 4326, Origin: [2]float64{0, 48}, PixelSize: [2]float64{1, 1}, NoData: "0",
+		ExtraTags: []testTag{asciiTag(TagGDALMetadata, `<GDALMetadata>
+  <Item name="SCALE" sample="1" role="scale">0.5</Item>
+  <Item name="OFFSET" sample="1" role="offset">-10</Item>
+</GDALMetadata>`)},
+		Value: func(band, x, y int) float64 {
+			if x < 4 {
+				return 0
+			}
+			return float64(x + 100*band)
+		},
+	}
+	c := tr.open(t)
+
+	if scale, offset := c.ScaleOffset(1); scale != 0.5 || offset != -10 {
+		t.Errorf("expected scale 0.5 and offset -10, got %v and %v", scale, offset)
+	}
+	if scale, offset := c.ScaleOffset(0); scale != 1 || offset != 0 {
+		t.Errorf("expected default scale and offset for band 0, got %v and %v", scale, offset)
+	}
+
+	stats, err := c.Statistics(StatisticsOptions{HistogramBuckets: 60})
+	if err != nil {
+		t.Fatalf("Statistics failed: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 bands, got %d", len(stats))
+	}
+	if s := stats[0]; s.Min != 4 || s.Max != 63 || math.Abs(s.Mean-33.5) > 1e-9 || s.ValidCount != 60*48 || s.NoDataCount != 4*48 || s.Approximate {
+		t.Errorf("unexpected band 0 statistics %+v", s)
+	}
+	if h := stats[0].Histogram; h.Total() != 60*48 || h.Counts[0] != 48 {
+		t.Errorf("unexpected band 0 histogram %v", h.Counts)
+	}
+	// Band 1 in physical units: (x + 100) * 0.5 - 10
+	if s := stats[1]; s.Min != 42 || s.Max != 71.5 || math.Abs(s.Mean-56.75) > 1e-9 {
+		t.Errorf("unexpected band 1 statistics %+v", s)
+	}
+
+	approx, err := c.Statistics(StatisticsOptions{Approximate: true, ApproxSize: 16, Bands: []int{0}, HistogramMin: 0, HistogramMax: 64, HistogramBuckets: 4})
+	if err != nil {
+		t.Fatalf("approximate Statistics failed: %v", err)
+	}
+	// Overview 2 (16x12) samples every fourth column: 4, 8, ..., 60
+	if s := approx[0]; !s.Approximate || s.ValidCount != 15*12 || s.Min != 4 || s.Max != 60 || math.Abs(s.Mean-32) > 1e-9 {
+		t.Errorf("unexpected approximate statistics %+v", s)
+	}
+	if h := approx[0].Histogram; h.Min != 0 || h.Max != 64 || h.Counts[0] != 3*12 {
+		t.Errorf("unexpected approximate histogram %+v", h)
+	}
+
+	if level := c.statisticsLevel(30); level != 1 {
+		t.Errorf("expected overview 1 for 30 pixels, got %d", level)
+	}
+	if level := c.statisticsLevel(1000); level != 0 {
+		t.Errorf("expected full resolution for 1000 pixels, got %d", level)
+	}
+}
@@ -0,0 +1,414 @@
+package gocog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// testTag is a raw IFD entry used to build synthetic TIFF files
+type testTag struct {
+	id    uint16
+	typ   DataType
+	count uint32
+	data  []byte // little-endian value bytes
+}
+
+func shortsTag(id uint16, values ...uint16) testTag {
+	data := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint16(data[i*2:], v)
+	}
+	return testTag{id: id, typ: DTSShort, count: uint32(len(values)), data: data}
+}
+
+func longsTag(id uint16, values ...uint32) testTag {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[i*4:], v)
+	}
+	return testTag{id: id, typ: DTSLong, count: uint32(len(values)), data: data}
+}
+
+func doublesTag(id uint16, values ...float64) testTag {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(v))
+	}
+	return testTag{id: id, typ: DTDouble, count: uint32(len(values)), data: data}
+}
+
+func asciiTag(id uint16, s string) testTag {
+	data := append([]byte(s), 0)
+	return testTag{id: id, typ: DTASCII, count: uint32(len(data)), data: data}
+}
+
+// testIFD is an image directory together with its tile or strip payloads
+type testIFD struct {
+	tags   []testTag
+	chunks [][]byte
+	tiled  bool
+}
+
+// writeTestTIFF lays out a little-endian TIFF with all IFDs and their
+// out-of-line values first and the image payloads afterwards, like a COG.
+func writeTestTIFF(ifds []testIFD) []byte {
+	type layout struct {
+		tags        []testTag
+		offset      int
+		valueOffset []int
+	}
+
+	layouts := make([]layout, len(ifds))
+	offset := 8
+	for i, ifd := range ifds {
+		offsetsID, countsID := uint16(TagStripOffsets), uint16(TagStripByteCounts)
+		if ifd.tiled {
+			offsetsID, countsID = TagTileOffsets, TagTileByteCounts
+		}
+		counts := make([]uint32, len(ifd.chunks))
+		for j, chunk := range ifd.chunks {
+			counts[j] = uint32(len(chunk))
+		}
+		tags := append([]testTag{}, ifd.tags...)
+		tags = append(tags, longsTag(offsetsID, make([]uint32, len(ifd.chunks))...), longsTag(countsID, counts...))
+		sort.Slice(tags, func(a, b int) bool { return tags[a].id < tags[b].id })
+
+		l := layout{tags: tags, offset: offset, valueOffset: make([]int, len(tags))}
+		offset += 2 + 12*len(tags) + 4
+		for j, tag := range tags {
+			if len(tag.data) > 4 {
+				l.valueOffset[j] = offset
+				offset += len(tag.data) + len(tag.data)%2
+			}
+		}
+		layouts[i] = l
+	}
+
+	// Assign payload offsets now that the metadata size is known
+	for i, ifd := range ifds {
+		chunkOffsets := make([]uint32, len(ifd.chunks))
+		for j, chunk := range ifd.chunks {
+			chunkOffsets[j] = uint32(offset)
+			offset += len(chunk)
+		}
+		for j, tag := range layouts[i].tags {
+			if tag.id == TagStripOffsets || tag.id == TagTileOffsets {
+				layouts[i].tags[j] = longsTag(tag.id, chunkOffsets...)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint16(0x4949))
+	binary.Write(&buf, binary.LittleEndian, uint16(42))
+	binary.Write(&buf, binary.LittleEndian, uint32(8))
+
+	for i, l := range layouts {
+		binary.Write(&buf, binary.LittleEndian, uint16(len(l.tags)))
+		for j, tag := range l.tags {
+			binary.Write(&buf, binary.LittleEndian, tag.id)
+			binary.Write(&buf, binary.LittleEndian, uint16(tag.typ))
+			binary.Write(&buf, binary.LittleEndian, tag.count)
+			if len(tag.data) > 4 {
+				binary.Write(&buf, binary.LittleEndian, uint32(l.valueOffset[j]))
+			} else {
+				inline := make([]byte, 4)
+				copy(inline, tag.data)
+				buf.Write(inline)
+			}
+		}
+		next := uint32(0)
+		if i+1 < len(layouts) {
+			next = uint32(layouts[i+1].offset)
+		}
+		binary.Write(&buf, binary.LittleEndian, next)
+		for _, tag := range l.tags {
+			if len(tag.data) > 4 {
+				buf.Write(tag.data)
+				if len(tag.data)%2 == 1 {
+					buf.WriteByte(0)
+				}
+			}
+		}
+	}
+
+	for _, ifd := range ifds {
+		for _, chunk := range ifd.chunks {
+			buf.Write(chunk)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// testRaster describes a synthetic georeferenced image for tests
+type testRaster struct {
+	Width, Height int
+	Bands         int
+	DataType      DataType
+	TileSize      int // 0 writes a single strip
+	Overviews     int // number of successive 2x overviews
+
+	EPSG       int        // 0 writes no GeoKeys
+	Origin     [2]float64 // Geographic coordinates of the top-left corner
+	PixelSize  [2]float64 // Pixel size in X and Y (both positive)
+	RasterType uint16     // 0 defaults to PixelIsArea
+	NoData     string     // GDAL_NODATA tag value, empty for none
+
+	// Transform, if set, is written as a ModelTransformation tag instead
+	// of Origin and PixelSize
+	Transform GeoTransform
+
+	// GCPs, if set, are written as tie points without a pixel scale
+	GCPs []TiePoint
+
+	GeoKeys   []uint16 // Additional GeoKey entries (keyID, location, count, value)
+	ExtraTags []testTag
+
+	// Value returns the sample at full resolution. Overviews take the
+	// top-left sample of each 2^k block.
+	Value func(band, x, y int) float64
+
+	// Mask, if set, is written after each level as a 1-bit internal mask
+	// IFD, as GDAL does, sampled like Value
+	Mask func(x, y int) bool
+}
+
+// bytes encodes the raster as a TIFF file
+func (tr testRaster) bytes() []byte {
+	if tr.Bands == 0 {
+		tr.Bands = 1
+	}
+	if tr.DataType == 0 {
+		tr.DataType = DTByte
+	}
+	if tr.PixelSize == [2]float64{} {
+		tr.PixelSize = [2]float64{1, 1}
+	}
+	if tr.Value == nil {
+		tr.Value = func(band, x, y int) float64 { return 0 }
+	}
+
+	var ifds []testIFD
+	for level := 0; level <= tr.Overviews; level++ {
+		factor := 1 << level
+		width := (tr.Width + factor - 1) / factor
+		height := (tr.Height + factor - 1) / factor
+		value := func(band, x, y int) float64 {
+			return tr.Value(band, x*factor, y*factor)
+		}
+
+		ifd := testIFD{tags: tr.imageTags(width, height)}
+		if level > 0 {
+			ifd.tags = append(ifd.tags, longsTag(254, 1)) // NewSubfileType: reduced resolution
+		} else {
+			ifd.tags = append(ifd.tags, tr.geoTags()...)
+			ifd.tags = append(ifd.tags, tr.ExtraTags...)
+		}
+		if tr.NoData != "" {
+			ifd.tags = append(ifd.tags, asciiTag(TagGDALNoData, tr.NoData))
+		}
+
+		if tr.TileSize > 0 {
+			ifd.tiled = true
+			ifd.tags = append(ifd.tags, longsTag(322, uint32(tr.TileSize)), longsTag(323, uint32(tr.TileSize)))
+			for ty := 0; ty < (height+tr.TileSize-1)/tr.TileSize; ty++ {
+				for tx := 0; tx < (width+tr.TileSize-1)/tr.TileSize; tx++ {
+					ifd.chunks = append(ifd.chunks, tr.encode(tx*tr.TileSize, ty*tr.TileSize, tr.TileSize, tr.TileSize, width, height, value))
+				}
+			}
+		} else {
+			ifd.tags = append(ifd.tags, longsTag(278, uint32(height)))
+			ifd.chunks = append(ifd.chunks, tr.encode(0, 0, width, height, width, height, value))
+		}
+		ifds = append(ifds, ifd)
+		if tr.Mask != nil {
+			ifds = append(ifds, tr.maskIFD(level, width, height))
+		}
+	}
+
+	return writeTestTIFF(ifds)
+}
+
+// maskIFD returns a single-strip 1-bit mask IFD for a level
+func (tr testRaster) maskIFD(level, width, height int) testIFD {
+	subfileType := uint32(4) // Transparency mask
+	if level > 0 {
+		subfileType |= 1
+	}
+	rowBytes := (width + 7) / 8
+	strip := make([]byte, rowBytes*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if tr.Mask(x<<level, y<<level) {
+				strip[y*rowBytes+x/8] |= 0x80 >> (x % 8)
+			}
+		}
+	}
+	return testIFD{
+		tags: []testTag{
+			longsTag(254, subfileType),
+			longsTag(256, uint32(width)),
+			longsTag(257, uint32(height)),
+			shortsTag(258, 1),
+			shortsTag(259, CompressionNone),
+			shortsTag(262, 4), // PhotometricInterpretation: transparency mask
+			shortsTag(277, 1),
+			longsTag(278, uint32(height)),
+		},
+		chunks: [][]byte{strip},
+	}
+}
+
+// imageTags returns the baseline TIFF tags for one IFD
+func (tr testRaster) imageTags(width, height int) []testTag {
+	bits := uint16(getBytesPerSampleStatic(tr.DataType) * 8)
+	format := uint16(1)
+	switch tr.DataType {
+	case DTSByte, DTSShortS, DTSLongS:
+		format = 2
+	case DTFloat, DTDouble:
+		format = 3
+	}
+	photometric := uint16(1)
+	if tr.Bands >= 3 {
+		photometric = 2
+	}
+
+	bitsPerSample := make([]uint16, tr.Bands)
+	sampleFormat := make([]uint16, tr.Bands)
+	for i := range bitsPerSample {
+		bitsPerSample[i] = bits
+		sampleFormat[i] = format
+	}
+
+	return []testTag{
+		longsTag(256, uint32(width)),
+		longsTag(257, uint32(height)),
+		shortsTag(258, bitsPerSample...),
+		shortsTag(259, CompressionNone),
+		shortsTag(262, photometric),
+		shortsTag(277, uint16(tr.Bands)),
+		shortsTag(284, 1), // PlanarConfiguration: chunky
+		shortsTag(339, sampleFormat...),
+	}
+}
+
+// geoTags returns the georeferencing tags of the main image
+func (tr testRaster) geoTags() []testTag {
+	if tr.EPSG == 0 && len(tr.GeoKeys) == 0 {
+		return nil
+	}
+
+	tags := []testTag{
+		doublesTag(TagModelPixelScale, tr.PixelSize[0], tr.PixelSize[1], 0),
+		doublesTag(TagModelTiepoint, 0, 0, 0, tr.Origin[0], tr.Origin[1], 0),
+	}
+	if len(tr.GCPs) > 0 {
+		var values []float64
+		for _, g := range tr.GCPs {
+			values = append(values, g.PixelX, g.PixelY, 0, g.GeoX, g.GeoY, 0)
+		}
+		tags = []testTag{doublesTag(TagModelTiepoint, values...)}
+	}
+	if gt := tr.Transform; gt != (GeoTransform{}) {
+		tags = []testTag{doublesTag(TagModelTransformation,
+			gt[1], gt[2], 0, gt[0],
+			gt[4], gt[5], 0, gt[3],
+			0, 0, 0, 0,
+			0, 0, 0, 1)}
+	}
+
+	rasterType := tr.RasterType
+	if rasterType == 0 {
+		rasterType = GTRasterTypePixelIsArea
+	}
+
+	keys := []uint16{GTRasterTypeGeoKey, 0, 1, rasterType}
+	if tr.EPSG != 0 {
+		if tr.EPSG >= 4000 && tr.EPSG < 5000 {
+			keys = append(keys, GTModelTypeGeoKey, 0, 1, GTModelTypeGeographic, GeographicTypeGeoKey, 0, 1, uint16(tr.EPSG))
+		} else {
+			keys = append(keys, GTModelTypeGeoKey, 0, 1, GTModelTypeProjected, ProjectedCSTypeGeoKey, 0, 1, uint16(tr.EPSG))
+		}
+	}
+	keys = append(keys, tr.GeoKeys...)
+
+	directory := []uint16{1, 1, 0, uint16(len(keys) / 4)}
+	directory = append(directory, keys...)
+	return append(tags, shortsTag(TagGeoKeyDirectory, directory...))
+}
+
+// encode writes a chunk of samples; positions outside the image are zero-filled
+func (tr testRaster) encode(x0, y0, chunkWidth, chunkHeight, width, height int, value func(band, x, y int) float64) []byte {
+	bytesPerSample := getBytesPerSampleStatic(tr.DataType)
+	out := make([]byte, chunkWidth*chunkHeight*tr.Bands*bytesPerSample)
+	for y := 0; y < chunkHeight; y++ {
+		for x := 0; x < chunkWidth; x++ {
+			if x0+x >= width || y0+y >= height {
+				continue
+			}
+			for b := 0; b < tr.Bands; b++ {
+				offset := ((y*chunkWidth+x)*tr.Bands + b) * bytesPerSample
+				putTestSample(out[offset:], tr.DataType, value(b, x0+x, y0+y))
+			}
+		}
+	}
+	return out
+}
+
+// putTestSample writes a numeric value in the binary form of a data type
+func putTestSample(dst []byte, dataType DataType, v float64) {
+	switch dataType {
+	case DTSByte:
+		dst[0] = byte(int8(v))
+	case DTSShort:
+		binary.LittleEndian.PutUint16(dst, uint16(v))
+	case DTSShortS:
+		binary.LittleEndian.PutUint16(dst, uint16(int16(v)))
+	case DTSLong:
+		binary.LittleEndian.PutUint32(dst, uint32(v))
+	case DTSLongS:
+		binary.LittleEndian.PutUint32(dst, uint32(int32(v)))
+	case DTFloat:
+		binary.LittleEndian.PutUint32(dst, math.Float32bits(float32(v)))
+	case DTDouble:
+		binary.LittleEndian.PutUint64(dst, math.Float64bits(v))
+	default:
+		dst[0] = byte(v)
+	}
+}
+
+// cog builds the raster and reads it into a COG
+func (tr testRaster) cog(t testing.TB) *COG {
+	t.Helper()
+	c, err := Read(bytes.NewReader(tr.bytes()))
+	if err != nil {
+		t.Fatalf("failed to read synthetic COG: %v", err)
+	}
+	return c
+}
+
+// open writes the raster to a temporary file and opens it with Open
+func (tr testRaster) open(t testing.TB) *COG {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.tif")
+	if err := os.WriteFile(path, tr.bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write synthetic COG: %v", err)
+	}
+	c, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("failed to open synthetic COG: %v", err)
+	}
+	t.Cleanup(func() {
+		if f, ok := c.reader.(*os.File); ok {
+			f.Close()
+		}
+	})
+	return c
+}
@@ -567,6 +567,16 @@ func (tr *TIFFReader) readInlineValue(tag *Tag) interface{} {
 			return int32(tag.Offset)
 		}
 		return []int32{int32(tag.Offset)}
+	case DTASCII:
+		// Short strings (up to 4 bytes including the null terminator) are stored inline
+		buf := make([]byte, 4)
+		tr.byteOrder.PutUint32(buf, tag.Offset)
+		buf = buf[:tag.Count]
+		// Remove null terminator
+		if len(buf) > 0 && buf[len(buf)-1] == 0 {
+			buf = buf[:len(buf)-1]
+		}
+		return string(buf)
 	default:
 		return tag.Offset
 	}
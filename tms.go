@@ -0,0 +1,302 @@
+package gocog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// Corners of origin of a tile matrix
+const (
+	CornerTopLeft    = "topLeft"
+	CornerBottomLeft = "bottomLeft"
+)
+
+// metresPerDegree is the length of one degree of longitude at the equator,
+// used for scale denominators of geographic tile matrix sets
+const metresPerDegree = 2 * math.Pi * 6378137 / 360
+
+// standardPixelSize is the 0.28 mm pixel used by OGC scale denominators
+const standardPixelSize = 0.00028
+
+// TileMatrix is one zoom level of a TileMatrixSet, as defined by OGC Two
+// Dimensional Tile Matrix Set (TMS) 2.0
+type TileMatrix struct {
+	ID               string     `json:"id"`
+	ScaleDenominator float64    `json:"scaleDenominator"`
+	CellSize         float64    `json:"cellSize"`                 // Pixel size in CRS units
+	CornerOfOrigin   string     `json:"cornerOfOrigin,omitempty"` // topLeft (default) or bottomLeft
+	PointOfOrigin    [2]float64 `json:"pointOfOrigin"`            // In the CRS axis order
+	TileWidth        int        `json:"tileWidth"`
+	TileHeight       int        `json:"tileHeight"`
+	MatrixWidth      int        `json:"matrixWidth"`
+	MatrixHeight     int        `json:"matrixHeight"`
+}
+
+// TileMatrixSet is an OGC TMS 2.0 tile matrix set: a tiling scheme in an
+// arbitrary CRS. Zoom levels are addressed by their index in TileMatrices.
+// Variable matrix widths (coalesced tiles near the poles) are not supported.
+type TileMatrixSet struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title,omitempty"`
+	URI          string       `json:"uri,omitempty"`
+	CRS          string       `json:"crs"`                   // CRS URI, e.g. http://www.opengis.net/def/crs/EPSG/0/3857
+	OrderedAxes  []string     `json:"orderedAxes,omitempty"` // Axis abbreviations in CRS order, e.g. ["Y", "X"]
+	TileMatrices []TileMatrix `json:"tileMatrices"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The crs member may be a URI
+// string or an object with a uri member.
+func (tms *TileMatrixSet) UnmarshalJSON(data []byte) error {
+	type plain TileMatrixSet
+	var doc struct {
+		plain
+		CRS json.RawMessage `json:"crs"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	*tms = TileMatrixSet(doc.plain)
+
+	if len(doc.CRS) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(doc.CRS, &tms.CRS); err == nil {
+		return nil
+	}
+	var ref struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(doc.CRS, &ref); err != nil || ref.URI == "" {
+		return fmt.Errorf("unsupported crs member: %s", doc.CRS)
+	}
+	tms.CRS = ref.URI
+	return nil
+}
+
+// ParseTileMatrixSet parses an OGC TMS 2.0 JSON tile matrix set
+func ParseTileMatrixSet(data []byte) (*TileMatrixSet, error) {
+	var tms TileMatrixSet
+	if err := json.Unmarshal(data, &tms); err != nil {
+		return nil, fmt.Errorf("failed to parse tile matrix set: %w", err)
+	}
+	if tms.CRS == "" {
+		return nil, fmt.Errorf("tile matrix set %q has no CRS", tms.ID)
+	}
+	if len(tms.TileMatrices) == 0 {
+		return nil, fmt.Errorf("tile matrix set %q has no tile matrices", tms.ID)
+	}
+	for i, m := range tms.TileMatrices {
+		if m.CellSize <= 0 || m.TileWidth <= 0 || m.TileHeight <= 0 || m.MatrixWidth <= 0 || m.MatrixHeight <= 0 {
+			return nil, fmt.Errorf("tile matrix %d of %q has invalid dimensions", i, tms.ID)
+		}
+		if m.CornerOfOrigin != "" && m.CornerOfOrigin != CornerTopLeft && m.CornerOfOrigin != CornerBottomLeft {
+			return nil, fmt.Errorf("tile matrix %d of %q has unsupported corner of origin %q", i, tms.ID, m.CornerOfOrigin)
+		}
+	}
+	return &tms, nil
+}
+
+// TileMatrixSetByID returns a built-in tile matrix set: WebMercatorQuad,
+// WorldCRS84Quad, EuropeanETRS89_LAEAQuad or UTMnnWGS84Quad (nn = 01-60)
+func TileMatrixSetByID(id string) (*TileMatrixSet, error) {
+	switch id {
+	case "WebMercatorQuad":
+		return WebMercatorQuad(), nil
+	case "WorldCRS84Quad":
+		return WorldCRS84Quad(), nil
+	case "EuropeanETRS89_LAEAQuad":
+		return EuropeanETRS89LAEAQuad(), nil
+	}
+	if strings.HasPrefix(id, "UTM") && strings.HasSuffix(id, "WGS84Quad") {
+		if zone, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(id, "UTM"), "WGS84Quad")); err == nil {
+			return UTMWGS84Quad(zone)
+		}
+	}
+	return nil, fmt.Errorf("unknown tile matrix set %q", id)
+}
+
+// quadTileMatrixSet builds a set of 256 pixel tiles whose matrix doubles in
+// each direction per zoom level. origin is the top-left corner in x/y order.
+func quadTileMatrixSet(id, crs string, origin [2]float64, cellSize, metresPerUnit float64, width, height, maxZoom int, yFirst bool) *TileMatrixSet {
+	tms := &TileMatrixSet{
+		ID:  id,
+		URI: "http://www.opengis.net/def/tilematrixset/OGC/1.0/" + id,
+		CRS: crs,
+	}
+	pointOfOrigin := origin
+	if yFirst {
+		tms.OrderedAxes = []string{"Y", "X"}
+		pointOfOrigin = [2]float64{origin[1], origin[0]}
+	} else {
+		tms.OrderedAxes = []string{"X", "Y"}
+	}
+
+	for z := 0; z <= maxZoom; z++ {
+		size := cellSize / float64(uint64(1)<<z)
+		tms.TileMatrices = append(tms.TileMatrices, TileMatrix{
+			ID:               strconv.Itoa(z),
+			ScaleDenominator: size * metresPerUnit / standardPixelSize,
+			CellSize:         size,
+			CornerOfOrigin:   CornerTopLeft,
+			PointOfOrigin:    pointOfOrigin,
+			TileWidth:        256,
+			TileHeight:       256,
+			MatrixWidth:      width << z,
+			MatrixHeight:     height << z,
+		})
+	}
+	return tms
+}
+
+// WebMercatorQuad returns the OGC WebMercatorQuad tile matrix set (EPSG:3857),
+// equivalent to the XYZ tiles of maptile.Tile, for zoom levels 0-24
+func WebMercatorQuad() *TileMatrixSet {
+	const extent = 20037508.3427892
+	return quadTileMatrixSet("WebMercatorQuad", "http://www.opengis.net/def/crs/EPSG/0/3857",
+		[2]float64{-extent, extent}, 2*extent/256, 1, 1, 1, 24, false)
+}
+
+// WorldCRS84Quad returns the OGC WorldCRS84Quad tile matrix set (CRS84
+// longitude/latitude), with 2x1 tiles at zoom 0, for zoom levels 0-23
+func WorldCRS84Quad() *TileMatrixSet {
+	return quadTileMatrixSet("WorldCRS84Quad", "http://www.opengis.net/def/crs/OGC/1.3/CRS84",
+		[2]float64{-180, 90}, 180.0/256, metresPerDegree, 2, 1, 23, false)
+}
+
+// EuropeanETRS89LAEAQuad returns the OGC EuropeanETRS89_LAEAQuad tile matrix
+// set (EPSG:3035), for zoom levels 0-15
+func EuropeanETRS89LAEAQuad() *TileMatrixSet {
+	return quadTileMatrixSet("EuropeanETRS89_LAEAQuad", "http://www.opengis.net/def/crs/EPSG/0/3035",
+		[2]float64{2000000, 5500000}, 4500000.0/256, 1, 1, 1, 15, true)
+}
+
+// UTMWGS84Quad returns the OGC UTMnnWGS84Quad tile matrix set for a WGS84 UTM
+// zone (EPSG:326nn), covering both hemispheres with 1x2 tiles at zoom 0, for
+// zoom levels 0-24
+func UTMWGS84Quad(zone int) (*TileMatrixSet, error) {
+	if zone < 1 || zone > 60 {
+		return nil, fmt.Errorf("invalid UTM zone %d", zone)
+	}
+	const (
+		minX   = -9501965.72931276
+		extent = 20003931.4586255
+	)
+	return quadTileMatrixSet(fmt.Sprintf("UTM%02dWGS84Quad", zone), fmt.Sprintf("http://www.opengis.net/def/crs/EPSG/0/%d", 32600+zone),
+		[2]float64{minX, extent}, extent/256, 1, 1, 2, 24, false), nil
+}
+
+// epsgFromCRS extracts the EPSG code from a CRS URI or "EPSG:" identifier.
+// CRS84 is returned as 4326 with lonFirst set.
+func epsgFromCRS(crs string) (code int, lonFirst bool, err error) {
+	switch {
+	case strings.HasSuffix(crs, "/CRS84") || strings.EqualFold(crs, "OGC:CRS84"):
+		return 4326, true, nil
+	case strings.Contains(crs, "/def/crs/EPSG/"):
+		code, err = strconv.Atoi(crs[strings.LastIndex(crs, "/")+1:])
+	default:
+		code, err = ParseEPSGCode(crs)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("unsupported CRS %q", crs)
+	}
+	return code, false, nil
+}
+
+// Projection returns the projection of the set's CRS
+func (tms *TileMatrixSet) Projection() (proj.Projection, error) {
+	code, _, err := epsgFromCRS(tms.CRS)
+	if err != nil {
+		return nil, err
+	}
+	return proj.FromEPSG(code)
+}
+
+// yFirst reports whether points of origin are given northing (or latitude)
+// first, from OrderedAxes or else the CRS's official axis order
+func (tms *TileMatrixSet) yFirst() bool {
+	if len(tms.OrderedAxes) > 0 {
+		switch strings.ToLower(tms.OrderedAxes[0]) {
+		case "y", "n", "lat", "north", "northing":
+			return true
+		}
+		return false
+	}
+	code, lonFirst, err := epsgFromCRS(tms.CRS)
+	if err != nil || lonFirst {
+		return false
+	}
+	info, ok := proj.LookupCRS(code)
+	return ok && info.AxisOrder == proj.AxisNorthEast
+}
+
+// Matrix returns the tile matrix of zoom level z
+func (tms *TileMatrixSet) Matrix(z int) (*TileMatrix, error) {
+	if z < 0 || z >= len(tms.TileMatrices) {
+		return nil, fmt.Errorf("zoom level %d out of range (0-%d)", z, len(tms.TileMatrices)-1)
+	}
+	return &tms.TileMatrices[z], nil
+}
+
+// TileBounds returns the bounds of tile (x, y) of zoom level z in the set's
+// CRS, in x/y (easting/northing or longitude/latitude) order
+func (tms *TileMatrixSet) TileBounds(z, x, y int) (orb.Bound, error) {
+	m, err := tms.Matrix(z)
+	if err != nil {
+		return orb.Bound{}, err
+	}
+	if x < 0 || y < 0 || x >= m.MatrixWidth || y >= m.MatrixHeight {
+		return orb.Bound{}, fmt.Errorf("tile %d/%d/%d outside the %dx%d matrix", z, x, y, m.MatrixWidth, m.MatrixHeight)
+	}
+
+	originX, originY := m.PointOfOrigin[0], m.PointOfOrigin[1]
+	if tms.yFirst() {
+		originX, originY = originY, originX
+	}
+	spanX := m.CellSize * float64(m.TileWidth)
+	spanY := m.CellSize * float64(m.TileHeight)
+
+	minX := originX + float64(x)*spanX
+	if m.CornerOfOrigin == CornerBottomLeft {
+		minY := originY + float64(y)*spanY
+		return orb.Bound{Min: orb.Point{minX, minY}, Max: orb.Point{minX + spanX, minY + spanY}}, nil
+	}
+	maxY := originY - float64(y)*spanY
+	return orb.Bound{Min: orb.Point{minX, maxY - spanY}, Max: orb.Point{minX + spanX, maxY}}, nil
+}
+
+// ReadTMSTile reads tile (x, y) of zoom level z of a tile matrix set. The
+// image is warped into the set's CRS like ReadTile, producing a tile of the
+// matrix's tile size; the returned bounds are in the set's CRS, in x/y order.
+func (c *COG) ReadTMSTile(tms *TileMatrixSet, z, x, y int) (*RasterData, error) {
+	if len(c.geoTIFFs) == 0 {
+		return nil, fmt.Errorf("no image data available")
+	}
+
+	bounds, err := tms.TileBounds(z, x, y)
+	if err != nil {
+		return nil, err
+	}
+	projection, err := tms.Projection()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported tile matrix set CRS: %w", err)
+	}
+
+	m := &tms.TileMatrices[z]
+	mapping, err := c.gridMapping(projection, bounds, m.TileWidth, m.TileHeight)
+	if err != nil {
+		return nil, err
+	}
+	overview := c.bestOverview(mappingFactor(mapping, m.TileWidth, m.TileHeight))
+
+	data, err := c.warp(overview, m.TileWidth, m.TileHeight, mapping, bounds, c.resampling)
+	if err != nil {
+		return nil, fmt.Errorf("failed to warp tile: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,209 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog/proj"
+)
+
+func boundsClose(a, b orb.Bound, tolerance float64) bool {
+	return math.Abs(a.Min[0]-b.Min[0]) <= tolerance && math.Abs(a.Min[1]-b.Min[1]) <= tolerance &&
+		math.Abs(a.Max[0]-b.Max[0]) <= tolerance && math.Abs(a.Max[1]-b.Max[1]) <= tolerance
+}
+
+func TestWebMercatorQuadMatchesMaptile(t *testing.T) {
+	tms := WebMercatorQuad()
+	for _, tile := range []maptile.Tile{maptile.New(0, 0, 0), maptile.New(8, 5, 4), maptile.New(1023, 700, 10)} {
+		got, err := tms.TileBounds(int(tile.Z), int(tile.X), int(tile.Y))
+		if err != nil {
+			t.Fatalf("TileBounds failed: %v", err)
+		}
+		want := projectBound(tile.Bound(), proj.WebMercator)
+		if !boundsClose(got, want, 1e-3) {
+			t.Errorf("tile %v: expected %v, got %v", tile, want, got)
+		}
+	}
+
+	// Reading through the tile matrix set matches ReadTile
+	tile := maptile.New(8, 5, 4)
+	c := mercatorTileRaster(tile, 512, 1).cog(t)
+	xyz, err := c.ReadTile(tile)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	data, err := c.ReadTMSTile(tms, 4, 8, 5)
+	if err != nil {
+		t.Fatalf("ReadTMSTile failed: %v", err)
+	}
+	if data.Width != 256 || data.Height != 256 || data.Overview != xyz.Overview {
+		t.Fatalf("expected a 256x256 tile from overview %d, got %dx%d from overview %d", xyz.Overview, data.Width, data.Height, data.Overview)
+	}
+	for i := range xyz.Data {
+		if data.Data[i] != xyz.Data[i] {
+			t.Fatalf("sample %d differs: %d vs %d", i, data.Data[i], xyz.Data[i])
+		}
+	}
+}
+
+const laeaTMS = `{
+  "id": "EuropeanETRS89_LAEAQuad",
+  "crs": {"uri": "http://www.opengis.net/def/crs/EPSG/0/3035"},
+  "orderedAxes": ["Y", "X"],
+  "tileMatrices": [
+    {"id": "0", "scaleDenominator": 62779017.857142866, "cellSize": 17578.125, "cornerOfOrigin": "topLeft",
+     "pointOfOrigin": [5500000.0, 2000000.0], "tileWidth": 256, "tileHeight": 256, "matrixWidth": 1, "matrixHeight": 1},
+    {"id": "1", "scaleDenominator": 31389508.928571433, "cellSize": 8789.0625,
+     "pointOfOrigin": [5500000.0, 2000000.0], "tileWidth": 256, "tileHeight": 256, "matrixWidth": 2, "matrixHeight": 2}
+  ]
+}`
+
+func TestParseTileMatrixSet(t *testing.T) {
+	tms, err := ParseTileMatrixSet([]byte(laeaTMS))
+	if err != nil {
+		t.Fatalf("ParseTileMatrixSet failed: %v", err)
+	}
+	if tms.CRS != "http://www.opengis.net/def/crs/EPSG/0/3035" {
+		t.Errorf("unexpected CRS %q", tms.CRS)
+	}
+
+	// The point of origin is northing first
+	want := orb.Bound{Min: orb.Point{2000000, 1000000}, Max: orb.Point{6500000, 5500000}}
+	if got, _ := tms.TileBounds(0, 0, 0); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	want = orb.Bound{Min: orb.Point{4250000, 3250000}, Max: orb.Point{6500000, 5500000}}
+	if got, _ := tms.TileBounds(1, 1, 0); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Without orderedAxes, the axis order comes from the CRS
+	tms.OrderedAxes = nil
+	if got, _ := tms.TileBounds(1, 1, 0); got != want {
+		t.Errorf("expected EPSG:3035 to be northing first, got %v", got)
+	}
+
+	// The built-in set agrees
+	builtin, err := TileMatrixSetByID("EuropeanETRS89_LAEAQuad")
+	if err != nil {
+		t.Fatalf("TileMatrixSetByID failed: %v", err)
+	}
+	if got, _ := builtin.TileBounds(1, 1, 0); got != want {
+		t.Errorf("built-in set: expected %v, got %v", want, got)
+	}
+
+	if _, err := tms.TileBounds(1, 2, 0); err == nil {
+		t.Error("expected error for a tile outside the matrix")
+	}
+	if _, err := tms.TileBounds(2, 0, 0); err == nil {
+		t.Error("expected error for an undefined zoom level")
+	}
+	if _, err := ParseTileMatrixSet([]byte(`{"id": "empty", "crs": "EPSG:3857", "tileMatrices": []}`)); err == nil {
+		t.Error("expected error for a set without tile matrices")
+	}
+}
+
+func TestTileMatrixSetBottomLeft(t *testing.T) {
+	tms, err := ParseTileMatrixSet([]byte(`{
+	  "id": "custom", "crs": "http://www.opengis.net/def/crs/EPSG/0/32633",
+	  "tileMatrices": [{"id": "0", "cellSize": 10, "cornerOfOrigin": "bottomLeft", "pointOfOrigin": [400000, 4900000],
+	    "tileWidth": 100, "tileHeight": 50, "matrixWidth": 10, "matrixHeight": 10}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseTileMatrixSet failed: %v", err)
+	}
+	want := orb.Bound{Min: orb.Point{401000, 4901000}, Max: orb.Point{402000, 4901500}}
+	if got, _ := tms.TileBounds(0, 1, 2); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestReadTMSTileWorldCRS84Quad(t *testing.T) {
+	tr := testRaster{
+		Width: 100, Height: 100, DataType: DTSShort, TileSize: 32, EPSG: 4326,
+		Origin: [2]float64{10, 51}, PixelSize: [2]float64{0.01, 0.01},
+		Value: func(band, x, y int) float64 { return float64(x) },
+	}
+	c := tr.cog(t)
+	tms := WorldCRS84Quad()
+
+	// Zoom 8 tile containing 10.5°E 50.5°N
+	cell := tms.TileMatrices[8].CellSize
+	x := int(math.Floor((10.5 + 180) / (256 * cell)))
+	y := int(math.Floor((90 - 50.5) / (256 * cell)))
+	data, err := c.ReadTMSTile(tms, 8, x, y)
+	if err != nil {
+		t.Fatalf("ReadTMSTile failed: %v", err)
+	}
+	if !data.Bounds.Contains(orb.Point{10.5, 50.5}) {
+		t.Fatalf("expected tile bounds %v to contain (10.5, 50.5)", data.Bounds)
+	}
+
+	inside := 0
+	for py := 0; py < 256; py += 15 {
+		for px := 0; px < 256; px += 15 {
+			lon := data.Bounds.Min[0] + (float64(px)+0.5)*cell
+			lat := data.Bounds.Max[1] - (float64(py)+0.5)*cell
+			col := math.Floor((lon - 10) / 0.01)
+			row := math.Floor((51 - lat) / 0.01)
+			if col < 0 || col >= 100 || row < 0 || row >= 100 {
+				if !data.IsNoData(0, px, py) {
+					t.Fatalf("pixel (%d, %d) outside the image is not masked", px, py)
+				}
+				continue
+			}
+			inside++
+			if got := data.Float(0, px, py); got != col {
+				t.Fatalf("pixel (%d, %d): expected %v, got %v", px, py, col, got)
+			}
+		}
+	}
+	if inside == 0 {
+		t.Error("expected part of the tile to cover the image")
+	}
+}
+
+func TestReadTMSTileUTM(t *testing.T) {
+	tr := testRaster{
+		Width: 100, Height: 100, DataType: DTSShort, TileSize: 32, EPSG: 32633,
+		Origin: [2]float64{495000, 5005000}, PixelSize: [2]float64{100, 100},
+		Value: func(band, x, y int) float64 { return float64(x) },
+	}
+	c := tr.cog(t)
+
+	tms, err := TileMatrixSetByID("UTM33WGS84Quad")
+	if err != nil {
+		t.Fatalf("TileMatrixSetByID failed: %v", err)
+	}
+	if _, err := TileMatrixSetByID("UTM61WGS84Quad"); err == nil {
+		t.Error("expected error for an invalid UTM zone")
+	}
+
+	span := 256 * tms.TileMatrices[10].CellSize
+	origin := tms.TileMatrices[10].PointOfOrigin
+	x := int(math.Floor((500000 - origin[0]) / span))
+	y := int(math.Floor((origin[1] - 5000000) / span))
+	data, err := c.ReadTMSTile(tms, 10, x, y)
+	if err != nil {
+		t.Fatalf("ReadTMSTile failed: %v", err)
+	}
+	if !data.Bounds.Contains(orb.Point{500000, 5000000}) {
+		t.Fatalf("expected tile bounds %v to contain the image centre", data.Bounds)
+	}
+
+	// The tile is in the image's own CRS: 100 m pixels over 76 m tile pixels
+	gt, _ := c.GeoTransform(0)
+	for _, px := range []int{10, 100, 200} {
+		geoX := data.Bounds.Min[0] + (float64(px)+0.5)*tms.TileMatrices[10].CellSize
+		col := math.Floor((geoX - gt[0]) / gt[1])
+		if col < 0 || col >= 100 {
+			continue
+		}
+		py := int((data.Bounds.Max[1] - 5000000) / tms.TileMatrices[10].CellSize)
+		if got := data.Float(0, px, py); got != col {
+			t.Errorf("pixel %d: expected %v, got %v", px, col, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package gocog
+
+import "fmt"
+
+// VerticalCRS describes the vertical reference of the heights in an image
+type VerticalCRS struct {
+	Name  string // e.g. "NAVD88 height"
+	Code  int    // EPSG code, 0 if user-defined
+	Datum VerticalDatum
+	Unit  Unit // Unit of the heights, metres if the GeoKeys give none
+
+	// Ellipsoidal is set for heights above the ellipsoid; otherwise heights
+	// are gravity-related (orthometric), above a geoid or mean sea level
+	Ellipsoidal bool
+}
+
+// VerticalDatum is a vertical datum
+type VerticalDatum struct {
+	Name string
+	Code int // EPSG code, 0 if user-defined or unknown
+}
+
+// VerticalCRS returns the vertical CRS of the image's values from its
+// vertical GeoKeys, and false if it has none. Units without a known size
+// have a Factor of 0.
+func (c *COG) VerticalCRS() (VerticalCRS, bool) {
+	if len(c.metadata) == 0 {
+		return VerticalCRS{}, false
+	}
+	return verticalCRSFromGeoKeys(c.metadata[0].GeoKeys)
+}
+
+// verticalCRSFromGeoKeys interprets the vertical GeoKeys
+func verticalCRSFromGeoKeys(keys map[uint16]interface{}) (VerticalCRS, bool) {
+	k := geoKeys(keys)
+	code, hasCode := k.code(VerticalCSTypeGeoKey)
+	datumCode, hasDatum := k.code(VerticalDatumGeoKey)
+	unitCode, hasUnit := k.code(VerticalUnitsGeoKey)
+	citation := k.citation(VerticalCitationGeoKey)
+	if !hasCode && !hasDatum && !hasUnit && citation == "" {
+		return VerticalCRS{}, false
+	}
+
+	v := VerticalCRS{Name: citation, Unit: unitMetre}
+	switch known, ok := verticalCRSs[code]; {
+	case !hasCode || code == 0 || code == userDefined:
+	case ok:
+		v = known
+		v.Code = code
+	case code > 5000 && code <= 5033:
+		v.Code, v.Ellipsoidal = code, true
+	default:
+		v.Code = code
+	}
+
+	if hasDatum && datumCode != 0 && datumCode != userDefined && v.Datum.Code == 0 {
+		v.Datum = VerticalDatum{Name: fmt.Sprintf("EPSG:%d", datumCode), Code: datumCode}
+		for _, known := range verticalCRSs {
+			if known.Datum.Code == datumCode {
+				v.Datum = known.Datum
+				v.Ellipsoidal = known.Ellipsoidal
+				break
+			}
+		}
+	}
+	if hasUnit && unitCode != 0 && unitCode != userDefined {
+		unit, ok := linearUnits[unitCode]
+		if !ok {
+			unit = Unit{Name: fmt.Sprintf("EPSG:%d", unitCode), Code: unitCode}
+		}
+		v.Unit = unit
+	}
+
+	if citation != "" {
+		v.Name = citation
+	}
+	if v.Name == "" {
+		v.Name = "unknown"
+		if v.Code != 0 {
+			v.Name = fmt.Sprintf("EPSG:%d", v.Code)
+		}
+	}
+	return v, true
+}
+
+// SetElevationInMetres converts elevations to metres when the vertical CRS
+// gives them in another unit, such as feet. The unit's size is folded into
+// ScaleOffset, so statistics, zonal statistics, profiles and rendering use
+// metres. Raw samples in RasterData and SampleMany are unchanged; apply
+// ScaleOffset to them for metres.
+func (c *COG) SetElevationInMetres(enabled bool) {
+	c.elevationInMetres = enabled
+}
+
+// ElevationInMetres returns the setting of SetElevationInMetres
+func (c *COG) ElevationInMetres() bool {
+	return c.elevationInMetres
+}
+
+// elevationFactor returns the factor converting values to metres when
+// SetElevationInMetres is enabled, and 1 otherwise
+func (c *COG) elevationFactor() float64 {
+	if !c.elevationInMetres {
+		return 1
+	}
+	v, ok := c.VerticalCRS()
+	if !ok || v.Unit.Factor == 0 {
+		return 1
+	}
+	return v.Unit.Factor
+}
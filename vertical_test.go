@@ -0,0 +1,100 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// feetDEM is a 32 m UTM DEM whose heights are NAVD88 in US survey feet
+var feetDEM = testRaster{
+	Width: 64, Height: 64, DataType: DTFloat, TileSize: 32, EPSG: 32618,
+	Origin: [2]float64{500000, 4500000}, PixelSize: [2]float64{32, 32},
+	GeoKeys: []uint16{VerticalCSTypeGeoKey, 0, 1, 6360},
+	Value:   func(band, x, y int) float64 { return 1000 },
+}
+
+func TestVerticalCRS(t *testing.T) {
+	c := feetDEM.cog(t)
+	v, ok := c.VerticalCRS()
+	if !ok {
+		t.Fatal("expected a vertical CRS")
+	}
+	if v.Name != "NAVD88 height (ftUS)" || v.Code != 6360 || v.Datum.Code != 5103 || v.Ellipsoidal || v.Unit.Code != 9003 {
+		t.Errorf("unexpected vertical CRS %+v", v)
+	}
+
+	// User-defined vertical CS on a coded datum with an explicit unit
+	tr := feetDEM
+	tr.GeoKeys = []uint16{
+		VerticalCSTypeGeoKey, 0, 1, userDefined,
+		VerticalDatumGeoKey, 0, 1, 5171,
+		VerticalUnitsGeoKey, 0, 1, 9002,
+	}
+	v, ok = tr.cog(t).VerticalCRS()
+	if !ok || v.Code != 0 || v.Datum.Name != "EGM96 geoid" || v.Unit.Factor != 0.3048 {
+		t.Errorf("unexpected user-defined vertical CRS %+v", v)
+	}
+
+	// GeoTIFF 1.0 ellipsoidal heights
+	tr.GeoKeys = []uint16{VerticalCSTypeGeoKey, 0, 1, 5030}
+	if v, ok = tr.cog(t).VerticalCRS(); !ok || !v.Ellipsoidal || v.Unit != unitMetre {
+		t.Errorf("expected ellipsoidal heights in metres, got %+v", v)
+	}
+
+	tr.GeoKeys = nil
+	if _, ok := tr.cog(t).VerticalCRS(); ok {
+		t.Error("expected no vertical CRS without vertical GeoKeys")
+	}
+}
+
+func TestElevationInMetres(t *testing.T) {
+	c := feetDEM.cog(t)
+	utm, _ := proj.UTM(18, true)
+	lon, lat := utm.Inverse(501000, 4499000)
+	point := orb.Point{lon, lat}
+	s, err := c.Sample(point)
+	if err != nil {
+		t.Fatalf("Sample failed: %v", err)
+	}
+	if !s.Inside || s.Values[0] != 1000 {
+		t.Fatalf("expected 1000 ftUS, got %+v", s)
+	}
+
+	c.SetElevationInMetres(true)
+	const metres = 1000 * 1200.0 / 3937
+	if s, _ = c.Sample(point); s.Values[0] != 1000 {
+		t.Errorf("expected the raw sample whatever the unit setting, got %v", s.Values[0])
+	}
+	if scale, offset := c.ScaleOffset(0); math.Abs(scale-1200.0/3937) > 1e-15 || offset != 0 {
+		t.Errorf("expected the unit size in the scale, got %v and %v", scale, offset)
+	}
+	stats, err := c.Statistics(StatisticsOptions{})
+	if err != nil {
+		t.Fatalf("Statistics failed: %v", err)
+	}
+	if math.Abs(stats[0].Max-metres) > 1e-9 {
+		t.Errorf("expected a maximum of %v m, got %v", metres, stats[0].Max)
+	}
+
+	// Samples stay raw with a GDAL scale and offset, which ScaleOffset
+	// converts to metres once
+	tr := feetDEM
+	tr.ExtraTags = []testTag{asciiTag(TagGDALMetadata, `<GDALMetadata>
+  <Item name="SCALE" sample="0" role="scale">0.5</Item>
+  <Item name="OFFSET" sample="0" role="offset">100</Item>
+</GDALMetadata>`)}
+	c = tr.cog(t)
+	c.SetElevationInMetres(true)
+	const scaled = (1000*0.5 + 100) * 1200.0 / 3937
+	s, err = c.Sample(point)
+	if err != nil {
+		t.Fatalf("Sample failed: %v", err)
+	}
+	scale, offset := c.ScaleOffset(0)
+	if got := s.Values[0]*scale + offset; s.Values[0] != 1000 || math.Abs(got-scaled) > 1e-9 {
+		t.Errorf("expected raw 1000 giving %v m, got %v giving %v m", scaled, s.Values[0], got)
+	}
+}
@@ -0,0 +1,189 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/tingold/gocog/proj"
+)
+
+// pixelMapping maps a position on an output grid (in output pixels, with
+// pixel centres at half-integers) to fractional pixel coordinates in the main
+// image. ok is false if the position has no corresponding source location.
+type pixelMapping func(x, y float64) (srcX, srcY float64, ok bool)
+
+// gridMapping returns the mapping from a width x height grid covering bounds
+// in projection dst to the main image. The grid is north-up: row 0 is at
+// bounds.Max[1].
+func (c *COG) gridMapping(dst proj.Projection, bounds orb.Bound, width, height int) (pixelMapping, error) {
+	src, err := c.Projection()
+	if err != nil {
+		return nil, fmt.Errorf("unsupported CRS: %w", err)
+	}
+	gtr := c.geoTIFFs[0]
+	if !gtr.georeferenced() {
+		return nil, fmt.Errorf("image is not georeferenced")
+	}
+
+	pixelWidth := (bounds.Max[0] - bounds.Min[0]) / float64(width)
+	pixelHeight := (bounds.Max[1] - bounds.Min[1]) / float64(height)
+	sameCRS := dst == src
+	frameWest, _, wrap := c.longitudeFrame()
+
+	return func(x, y float64) (float64, float64, bool) {
+		geoX := bounds.Min[0] + x*pixelWidth
+		geoY := bounds.Max[1] - y*pixelHeight
+		if !sameCRS {
+			lon, lat := dst.Inverse(geoX, geoY)
+			geoX, geoY = src.Forward(lon, lat)
+		}
+		if math.IsNaN(geoX) || math.IsNaN(geoY) || math.IsInf(geoX, 0) || math.IsInf(geoY, 0) {
+			return 0, 0, false
+		}
+		if wrap {
+			geoX = wrapLongitude(geoX, frameWest)
+		}
+		srcX, srcY := gtr.geoToPixel(geoX, geoY)
+		return srcX, srcY, true
+	}, nil
+}
+
+// warp builds a width x height raster by mapping the centre of every output
+// pixel through mapping to the image stored in IFD ifdIndex and resampling
+// the source pixels around it. Output pixels whose centres fall outside the
+// source image are filled with the NoData value (or zero) and masked.
+func (c *COG) warp(ifdIndex, width, height int, mapping pixelMapping, bounds orb.Bound, resampling Resampling) (*RasterData, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("output dimensions must be positive")
+	}
+	meta := c.metadata[ifdIndex]
+	mainMeta := c.metadata[0]
+	ifdScaleX := float64(meta.Width) / float64(mainMeta.Width)
+	ifdScaleY := float64(meta.Height) / float64(mainMeta.Height)
+
+	// Map every output pixel centre to the IFD's pixel space
+	coords := make([]float64, 2*width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := 2 * (y*width + x)
+			srcX, srcY, ok := mapping(float64(x)+0.5, float64(y)+0.5)
+			if !ok {
+				coords[i], coords[i+1] = math.NaN(), math.NaN()
+				continue
+			}
+			coords[i], coords[i+1] = srcX*ifdScaleX, srcY*ifdScaleY
+		}
+	}
+
+	// Columns of an image spanning 360° of longitude wrap around, so every
+	// column position is inside it
+	_, wrapX, _ := c.longitudeFrame()
+	if wrapX {
+		unwrapColumns(coords, width, height, float64(meta.Width))
+	}
+	inside := func(p int) bool {
+		sx, sy := coords[2*p], coords[2*p+1]
+		return (wrapX || sx >= 0 && sx < float64(meta.Width)) && sy >= 0 && sy < float64(meta.Height)
+	}
+
+	// scales returns the size of output pixel p in source pixels, from the
+	// distance to its neighbours' source positions
+	scales := func(p int) (float64, float64) {
+		if resampling == ResampleNearest {
+			return 1, 1
+		}
+		x, y := p%width, p/width
+		distance := func(q int) float64 {
+			d := math.Hypot(coords[2*q]-coords[2*p], coords[2*q+1]-coords[2*p+1])
+			if math.IsNaN(d) {
+				return 1
+			}
+			return d
+		}
+		scaleX, scaleY := 1.0, 1.0
+		if x+1 < width {
+			scaleX = distance(p + 1)
+		} else if x > 0 {
+			scaleX = distance(p - 1)
+		}
+		if y+1 < height {
+			scaleY = distance(p + width)
+		} else if y > 0 {
+			scaleY = distance(p - width)
+		}
+		return scaleX, scaleY
+	}
+
+	// Source window covering the support of every output pixel
+	minX, minY, maxX, maxY := math.MaxInt, meta.Height, math.MinInt, -1
+	for p := 0; p < width*height; p++ {
+		if !inside(p) {
+			continue
+		}
+		scaleX, scaleY := scales(p)
+		rx, ry := resampling.support(scaleX), resampling.support(scaleY)
+		sx, sy := coords[2*p], coords[2*p+1]
+		left, right := int(math.Floor(sx-rx)), int(math.Floor(sx+rx))
+		if !wrapX {
+			left, right = max(0, left), min(meta.Width-1, right)
+		}
+		minX = min(minX, left)
+		minY = min(minY, max(0, int(math.Floor(sy-ry))))
+		maxX = max(maxX, right)
+		maxY = max(maxY, min(meta.Height-1, int(math.Floor(sy+ry))))
+	}
+
+	result := c.newRasterData(make([]uint64, width*height*meta.BandCount), width, height, meta.BandCount, bounds)
+	result.Overview = ifdIndex
+	fill := uint64(0)
+	if result.HasNoData {
+		fill = Float64ToSample(result.NoData, result.DataType)
+	}
+
+	var r *resampler
+	if maxY >= 0 {
+		src, err := c.readRasterWrapped(ifdIndex, minX, minY, maxX-minX+1, maxY-minY+1)
+		if err != nil {
+			return nil, err
+		}
+		r = &resampler{method: resampling, src: src, x0: minX, y0: minY}
+	}
+
+	mask := make([]uint8, width*height)
+	outside := false
+	for p := 0; p < width*height; p++ {
+		dst := result.Data[p*meta.BandCount : (p+1)*meta.BandCount]
+		if !inside(p) {
+			outside = true
+			for b := range dst {
+				dst[b] = fill
+			}
+			continue
+		}
+		mask[p] = 255
+		scaleX, scaleY := scales(p)
+		r.sample(dst, coords[2*p], coords[2*p+1], scaleX, scaleY)
+	}
+	if outside {
+		result.Mask = mask
+	}
+
+	return result, nil
+}
+
+// unwrapColumns shifts the source columns in coords by multiples of the
+// image width so they are continuous across a width x height output grid,
+// taking the column of the centre pixel (or the first mapped one) as the
+// reference
+func unwrapColumns(coords []float64, width, height int, imageWidth float64) {
+	ref := coords[2*(height/2*width+width/2)]
+	for p := 0; math.IsNaN(ref) && p < width*height; p++ {
+		ref = coords[2*p]
+	}
+	for p := 0; p < width*height; p++ {
+		if sx := coords[2*p]; !math.IsNaN(sx) {
+			coords[2*p] = sx + imageWidth*math.Round((ref-sx)/imageWidth)
+		}
+	}
+}
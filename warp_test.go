@@ -0,0 +1,132 @@
+package gocog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/tingold/gocog/proj"
+)
+
+func TestReadTileWarpsImageEdge(t *testing.T) {
+	// 1° square of 0.01° pixels; values encode the pixel position
+	tr := testRaster{
+		Width: 100, Height: 100, DataType: DTSShort, TileSize: 32, EPSG: 4326,
+		Origin: [2]float64{10, 51}, PixelSize: [2]float64{0.01, 0.01}, NoData: "65535",
+		Value: func(band, x, y int) float64 { return float64(y*100 + x) },
+	}
+	c := tr.cog(t)
+
+	// Tile containing the north-east corner of the image, partly outside it
+	tile := maptile.At(orb.Point{11, 51}, 8)
+	data, err := c.ReadTile(tile, 64)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	if data.Mask == nil {
+		t.Fatal("expected pixels outside the image to be masked")
+	}
+
+	if data.Bounds != tile.Bound() {
+		t.Errorf("expected the tile's longitude/latitude bounds, got %v", data.Bounds)
+	}
+
+	inside, outside := 0, 0
+	bounds := projectBound(tile.Bound(), proj.WebMercator)
+	pixelWidth := (bounds.Max[0] - bounds.Min[0]) / 64
+	pixelHeight := (bounds.Max[1] - bounds.Min[1]) / 64
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			lon, lat := proj.WebMercator.Inverse(bounds.Min[0]+(float64(x)+0.5)*pixelWidth, bounds.Max[1]-(float64(y)+0.5)*pixelHeight)
+			px := math.Floor((lon - 10) / 0.01)
+			py := math.Floor((51 - lat) / 0.01)
+			if px < 0 || px >= 100 || py < 0 || py >= 100 {
+				outside++
+				if !data.IsNoData(0, x, y) || data.Float(0, x, y) != 65535 {
+					t.Fatalf("pixel (%d, %d) outside the image: expected NoData, got %v", x, y, data.Float(0, x, y))
+				}
+				continue
+			}
+			inside++
+			if data.IsNoData(0, x, y) {
+				t.Fatalf("pixel (%d, %d) inside the image is masked", x, y)
+			}
+			if got, want := data.Float(0, x, y), py*100+px; got != want {
+				t.Fatalf("pixel (%d, %d): expected %v, got %v", x, y, want, got)
+			}
+		}
+	}
+	if inside == 0 || outside == 0 {
+		t.Fatalf("expected a partial tile, got %d inside and %d outside", inside, outside)
+	}
+
+	// The mask is excluded from statistics
+	stats, err := data.Statistics(StatisticsOptions{})
+	if err != nil {
+		t.Fatalf("Statistics failed: %v", err)
+	}
+	if stats[0].ValidCount != int64(inside) {
+		t.Errorf("expected %d valid pixels, got %d", inside, stats[0].ValidCount)
+	}
+}
+
+func TestReadTileOutsideImage(t *testing.T) {
+	tr := testRaster{
+		Width: 10, Height: 10, EPSG: 4326,
+		Origin: [2]float64{10, 51}, PixelSize: [2]float64{0.1, 0.1},
+		Value: func(band, x, y int) float64 { return 1 },
+	}
+	data, err := tr.cog(t).ReadTile(maptile.At(orb.Point{-50, -20}, 6), 16)
+	if err != nil {
+		t.Fatalf("ReadTile failed: %v", err)
+	}
+	for i, m := range data.Mask {
+		if m != 0 {
+			t.Fatalf("expected every pixel to be masked, pixel %d is not", i)
+		}
+	}
+	if len(data.Mask) != 16*16 {
+		t.Errorf("expected a mask of %d pixels, got %d", 16*16, len(data.Mask))
+	}
+}
+
+func TestRasterDataMaskOperations(t *testing.T) {
+	r := &RasterData{Data: []uint64{1, 2, 3, 4, 5, 6}, Width: 3, Height: 2, Bands: 1, Mask: []uint8{255, 0, 255, 255, 255, 0}}
+
+	cropped, err := r.Crop(Rectangle{X: 1, Y: 0, Width: 2, Height: 2})
+	if err != nil {
+		t.Fatalf("Crop failed: %v", err)
+	}
+	if !cropped.IsNoData(0, 0, 0) || cropped.IsNoData(0, 1, 0) || !cropped.IsNoData(0, 1, 1) {
+		t.Errorf("unexpected cropped mask %v", cropped.Mask)
+	}
+
+	padded, err := r.Pad(1, 0, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("Pad failed: %v", err)
+	}
+	if !padded.IsNoData(0, 0, 0) || padded.IsNoData(0, 1, 0) || !padded.IsNoData(0, 2, 0) {
+		t.Errorf("unexpected padded mask %v", padded.Mask)
+	}
+
+	flipped := r.FlipVertical()
+	if !flipped.IsNoData(0, 1, 1) || !flipped.IsNoData(0, 2, 0) {
+		t.Errorf("unexpected flipped mask %v", flipped.Mask)
+	}
+
+	other := &RasterData{Data: []uint64{1, 2, 3, 4, 5, 6}, Width: 3, Height: 2, Bands: 1, Mask: []uint8{0, 255, 255, 255, 255, 255}}
+	stacked, err := StackBands(r, other)
+	if err != nil {
+		t.Fatalf("StackBands failed: %v", err)
+	}
+	want := []uint8{0, 0, 255, 255, 255, 0}
+	for i := range want {
+		if stacked.Mask[i] != want[i] {
+			t.Fatalf("expected stacked mask %v, got %v", want, stacked.Mask)
+		}
+	}
+	if r.Mask[0] != 255 {
+		t.Error("StackBands modified the input mask")
+	}
+}
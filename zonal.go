@@ -0,0 +1,97 @@
+package gocog
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+)
+
+// ZonalStatistics summarises the valid pixels of one band inside a zone.
+// Values are in physical units when the source defines a scale and offset.
+type ZonalStatistics struct {
+	Band        int // 0-based band index
+	Count       int64
+	NoDataCount int64 // Number of NoData (or NaN) pixels inside the zone
+	Sum         float64
+	Mean        float64
+	Min         float64
+	Max         float64
+	StdDev      float64 // Population standard deviation
+	Majority    float64 // Most frequent value; ties go to the smallest value
+}
+
+// ZonalStatistics reads an orb.Polygon or orb.MultiPolygon with ReadPolygon
+// and summarises every band over the pixels inside it, excluding NoData
+func (c *COG) ZonalStatistics(geom orb.Geometry, opts PolygonOptions) ([]ZonalStatistics, error) {
+	data, err := c.ReadPolygon(geom, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read polygon: %w", err)
+	}
+	bands, err := selectStatisticsBands(nil, data.Bands, c.ScaleOffset)
+	if err != nil {
+		return nil, err
+	}
+	return zonalStatistics(data, bands), nil
+}
+
+// ZonalStatistics summarises every band over the pixels that are not masked,
+// such as the result of ReadPolygon, excluding NoData
+func (r *RasterData) ZonalStatistics() []ZonalStatistics {
+	bands, _ := selectStatisticsBands(nil, r.Bands, nil)
+	return zonalStatistics(r, bands)
+}
+
+// zonalStatistics computes the statistics of the unmasked pixels of data
+func zonalStatistics(data *RasterData, bands []statsBand) []ZonalStatistics {
+	pixels := data.Width * data.Height
+	results := make([]ZonalStatistics, len(bands))
+	for i, b := range bands {
+		acc := newStatsAccumulator()
+		counts := make(map[float64]int64)
+		sum := 0.0
+		for p := 0; p < pixels; p++ {
+			if data.masked(p) {
+				continue
+			}
+			v := SampleToFloat64(data.Data[p*data.Bands+b.band], data.DataType)
+			if data.isNoDataValue(v) {
+				acc.noData++
+				continue
+			}
+			v = v*b.scale + b.offset
+			acc.add(v)
+			sum += v
+			counts[v]++
+		}
+
+		stats := acc.result(b.band)
+		results[i] = ZonalStatistics{
+			Band:        b.band,
+			Count:       stats.ValidCount,
+			NoDataCount: stats.NoDataCount,
+			Sum:         sum,
+			Mean:        stats.Mean,
+			Min:         stats.Min,
+			Max:         stats.Max,
+			StdDev:      stats.StdDev,
+			Majority:    majority(counts),
+		}
+	}
+	return results
+}
+
+// majority returns the most frequent value in counts, preferring the
+// smallest value on ties (0 if counts is empty)
+func majority(counts map[float64]int64) float64 {
+	best, bestCount := math.Inf(1), int64(0)
+	for v, n := range counts {
+		if n > bestCount || (n == bestCount && v < best) {
+			best, bestCount = v, n
+		}
+	}
+	if bestCount == 0 {
+		return 0
+	}
+	return best
+}